@@ -0,0 +1,83 @@
+package conf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A Report lists the fields of a config struct which didn't receive
+// a value from any source, helping to prune stale configuration.
+type Report struct {
+
+	// UnusedDefaults are the fields whose defaults were never overridden.
+	UnusedDefaults []Field
+
+	// DeadFields are the fields without a default which received no value.
+	DeadFields []Field
+}
+
+// String renders the report with one field per line.
+func (r *Report) String() string {
+	var b strings.Builder
+
+	if len(r.UnusedDefaults) > 0 {
+		b.WriteString("Defaults never overridden:\n")
+		for _, field := range r.UnusedDefaults {
+			def := field.Options.DefaultVal
+			if field.Options.DefaultFile != "" {
+				def = "file " + field.Options.DefaultFile
+			}
+			if field.Options.DefaultEmbed != "" {
+				def = "embed " + field.Options.DefaultEmbed
+			}
+			fmt.Fprintf(&b, "  %s (%s) default: %s\n", field.EnvKey, strings.Join(field.Path, "."), def)
+		}
+	}
+
+	if len(r.DeadFields) > 0 {
+		b.WriteString("Fields without value:\n")
+		for _, field := range r.DeadFields {
+			fmt.Fprintf(&b, "  %s (%s)\n", field.EnvKey, strings.Join(field.Path, "."))
+		}
+	}
+
+	return b.String()
+}
+
+// Audit parses the specified config struct like Parse and reports
+// the fields which were left to their defaults or didn't receive
+// a value at all from the sources.
+func Audit(prefix string, cfg any, opts ...Option) (*Report, error) {
+	o := newOptions(opts)
+
+	var (
+		fields []Field
+		values map[string]string
+	)
+
+	lookup := func(flds []Field) (map[string]string, error) {
+		vals, err := resolveValues(flds, o)
+		fields, values = flds, vals
+		return vals, err
+	}
+
+	if err := parse(prefix, cfg, o, lookup); err != nil {
+		return nil, err
+	}
+
+	var r Report
+	for _, field := range fields {
+		if _, ok := values[field.EnvKey]; ok {
+			continue
+		}
+
+		if field.Options.DefaultVal != "" || field.Options.DefaultFile != "" || field.Options.DefaultEmbed != "" {
+			r.UnusedDefaults = append(r.UnusedDefaults, field)
+			continue
+		}
+
+		r.DeadFields = append(r.DeadFields, field)
+	}
+
+	return &r, nil
+}
@@ -0,0 +1,52 @@
+package conf
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAudit(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("TEST_AN_INT", "1")
+	_ = os.Setenv("TEST_BOOL", "true")
+	_ = os.Setenv("TEST_NAME", "virp")
+
+	var cfg config
+	r, err := Audit("test", &cfg)
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to audit config struct : %s.", failed, err)
+	}
+	t.Logf("\t%s\tShould be able to audit config struct.", success)
+
+	keys := func(fields []Field) []string {
+		var out []string
+		for _, field := range fields {
+			out = append(out, field.EnvKey)
+		}
+		return out
+	}
+
+	wantDefaults := []string{"TEST_A_STRING", "IP_NAME_VAR", "TEST_IP_IP", "TEST_IP_ENDPOINTS", "TEST_DEBUG_HOST", "TEST_PASSWORD", "TEST_CUSTOM", "TEST_DURATION"}
+	if diff := cmp.Diff(wantDefaults, keys(r.UnusedDefaults)); diff != "" {
+		t.Fatalf("\t%s\tShould report defaults never overridden\n%s", failed, diff)
+	}
+	t.Logf("\t%s\tShould report defaults never overridden.", success)
+
+	if len(r.DeadFields) != 0 {
+		t.Fatalf("\t%s\tShould not report fields without value : %v.", failed, keys(r.DeadFields))
+	}
+
+	os.Clearenv()
+
+	var cfg2 config
+	if r, err = Audit("test", &cfg2); err != nil {
+		t.Fatalf("\t%s\tShould be able to audit config struct : %s.", failed, err)
+	}
+
+	if diff := cmp.Diff([]string{"TEST_BOOL"}, keys(r.DeadFields)); diff != "" {
+		t.Fatalf("\t%s\tShould report fields without value\n%s", failed, diff)
+	}
+	t.Logf("\t%s\tShould report fields without value.", success)
+}
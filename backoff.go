@@ -0,0 +1,78 @@
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Backoff describes an exponential retry schedule. It is set from a string
+// such as 100ms..30s*2, the delay starts at 100ms, is multiplied by 2 after
+// every attempt and never exceeds 30s. The multiplier defaults to 2.
+type Backoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+}
+
+// Set implements the Setter interface.
+func (b *Backoff) Set(data string) error {
+	bounds, mult, hasMult := strings.Cut(data, "*")
+
+	initial, maxDelay, ok := strings.Cut(bounds, "..")
+	if !ok {
+		return errors.New("invalid backoff, expected initial..max*multiplier such as 100ms..30s*2")
+	}
+
+	var (
+		v   Backoff
+		err error
+	)
+
+	if v.Initial, err = time.ParseDuration(strings.TrimSpace(initial)); err != nil {
+		return fmt.Errorf("invalid backoff initial delay: %w", err)
+	}
+
+	if v.Max, err = time.ParseDuration(strings.TrimSpace(maxDelay)); err != nil {
+		return fmt.Errorf("invalid backoff max delay: %w", err)
+	}
+
+	v.Multiplier = 2
+	if hasMult {
+		if v.Multiplier, err = strconv.ParseFloat(strings.TrimSpace(mult), 64); err != nil {
+			return fmt.Errorf("invalid backoff multiplier: %w", err)
+		}
+	}
+
+	switch {
+	case v.Initial <= 0:
+		return fmt.Errorf("invalid backoff, initial delay %s must be positive", v.Initial)
+	case v.Max < v.Initial:
+		return fmt.Errorf("invalid backoff, max delay %s is less than initial delay %s", v.Max, v.Initial)
+	case v.Multiplier < 1:
+		return fmt.Errorf("invalid backoff, multiplier %g is less than 1", v.Multiplier)
+	}
+
+	*b = v
+
+	return nil
+}
+
+// String renders the backoff in the form accepted by Set.
+func (b Backoff) String() string {
+	return fmt.Sprintf("%s..%s*%s", b.Initial, b.Max, strconv.FormatFloat(b.Multiplier, 'g', -1, 64))
+}
+
+// Delay returns the delay before the retry following the attempt,
+// counting attempts from 0.
+func (b Backoff) Delay(attempt int) time.Duration {
+	d := float64(b.Initial) * math.Pow(b.Multiplier, float64(attempt))
+	if d > float64(b.Max) || math.IsInf(d, 0) || math.IsNaN(d) {
+		return b.Max
+	}
+
+	return time.Duration(d)
+}
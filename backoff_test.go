@@ -0,0 +1,71 @@
+package conf
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse_Backoff(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("TEST_RETRY", "100ms..30s*3")
+
+	var cfg struct {
+		Retry   Backoff
+		Default Backoff `conf:"default:1s..1m"`
+	}
+
+	if err := Parse("test", &cfg); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse backoff : %s.", failed, err)
+	}
+
+	if want := (Backoff{100 * time.Millisecond, 30 * time.Second, 3}); cfg.Retry != want {
+		t.Fatalf("\t%s\tShould have parsed backoff, got %+v, want %+v.", failed, cfg.Retry, want)
+	}
+	t.Logf("\t%s\tShould have parsed backoff.", success)
+
+	if cfg.Default.Multiplier != 2 || cfg.Default.String() != "1s..1m0s*2" {
+		t.Fatalf("\t%s\tShould default the multiplier to 2 : %s.", failed, cfg.Default)
+	}
+	t.Logf("\t%s\tShould default the multiplier to 2.", success)
+
+	delays := []time.Duration{100 * time.Millisecond, 300 * time.Millisecond, 900 * time.Millisecond}
+	for attempt, want := range delays {
+		if got := cfg.Retry.Delay(attempt); got != want {
+			t.Fatalf("\t%s\tShould delay attempt %d by %s, got %s.", failed, attempt, want, got)
+		}
+	}
+
+	if got := cfg.Retry.Delay(1000); got != 30*time.Second {
+		t.Fatalf("\t%s\tShould cap the delay at max, got %s.", failed, got)
+	}
+	t.Logf("\t%s\tShould grow the delay up to max.", success)
+}
+
+func TestParse_BackoffErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		want string
+	}{
+		{"format", "100ms", "expected initial..max*multiplier"},
+		{"initial", "fast..1s", "invalid backoff initial delay"},
+		{"max-less", "1s..100ms", "max delay 100ms is less than initial delay 1s"},
+		{"multiplier", "1s..1m*0.5", "multiplier 0.5 is less than 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			_ = os.Setenv("TEST_RETRY", tt.env)
+
+			var cfg struct{ Retry Backoff }
+			err := Parse("test", &cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("\t%s\tShould fail for %q with %q : %v.", failed, tt.env, tt.want, err)
+			}
+			t.Logf("\t%s\tShould fail for %q : %s.", success, tt.env, err)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package conf
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// bundle is the document rendered by SupportBundle.
+type bundle struct {
+	Version  string         `json:"version,omitempty"`
+	Schema   string         `json:"schema"`
+	ParsedAt *time.Time     `json:"parsed_at,omitempty"`
+	Fields   []bundleField  `json:"fields"`
+	Sources  []bundleSource `json:"sources,omitempty"`
+	Warnings []string       `json:"warnings,omitempty"`
+}
+
+type bundleField struct {
+	Key    string `json:"key"`
+	Path   string `json:"path"`
+	Value  string `json:"value"`
+	Source string `json:"source,omitempty"`
+}
+
+type bundleSource struct {
+	Name      string     `json:"name"`
+	LastFetch *time.Time `json:"last_fetch,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	Cached    bool       `json:"cached,omitempty"`
+	Duration  string     `json:"duration,omitempty"`
+}
+
+// SupportBundle renders the state of the specified config struct as a single
+// JSON document to be attached to incident tickets. It holds the redacted
+// values of the fields with the sources they came from, the health of the
+// sources, the warnings of the last successful parse with WithProvenance,
+// the build of the
+// embedded Version and the schema version, a hash of the config surface
+// telling apart builds with different settings. Values of fields tagged with
+// `noprint` are left out like in String.
+func SupportBundle(cfg any, opts ...Option) ([]byte, error) {
+	var rec parseRecord
+	if r, ok := provenance.Load(cfg); ok {
+		rec = r.(parseRecord)
+	}
+
+	fields, err := Fields(rec.prefix, cfg, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := ExportSchema(rec.prefix, cfg, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("encode schema: %w", err)
+	}
+	sum := sha256.Sum256(data)
+
+	b := bundle{
+		Schema: "sha256:" + hex.EncodeToString(sum[:8]),
+		Fields: make([]bundleField, 0, len(fields)),
+	}
+
+	if v, ok := findVersion(cfg); ok {
+		b.Version = v.Build
+	}
+
+	if !rec.at.IsZero() {
+		b.ParsedAt = &rec.at
+	}
+
+	for _, field := range fields {
+		if field.Options.NoPrint {
+			continue
+		}
+
+		b.Fields = append(b.Fields, bundleField{
+			Key:    field.EnvKey,
+			Path:   strings.Join(field.Path, "."),
+			Value:  field.Value(),
+			Source: rec.origins[field.EnvKey],
+		})
+	}
+
+	for _, h := range SourcesHealth() {
+		s := bundleSource{Name: h.Name, Cached: h.Cached, Duration: h.Duration.String()}
+		if !h.LastFetch.IsZero() {
+			s.LastFetch = &h.LastFetch
+		}
+		if h.LastError != nil {
+			s.Error = h.LastError.Error()
+		}
+		b.Sources = append(b.Sources, s)
+	}
+
+	for _, w := range rec.warnings {
+		b.Warnings = append(b.Warnings, w.String())
+	}
+
+	out, err := json.MarshalIndent(b, "", "\t")
+	if err != nil {
+		return nil, fmt.Errorf("encode support bundle: %w", err)
+	}
+
+	return out, nil
+}
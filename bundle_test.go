@@ -0,0 +1,69 @@
+package conf
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSupportBundle(t *testing.T) {
+	os.Clearenv()
+
+	type config struct {
+		Version
+		Host     string `conf:"default:localhost"`
+		Password string `conf:"mask"`
+		Token    string `conf:"noprint"`
+		DB       DSN
+		OldHost  string `conf:"removed_in:v2.0"`
+	}
+
+	env := map[string]string{
+		"TEST_PASSWORD": "secret",
+		"TEST_TOKEN":    "token",
+		"TEST_DB":       "postgres://app:secret@db:5432/orders",
+		"TEST_OLD_HOST": "old",
+	}
+
+	cfg := config{Version: Version{Build: "v1.2.0"}}
+	if err := Parse("test", &cfg, WithEnviron(env), WithArgs(nil), WithProvenance()); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse : %s.", failed, err)
+	}
+
+	data, err := SupportBundle(&cfg)
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to render the support bundle : %s.", failed, err)
+	}
+	t.Logf("\t%s\tShould be able to render the support bundle :\n%s", success, data)
+
+	if strings.Contains(string(data), "secret") || strings.Contains(string(data), "token") {
+		t.Fatalf("\t%s\tShould redact the secrets.", failed)
+	}
+	t.Logf("\t%s\tShould redact the secrets.", success)
+
+	var doc struct {
+		Version  string
+		Schema   string
+		Fields   []struct{ Key, Path, Value, Source string }
+		Warnings []string
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("\t%s\tShould render JSON : %s.", failed, err)
+	}
+
+	if doc.Version != "v1.2.0" || !strings.HasPrefix(doc.Schema, "sha256:") {
+		t.Fatalf("\t%s\tShould render the versions : %q %q.", failed, doc.Version, doc.Schema)
+	}
+	t.Logf("\t%s\tShould render the versions.", success)
+
+	if len(doc.Fields) != 4 || doc.Fields[0].Key != "TEST_HOST" || doc.Fields[0].Source != "default" || doc.Fields[1].Value != maskedValue || doc.Fields[1].Source != "env" {
+		t.Fatalf("\t%s\tShould render the fields with their sources : %+v.", failed, doc.Fields)
+	}
+	t.Logf("\t%s\tShould render the fields with their sources.", success)
+
+	if len(doc.Warnings) != 1 || !strings.HasPrefix(doc.Warnings[0], "TEST_OLD_HOST:") {
+		t.Fatalf("\t%s\tShould render the warnings of the parse : %v.", failed, doc.Warnings)
+	}
+	t.Logf("\t%s\tShould render the warnings of the parse.", success)
+}
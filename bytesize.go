@@ -0,0 +1,100 @@
+package conf
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ByteSize holds a number of bytes, set from a value with a unit such as
+// 512KiB, 10MB or 1.5G. The units KiB, MiB, GiB and TiB and the single
+// letters K, M, G and T are powers of 1024, while KB, MB, GB and TB are
+// powers of 1000. Units are case insensitive, values without a unit or
+// with B are bytes. Fractions are rounded to the nearest byte.
+type ByteSize int64
+
+// The sizes of the binary and decimal units.
+const (
+	Byte ByteSize = 1
+
+	KiB = 1024 * Byte
+	MiB = 1024 * KiB
+	GiB = 1024 * MiB
+	TiB = 1024 * GiB
+
+	KB = 1000 * Byte
+	MB = 1000 * KB
+	GB = 1000 * MB
+	TB = 1000 * GB
+)
+
+var byteUnits = map[string]ByteSize{
+	"":    Byte,
+	"b":   Byte,
+	"k":   KiB,
+	"kib": KiB,
+	"kb":  KB,
+	"m":   MiB,
+	"mib": MiB,
+	"mb":  MB,
+	"g":   GiB,
+	"gib": GiB,
+	"gb":  GB,
+	"t":   TiB,
+	"tib": TiB,
+	"tb":  TB,
+}
+
+// Set implements the Setter interface.
+func (b *ByteSize) Set(data string) error {
+	s := strings.TrimSpace(data)
+
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+
+	num, unit := s[:i], strings.ToLower(strings.TrimSpace(s[i:]))
+
+	mult, ok := byteUnits[unit]
+	if !ok {
+		return fmt.Errorf("unknown unit %q, expected a size such as 512KiB, 10MB or 1.5G", s[i:])
+	}
+
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil || num == "" {
+		return fmt.Errorf("invalid size %q, expected a size such as 512KiB, 10MB or 1.5G", data)
+	}
+
+	size := math.Round(n * float64(mult))
+	if size >= math.MaxInt64 {
+		return fmt.Errorf("size %q is too large", data)
+	}
+
+	*b = ByteSize(size)
+
+	return nil
+}
+
+// String renders the size with the largest unit holding it exactly,
+// such as 512KiB or 10MB, in the form accepted by Set.
+func (b ByteSize) String() string {
+	for _, u := range []struct {
+		name string
+		size ByteSize
+	}{
+		{"TiB", TiB}, {"TB", TB},
+		{"GiB", GiB}, {"GB", GB},
+		{"MiB", MiB}, {"MB", MB},
+		{"KiB", KiB}, {"KB", KB},
+	} {
+		if b != 0 && b%u.size == 0 {
+			return strconv.FormatInt(int64(b/u.size), 10) + u.name
+		}
+	}
+
+	return strconv.FormatInt(int64(b), 10) + "B"
+}
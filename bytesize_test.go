@@ -0,0 +1,70 @@
+package conf
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestByteSize(t *testing.T) {
+	tests := []struct {
+		value string
+		want  ByteSize
+		str   string
+	}{
+		{"0", 0, "0B"},
+		{"100", 100, "100B"},
+		{"100B", 100, "100B"},
+		{"512KiB", 512 * KiB, "512KiB"},
+		{"512k", 512 * KiB, "512KiB"},
+		{"10MB", 10 * MB, "10MB"},
+		{"10 mb", 10 * MB, "10MB"},
+		{"1.5G", 1536 * MiB, "1536MiB"},
+		{"2TiB", 2 * TiB, "2TiB"},
+		{"1.1K", 1126, "1126B"},
+	}
+
+	for _, tt := range tests {
+		var b ByteSize
+		if err := b.Set(tt.value); err != nil {
+			t.Fatalf("\t%s\tShould parse %q : %s.", failed, tt.value, err)
+		}
+
+		if b != tt.want || b.String() != tt.str {
+			t.Fatalf("\t%s\tShould parse %q as %d rendered %s : got %d rendered %s.", failed, tt.value, tt.want, tt.str, b, b)
+		}
+	}
+	t.Logf("\t%s\tShould parse sizes with units.", success)
+
+	for _, value := range []string{"", "KiB", "10XB", "-1K", "1..5M", "9000000TiB"} {
+		var b ByteSize
+		if err := b.Set(value); err == nil {
+			t.Fatalf("\t%s\tShould reject %q : got %d.", failed, value, b)
+		}
+	}
+	t.Logf("\t%s\tShould reject invalid sizes.", success)
+}
+
+func TestParse_ByteSize(t *testing.T) {
+	os.Clearenv()
+
+	var cfg struct {
+		Buffer ByteSize `conf:"default:64KiB"`
+		Upload ByteSize `conf:"max:1GiB"`
+	}
+
+	if err := Parse("test", &cfg, WithEnviron(map[string]string{"TEST_UPLOAD": "100MB"}), WithArgs(nil)); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse sizes : %s.", failed, err)
+	}
+
+	if cfg.Buffer != 64*KiB || cfg.Upload != 100*MB {
+		t.Fatalf("\t%s\tShould set the sizes : %+v.", failed, cfg)
+	}
+	t.Logf("\t%s\tShould set the sizes.", success)
+
+	err := Parse("test", &cfg, WithEnviron(map[string]string{"TEST_UPLOAD": "2G"}), WithArgs(nil))
+	if err == nil || !strings.Contains(err.Error(), "value 2GiB is out of bounds, max is 1GiB") {
+		t.Fatalf("\t%s\tShould bound the size : %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould bound the size : %s.", success, err)
+}
@@ -0,0 +1,30 @@
+package conf
+
+import "time"
+
+// A Clock tells the time to everything depending on it: the expiries of
+// fields, the health of sources, the records of parses and the interval of
+// Watch. Tests replace the system clock with WithClock to make them
+// deterministic.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// WithClock sets the clock telling the time, the system clock by default.
+func WithClock(c Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+// systemClock tells the time of the system.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
@@ -0,0 +1,102 @@
+package conf
+
+import (
+	"context"
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// fakeClock provides support for testing time dependent behavior,
+// the time moves only when the test ticks it.
+type fakeClock struct {
+	now  time.Time
+	tick chan time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	return c.tick
+}
+
+func TestWithClock(t *testing.T) {
+	os.Clearenv()
+
+	type config struct {
+		Toggle bool `conf:"expires:2024-03-01"`
+	}
+
+	clock := &fakeClock{now: time.Date(2024, 3, 1, 23, 59, 0, 0, time.UTC), tick: make(chan time.Time)}
+
+	var cfg config
+	if err := Parse("test", &cfg, WithEnviron(nil), WithArgs(nil), WithClock(clock), WithExpiryErrors()); err != nil {
+		t.Fatalf("\t%s\tShould keep the field until the end of the date : %s.", failed, err)
+	}
+	t.Logf("\t%s\tShould keep the field until the end of the date.", success)
+
+	clock.now = clock.now.Add(time.Minute)
+
+	if err := Parse("test", &cfg, WithEnviron(nil), WithArgs(nil), WithClock(clock), WithExpiryErrors()); err == nil {
+		t.Fatalf("\t%s\tShould fail once the date has passed on the clock.", failed)
+	}
+	t.Logf("\t%s\tShould fail once the date has passed on the clock.", success)
+
+	src := &rotatingSource{values: map[string]string{"TEST_TOGGLE": "false"}}
+	changes := make(chan bool)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		_ = Watch(ctx, "test", &cfg, func(old, new any) {
+			changes <- new.(*config).Toggle
+		}, WithSources(src), WithArgs(nil), WithClock(clock))
+	}()
+
+	src.set("TEST_TOGGLE", "true")
+	clock.tick <- clock.now
+
+	select {
+	case toggle := <-changes:
+		if !toggle {
+			t.Fatalf("\t%s\tShould parse when the clock ticks.", failed)
+		}
+		t.Logf("\t%s\tShould parse when the clock ticks.", success)
+	case <-time.After(time.Second):
+		t.Fatalf("\t%s\tShould parse when the clock ticks.", failed)
+	}
+}
+
+func TestWithFS(t *testing.T) {
+	os.Clearenv()
+
+	type config struct {
+		Host     string
+		Password string `conf:"secret:db_password"`
+		Token    string `conf:"file"`
+		Motd     string `conf:"defaultfile:/etc/motd"`
+	}
+
+	fsys := fstest.MapFS{
+		"etc/app/config.yaml":     {Data: []byte("host: files\n")},
+		"run/secrets/db_password": {Data: []byte("s3cret\n")},
+		"var/token":               {Data: []byte("t0ken")},
+		"etc/motd":                {Data: []byte("hello")},
+	}
+
+	env := map[string]string{"TEST_TOKEN_FILE": "/var/token"}
+
+	var cfg config
+	if err := Parse("test", &cfg, WithEnviron(env), WithArgs(nil), WithFS(fsys), WithYamlFile("/etc/app/config.yaml")); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse from the file system : %s.", failed, err)
+	}
+
+	if cfg != (config{"files", "s3cret", "t0ken", "hello"}) {
+		t.Fatalf("\t%s\tShould read every file from the file system : %+v.", failed, cfg)
+	}
+	t.Logf("\t%s\tShould read every file from the file system.", success)
+}
@@ -0,0 +1,647 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A leaf is a field of the config struct set from a single variable.
+type leaf struct {
+	path    []string
+	key     string
+	flag    string
+	target  string
+	typ     string
+	kind    string
+	slice   bool
+	elem    string
+	sep     string
+	def     string
+	require bool
+	mask    bool
+
+	// lazy marks the fields of type conf.LazyValue tagged with
+	// `required:lazy`, typ is the type of their value.
+	lazy bool
+}
+
+// kinds maps the basic types to the functions parsing them.
+var kinds = map[string]string{
+	"string":        "",
+	"bool":          "strconv.ParseBool(%s)",
+	"int":           "strconv.ParseInt(%s, 0, 0)",
+	"int8":          "strconv.ParseInt(%s, 0, 8)",
+	"int16":         "strconv.ParseInt(%s, 0, 16)",
+	"int32":         "strconv.ParseInt(%s, 0, 32)",
+	"int64":         "strconv.ParseInt(%s, 0, 64)",
+	"uint":          "strconv.ParseUint(%s, 0, 0)",
+	"uint8":         "strconv.ParseUint(%s, 0, 8)",
+	"uint16":        "strconv.ParseUint(%s, 0, 16)",
+	"uint32":        "strconv.ParseUint(%s, 0, 32)",
+	"uint64":        "strconv.ParseUint(%s, 0, 64)",
+	"float32":       "strconv.ParseFloat(%s, 32)",
+	"float64":       "strconv.ParseFloat(%s, 64)",
+	"time.Duration": "time.ParseDuration(%s)",
+}
+
+// confPath is the import path of conf, imported as conf by the generated
+// code reporting missing required and lazy fields.
+const confPath = "github.com/virp/conf"
+
+// unsupported are the tag options whose behavior the generated code lacks.
+var unsupported = map[string]bool{
+	"defaultfile": true,
+	"min":         true,
+	"max":         true,
+	"len":         true,
+	"oneof":       true,
+	"mimetype":    true,
+	"file":        true,
+	"chunked":     true,
+	"secret":      true,
+	"layout":      true,
+	"kvsep":       true,
+	"removed_in":  true,
+	"expires":     true,
+	"alias":       true,
+	"deprecated":  true,
+}
+
+// hooks are the methods conf calls on the types declared in the package,
+// decoding values, listing the valid ones, setting defaults and validating,
+// which the generated code lacks.
+var hooks = []string{"Set", "UnmarshalText", "UnmarshalBinary", "Values", "Defaults", "Validate"}
+
+// generator collects the leaves of the config struct from the sources
+// of its package.
+type generator struct {
+	pkg     string
+	types   map[string]ast.Expr
+	methods map[string]map[string]bool
+	leaves  []leaf
+}
+
+// load parses the Go files of the package in the directory, skipping tests
+// and the output of earlier runs.
+func load(dir, output string) (*generator, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	g := generator{types: make(map[string]ast.Expr), methods: make(map[string]map[string]bool)}
+	fset := token.NewFileSet()
+
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") || filepath.Base(path) == filepath.Base(output) {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		g.pkg = f.Name.Name
+
+		for _, decl := range f.Decls {
+			if fd, ok := decl.(*ast.FuncDecl); ok && fd.Recv != nil {
+				name := receiverName(fd.Recv.List[0].Type)
+				if g.methods[name] == nil {
+					g.methods[name] = make(map[string]bool)
+				}
+				g.methods[name][fd.Name.Name] = true
+				continue
+			}
+
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				g.types[ts.Name.Name] = ts.Type
+			}
+		}
+	}
+
+	if g.pkg == "" {
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+
+	return &g, nil
+}
+
+// collect walks the struct type adding its leaves, the keys start with the
+// prefix and the targets with the expression of the struct value.
+func (g *generator) collect(st *ast.StructType, prefix string, path []string, target string) error {
+	for _, field := range st.Fields.List {
+		tag := ""
+		if field.Tag != nil {
+			s, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return err
+			}
+			tag = reflect.StructTag(s).Get("conf")
+		}
+
+		if tag == "-" {
+			continue
+		}
+
+		opts, err := parseTag(tag)
+		if err != nil {
+			return err
+		}
+
+		// Embedded structs share the keys of the outer struct.
+		if len(field.Names) == 0 {
+			name := types.ExprString(field.Type)
+			if method, ok := g.hook(field.Type); ok {
+				return fmt.Errorf("embedded field %s: method %s isn't supported by confgen, use conf.Parse", name, method)
+			}
+
+			st, ok := g.structType(field.Type)
+			if !ok {
+				return fmt.Errorf("embedded field %s: unsupported type, use conf.Parse", name)
+			}
+
+			innerPrefix, innerPath := prefix, path
+			if p := opts["prefix"]; p != "" {
+				innerPrefix, innerPath = joinKey(prefix, strings.ToUpper(p)), append(path[:len(path):len(path)], p)
+			}
+
+			if err := g.collect(st, innerPrefix, innerPath, target+"."+name); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+
+			key := joinKey(prefix, strings.ToUpper(strings.Join(camelSplit(name.Name), "_")))
+			fieldPath := append(path[:len(path):len(path)], name.Name)
+
+			if method, ok := g.hook(field.Type); ok {
+				return fmt.Errorf("field %s: method %s of type %s isn't supported by confgen, use conf.Parse", strings.Join(fieldPath, "."), method, types.ExprString(field.Type))
+			}
+
+			if st, ok := g.structType(field.Type); ok {
+				innerPrefix := key
+				if p := opts["prefix"]; p != "" {
+					innerPrefix, fieldPath = joinKey(prefix, strings.ToUpper(p)), append(path[:len(path):len(path)], p)
+				}
+
+				if err := g.collect(st, innerPrefix, fieldPath, target+"."+name.Name); err != nil {
+					return err
+				}
+				continue
+			}
+
+			for opt := range opts {
+				if unsupported[opt] {
+					return fmt.Errorf("field %s: tag option %s isn't supported by confgen, use conf.Parse", strings.Join(fieldPath, "."), opt)
+				}
+			}
+
+			if strings.HasPrefix(opts["default"], "embed:") {
+				return fmt.Errorf("field %s: tag option default:embed isn't supported by confgen, use conf.Parse", strings.Join(fieldPath, "."))
+			}
+
+			typ := field.Type
+
+			lazy := opts["required"] == "lazy"
+			if lazy {
+				elem, ok := lazyValue(typ)
+				if !ok {
+					return fmt.Errorf("field %s: tag option required:lazy requires a conf.LazyValue field", strings.Join(fieldPath, "."))
+				}
+				typ = elem
+			}
+
+			l := leaf{
+				path:    fieldPath,
+				key:     key,
+				target:  target + "." + name.Name,
+				typ:     types.ExprString(typ),
+				def:     opts["default"],
+				require: opts["required"] != "" && !lazy,
+				mask:    opts["mask"] != "",
+				sep:     ";",
+				lazy:    lazy,
+			}
+			if l.flag = opts["flag"]; l.flag == "" {
+				l.flag = flagName(fieldPath)
+			}
+			if env := opts["env"]; env != "" {
+				l.key = env
+			}
+			if sep := opts["sep"]; sep != "" {
+				l.sep = sep
+			}
+
+			if at, ok := typ.(*ast.ArrayType); ok && at.Len == nil {
+				l.slice, l.elem, typ = true, types.ExprString(at.Elt), at.Elt
+			}
+
+			kind, ok := g.basicKind(typ)
+			if !ok {
+				return fmt.Errorf("field %s: unsupported type %s, use conf.Parse", strings.Join(fieldPath, "."), l.typ)
+			}
+
+			l.kind = kind
+
+			g.leaves = append(g.leaves, l)
+		}
+	}
+
+	return nil
+}
+
+// structType returns the struct type of the expression, inline or declared
+// in the package.
+func (g *generator) structType(expr ast.Expr) (*ast.StructType, bool) {
+	switch t := expr.(type) {
+	case *ast.StructType:
+		return t, true
+	case *ast.Ident:
+		if decl, ok := g.types[t.Name]; ok {
+			return g.structType(decl)
+		}
+	}
+
+	return nil, false
+}
+
+// lazyValue returns the type of the value of the expression if it's a
+// conf.LazyValue, whatever name conf is imported as.
+func lazyValue(expr ast.Expr) (ast.Expr, bool) {
+	ix, ok := expr.(*ast.IndexExpr)
+	if !ok {
+		return nil, false
+	}
+
+	sel, ok := ix.X.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "LazyValue" {
+		return nil, false
+	}
+
+	return ix.Index, true
+}
+
+// hook returns the first of the hooks declared on the type of the expression,
+// or on its elements for pointers and slices.
+func (g *generator) hook(expr ast.Expr) (string, bool) {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return g.hook(t.X)
+	case *ast.ArrayType:
+		return g.hook(t.Elt)
+	case *ast.Ident:
+		for _, method := range hooks {
+			if g.methods[t.Name][method] {
+				return method, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// receiverName returns the name of the type of the method receiver.
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+
+	return ""
+}
+
+// basicKind returns the basic type of the expression, following the types
+// declared in the package such as type Level string.
+func (g *generator) basicKind(expr ast.Expr) (string, bool) {
+	name := types.ExprString(expr)
+	if _, ok := kinds[name]; ok {
+		return name, true
+	}
+
+	if id, ok := expr.(*ast.Ident); ok {
+		if decl, ok := g.types[id.Name]; ok {
+			return g.basicKind(decl)
+		}
+	}
+
+	return "", false
+}
+
+// parseTag parses the conf tag into its options, options without a value
+// are set to their name.
+func parseTag(tag string) (map[string]string, error) {
+	opts := make(map[string]string)
+	if tag == "" {
+		return opts, nil
+	}
+
+	for _, part := range strings.Split(tag, ",") {
+		name, value, ok := strings.Cut(part, ":")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+
+		if !ok {
+			opts[name] = name
+			continue
+		}
+
+		if value == "" {
+			return nil, fmt.Errorf("tag %q missing value", name)
+		}
+
+		if name == "sep" && value == "comma" {
+			value = ","
+		}
+		opts[name] = value
+	}
+
+	if opts["required"] != "" && opts["default"] != "" {
+		return nil, fmt.Errorf("cannot set both `required` and `default`")
+	}
+
+	return opts, nil
+}
+
+// generate renders the source of the function parsing the config struct.
+func (g *generator) generate(typeName, prefix string, args []string) ([]byte, error) {
+	var b bytes.Buffer
+
+	imports := map[string]bool{"errors": true, "os": true}
+	for _, l := range g.leaves {
+		switch l.kind {
+		case "string":
+		case "time.Duration":
+			imports["fmt"], imports["time"] = true, true
+		default:
+			imports["fmt"], imports["strconv"] = true, true
+		}
+		if l.slice {
+			imports["fmt"], imports["strings"] = true, true
+		}
+		if l.require || l.lazy {
+			imports[confPath] = true
+		}
+	}
+
+	names := make([]string, 0, len(imports))
+	for name := range imports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(&b, "// Code generated by confgen %s; DO NOT EDIT.\n\n", strings.Join(args, " "))
+	fmt.Fprintf(&b, "package %s\n\nimport (\n", g.pkg)
+	for _, name := range names {
+		if name != confPath {
+			fmt.Fprintf(&b, "\t%q\n", name)
+		}
+	}
+	if imports[confPath] {
+		fmt.Fprintf(&b, "\n\t%q\n", confPath)
+	}
+	b.WriteString(")\n\n")
+
+	fmt.Fprintf(&b, "// Parse%[1]s parses a %[1]s from the environment like conf.Parse with the\n", typeName)
+	fmt.Fprintf(&b, "// prefix %q, without reflection. Flags and sources other than the\n", prefix)
+	b.WriteString("// environment aren't supported. The errors of all fields are joined.\n")
+	fmt.Fprintf(&b, "func Parse%[1]s() (%[1]s, error) {\n", typeName)
+	fmt.Fprintf(&b, "\tvar (\n\t\tcfg  %s\n\t\terrs []error\n\t)\n\n", typeName)
+	b.WriteString("\tlookup := func(key, def string) (string, bool) {\n")
+	b.WriteString("\t\tif v, ok := os.LookupEnv(key); ok {\n\t\t\treturn v, true\n\t\t}\n")
+	b.WriteString("\t\treturn def, def != \"\"\n\t}\n\n")
+
+	for _, l := range g.leaves {
+		g.emit(&b, l)
+	}
+
+	fmt.Fprintf(&b, "\tif err := errors.Join(errs...); err != nil {\n\t\treturn %s{}, err\n\t}\n\n", typeName)
+	b.WriteString("\treturn cfg, nil\n}\n")
+
+	out, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+
+	return out, nil
+}
+
+// emit renders the statements setting the leaf.
+func (g *generator) emit(b *bytes.Buffer, l leaf) {
+	name := strings.Join(l.path, ".")
+
+	shown := "v"
+	if l.mask {
+		shown = `"xxxxxx"`
+	}
+
+	fmt.Fprintf(b, "\tif v, ok := lookup(%q, %q); ok {\n", l.key, l.def)
+
+	// Lazy values are converted into lv and wrapped afterwards.
+	target := l.target
+	if l.lazy {
+		target = "lv"
+		fmt.Fprintf(b, "\t\tvar lv %s\n", l.typ)
+	}
+
+	typ := l.typ
+	if l.slice {
+		typ = l.elem
+
+		// conf.Parse unquotes the items, which the generated code doesn't.
+		fmt.Fprintf(b, "\t\tif strings.Contains(v, `\"`) || strings.Contains(v, %q) {\n", `\`+l.sep)
+		fmt.Fprintf(b, "\t\t\terrs = append(errs, fmt.Errorf(\"field %s (%s): quoted and escaped items aren't supported by the generated code, use conf.Parse\"))\n", name, l.key)
+		b.WriteString("\t\t}\n")
+		fmt.Fprintf(b, "\t\titems := strings.Split(v, %q)\n", l.sep)
+		fmt.Fprintf(b, "\t\ts := make(%s, len(items))\n", l.typ)
+		b.WriteString("\t\tfor i, v := range items {\n")
+	}
+
+	set := target
+	if l.slice {
+		set = "s[i]"
+	}
+
+	// The errors of strconv and time quote the value, masked fields
+	// keep only the cause of the strconv ones.
+	cause := "err"
+	if l.mask {
+		cause = "errors.Unwrap(err)"
+		if l.kind == "time.Duration" {
+			cause = `errors.New("invalid duration")`
+		}
+	}
+
+	if parse := kinds[l.kind]; parse == "" {
+		fmt.Fprintf(b, "\t\t%s = %s(v)\n", set, typ)
+	} else {
+		fmt.Fprintf(b, "\t\tif x, err := %s; err != nil {\n", fmt.Sprintf(parse, "v"))
+		if l.slice {
+			fmt.Fprintf(b, "\t\t\terrs = append(errs, fmt.Errorf(\"field %s (%s): element %%d: converting '%%s' to type %s: %%w\", i, %s, %s))\n", name, l.key, typ, shown, cause)
+		} else {
+			fmt.Fprintf(b, "\t\t\terrs = append(errs, fmt.Errorf(\"field %s (%s): converting '%%s' to type %s: %%w\", %s, %s))\n", name, l.key, typ, shown, cause)
+		}
+		fmt.Fprintf(b, "\t\t} else {\n\t\t\t%s = %s(x)\n\t\t}\n", set, typ)
+	}
+
+	if l.slice {
+		fmt.Fprintf(b, "\t\t}\n\t\t%s = s\n", target)
+	}
+
+	missing := fmt.Sprintf("&conf.RequiredError{Field: %q, EnvKey: %q, FlagKey: %q}", name, l.key, l.flag)
+
+	if l.require {
+		fmt.Fprintf(b, "\t} else {\n\t\terrs = append(errs, %s)\n", missing)
+	}
+
+	if l.lazy {
+		fmt.Fprintf(b, "\t\t%s = conf.NewLazyValue(lv, nil)\n", l.target)
+		fmt.Fprintf(b, "\t} else {\n\t\t%s = conf.NewLazyValue(*new(%s), %s)\n", l.target, l.typ, missing)
+	}
+
+	b.WriteString("\t}\n\n")
+}
+
+// run generates the function parsing the type declared in the package in
+// the directory and writes it to the output file.
+func run(dir, typeName, prefix, output string, args []string) error {
+	if output == "" {
+		output = strings.ToLower(typeName) + "_conf.go"
+	}
+	if !filepath.IsAbs(output) {
+		output = filepath.Join(dir, output)
+	}
+
+	g, err := load(dir, output)
+	if err != nil {
+		return err
+	}
+
+	st, ok := g.structType(&ast.Ident{Name: typeName})
+	if !ok {
+		return fmt.Errorf("struct type %s not found in %s", typeName, dir)
+	}
+
+	if method, ok := g.hook(&ast.Ident{Name: typeName}); ok {
+		return fmt.Errorf("type %s: method %s isn't supported by confgen, use conf.Parse", typeName, method)
+	}
+
+	if err := g.collect(st, strings.ToUpper(prefix), nil, "cfg"); err != nil {
+		return fmt.Errorf("type %s: %w", typeName, err)
+	}
+
+	src, err := g.generate(typeName, prefix, args)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(output, src, 0o644)
+}
+
+// joinKey joins the prefix and the key of a field.
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "_" + key
+}
+
+// flagName returns the flag key conf derives from the path of a field,
+// reported by the errors of required fields.
+func flagName(path []string) string {
+	parts := make([]string, 0, len(path))
+	for _, name := range path {
+		parts = append(parts, strings.Join(camelSplit(name), "-"))
+	}
+
+	return strings.ToLower(strings.Join(parts, "-"))
+}
+
+// camelSplit splits the name of a field at the changes of the case like
+// the keys of conf do, e.g. DebugHost is Debug Host and FOOBar is FOO Bar.
+func camelSplit(src string) []string {
+	if src == "" {
+		return []string{}
+	}
+
+	if len(src) < 2 {
+		return []string{src}
+	}
+
+	runes := []rune(src)
+
+	lastClass := charClass(runes[0])
+	lastIdx := 0
+	var out []string
+
+	for i, r := range runes {
+		class := charClass(r)
+
+		if class != lastClass {
+			switch {
+			case lastClass == classUpper && class != classNumber:
+				if i-lastIdx > 1 {
+					out = append(out, string(runes[lastIdx:i-1]))
+					lastIdx = i - 1
+				}
+			default:
+				out = append(out, string(runes[lastIdx:i]))
+				lastIdx = i
+			}
+		}
+
+		if i == len(runes)-1 {
+			out = append(out, string(runes[lastIdx:]))
+		}
+
+		lastClass = class
+	}
+
+	return out
+}
+
+const (
+	classLower int = iota
+	classUpper
+	classNumber
+	classOther
+)
+
+func charClass(r rune) int {
+	switch {
+	case unicode.IsLower(r):
+		return classLower
+	case unicode.IsUpper(r):
+		return classUpper
+	case unicode.IsDigit(r):
+		return classNumber
+	}
+	return classOther
+}
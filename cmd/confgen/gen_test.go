@@ -0,0 +1,389 @@
+package main
+
+import (
+	"go/ast"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/virp/conf"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+// source declares the config struct of the package generated for, which
+// must match config below.
+const source = `package app
+
+import "time"
+
+type Level string
+
+type Config struct {
+	Host     string        ` + "`conf:\"default:localhost\"`" + `
+	Port     int           ` + "`conf:\"default:8080\"`" + `
+	Debug    bool
+	Level    Level         ` + "`conf:\"default:info\"`" + `
+	Ratio    float64       ` + "`conf:\"default:0.5\"`" + `
+	Timeout  time.Duration ` + "`conf:\"default:5s\"`" + `
+	Tags     []string      ` + "`conf:\"sep:comma\"`" + `
+	Ports    []uint16
+	APIKey   string ` + "`conf:\"required,mask\"`" + `
+	PIN      int    ` + "`conf:\"mask\"`" + `
+	Region   string ` + "`conf:\"env:AWS_REGION\"`" + `
+	Internal string ` + "`conf:\"-\"`" + `
+	internal string
+	DB       struct {
+		DSN string ` + "`conf:\"required\"`" + `
+	}
+	Cache Cache ` + "`conf:\"prefix:redis\"`" + `
+	Meta
+}
+
+type Cache struct {
+	URL string ` + "`conf:\"default:redis://localhost\"`" + `
+}
+
+type Meta struct {
+	Owner string
+}
+`
+
+type Level string
+
+type Cache struct {
+	URL string `conf:"default:redis://localhost"`
+}
+
+type Meta struct {
+	Owner string
+}
+
+// config is the struct declared by source for conf.
+type config struct {
+	Host     string `conf:"default:localhost"`
+	Port     int    `conf:"default:8080"`
+	Debug    bool
+	Level    Level         `conf:"default:info"`
+	Ratio    float64       `conf:"default:0.5"`
+	Timeout  time.Duration `conf:"default:5s"`
+	Tags     []string      `conf:"sep:comma"`
+	Ports    []uint16
+	APIKey   string `conf:"required,mask"`
+	PIN      int    `conf:"mask"`
+	Region   string `conf:"env:AWS_REGION"`
+	Internal string `conf:"-"`
+	internal string
+	DB       struct {
+		DSN string `conf:"required"`
+	}
+	Cache Cache `conf:"prefix:redis"`
+	Meta
+}
+
+// test exercises the generated function in the package.
+const test = `package app
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/virp/conf"
+)
+
+func TestParseConfig(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("APP_API_KEY", "secret")
+	os.Setenv("APP_DB_DSN", "postgres://db")
+	os.Setenv("APP_PORT", "9090")
+	os.Setenv("APP_TAGS", "a,b")
+	os.Setenv("APP_PORTS", "80;443")
+	os.Setenv("APP_OWNER", "ops")
+	os.Setenv("AWS_REGION", "eu-west-1")
+
+	cfg, err := ParseConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Config{
+		Host:    "localhost",
+		Port:    9090,
+		Level:   "info",
+		Ratio:   0.5,
+		Timeout: 5 * time.Second,
+		Tags:    []string{"a", "b"},
+		Ports:   []uint16{80, 443},
+		APIKey:  "secret",
+		Region:  "eu-west-1",
+		Cache:   Cache{URL: "redis://localhost"},
+		Meta:    Meta{Owner: "ops"},
+	}
+	want.DB.DSN = "postgres://db"
+
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("got %+v, want %+v", cfg, want)
+	}
+
+	os.Clearenv()
+	os.Setenv("APP_PORT", "x")
+	os.Setenv("APP_API_KEY", "secret")
+	os.Setenv("APP_PIN", "hunter2")
+	os.Setenv("APP_TAGS", ` + "`" + `"a,b",c` + "`" + `)
+
+	_, err = ParseConfig()
+	if err == nil {
+		t.Fatal("want an error")
+	}
+
+	for _, msg := range []string{
+		"field Port (APP_PORT): converting 'x' to type int",
+		"required field DB.DSN (APP_DB_DSN) is missing value",
+		"field PIN (APP_PIN): converting 'xxxxxx' to type int: invalid syntax",
+		"field Tags (APP_TAGS): quoted and escaped items aren't supported by the generated code",
+	} {
+		if !strings.Contains(err.Error(), msg) {
+			t.Fatalf("error %q lacks %q", err, msg)
+		}
+	}
+
+	if strings.Contains(err.Error(), "hunter2") {
+		t.Fatalf("error %q reveals a masked value", err)
+	}
+
+	var re *conf.RequiredError
+	if !errors.As(err, &re) || re.EnvKey != "APP_DB_DSN" || re.FlagKey != "db-dsn" {
+		t.Fatalf("error %q isn't a conf.RequiredError of the field: %+v", err, re)
+	}
+}
+`
+
+func TestCollect_Keys(t *testing.T) {
+	t.Log("Given the need to derive the keys of the generated code like conf.")
+	{
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "config.go"), []byte(source), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		g, err := load(dir, "config_conf.go")
+		if err != nil {
+			t.Fatalf("\t%s\tShould be able to load the package : %s.", failed, err)
+		}
+
+		st, _ := g.structType(&ast.Ident{Name: "Config"})
+		if err := g.collect(st, "APP", nil, "cfg"); err != nil {
+			t.Fatalf("\t%s\tShould be able to collect the fields : %s.", failed, err)
+		}
+		t.Logf("\t%s\tShould be able to collect the fields.", success)
+
+		var got []string
+		for _, l := range g.leaves {
+			got = append(got, l.key)
+		}
+
+		fields, err := conf.Fields("app", &config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var want []string
+		for _, f := range fields {
+			want = append(want, f.EnvKey)
+		}
+
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("\t%s\tShould derive the keys of conf. Diff:\n%s", failed, diff)
+		}
+		t.Logf("\t%s\tShould derive the keys of conf.", success)
+	}
+}
+
+func TestCollect_Unsupported(t *testing.T) {
+	tests := []struct {
+		name  string
+		decl  string
+		want  string
+		extra string
+	}{
+		{"option", "Port int `conf:\"min:1\"`", "tag option min isn't supported by confgen", ""},
+		{"embed", "Banner string `conf:\"default:embed:banner.txt\"`", "tag option default:embed isn't supported by confgen", ""},
+		{"lazy", "Key string `conf:\"required:lazy\"`", "tag option required:lazy requires a conf.LazyValue field", ""},
+		{"pointer", "Port *int", "unsupported type *int", ""},
+		{"map", "Labels map[string]string", "unsupported type map[string]string", ""},
+		{"foreign", "Addr net.IP", "unsupported type net.IP", ""},
+		{"setter", "Level Level `conf:\"default:info\"`", "method Set of type Level isn't supported by confgen",
+			"type Level struct{ level string }\n\nfunc (l *Level) Set(s string) error { l.level = s; return nil }\n"},
+		{"text unmarshaler", "Levels []*Level", "method UnmarshalText of type []*Level isn't supported by confgen",
+			"type Level struct{ level string }\n\nfunc (l *Level) UnmarshalText(b []byte) error { l.level = string(b); return nil }\n"},
+		{"defaulter", "Port int", "type Config: method Defaults isn't supported by confgen",
+			"func (c *Config) Defaults() { c.Port = 8080 }\n"},
+		{"validator", "DB DB", "method Validate of type DB isn't supported by confgen",
+			"type DB struct{ Host string }\n\nfunc (d DB) Validate() error { return nil }\n"},
+	}
+
+	t.Log("Given the need to refuse the fields the generated code can't parse like conf.")
+	{
+		for i, tt := range tests {
+			t.Logf("\tTest: %d\tWhen checking a field with %s.", i, tt.name)
+			{
+				dir := t.TempDir()
+				src := "package app\n\ntype Config struct {\n\t" + tt.decl + "\n}\n\n" + tt.extra
+				if err := os.WriteFile(filepath.Join(dir, "config.go"), []byte(src), 0o644); err != nil {
+					t.Fatal(err)
+				}
+
+				err := run(dir, "Config", "app", "", nil)
+				if err == nil || !strings.Contains(err.Error(), tt.want) {
+					t.Fatalf("\t%s\tShould fail with %q : %v.", failed, tt.want, err)
+				}
+				t.Logf("\t%s\tShould fail with %q.", success, tt.want)
+			}
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a module")
+	}
+
+	t.Log("Given the need to parse a config struct with the generated code.")
+	{
+		dir := t.TempDir()
+
+		writeModule(t, dir, map[string]string{
+			"config.go":      source,
+			"config_test.go": test,
+		})
+
+		args := []string{"-type", "Config", "-prefix", "app"}
+		if err := run(dir, "Config", "app", "", args); err != nil {
+			t.Fatalf("\t%s\tShould be able to generate the code : %s.", failed, err)
+		}
+		t.Logf("\t%s\tShould be able to generate the code.", success)
+
+		src, err := os.ReadFile(filepath.Join(dir, "config_conf.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.HasPrefix(string(src), "// Code generated by confgen -type Config -prefix app; DO NOT EDIT.") {
+			t.Fatalf("\t%s\tShould mark the code as generated : %s.", failed, src)
+		}
+		t.Logf("\t%s\tShould mark the code as generated.", success)
+
+		if strings.Contains(string(src), `"reflect"`) {
+			t.Fatalf("\t%s\tShould not use reflection.", failed)
+		}
+		t.Logf("\t%s\tShould not use reflection.", success)
+
+		cmd := exec.Command("go", "test", "./...")
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("\t%s\tShould parse the config struct : %s\n%s\n%s", failed, err, out, src)
+		}
+		t.Logf("\t%s\tShould parse the config struct.", success)
+	}
+}
+
+func TestRun_Lazy(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a module")
+	}
+
+	t.Log("Given the need to parse lazy values with the generated code.")
+	{
+		dir := t.TempDir()
+
+		writeModule(t, dir, map[string]string{
+			"config.go": `package app
+
+import cfg "github.com/virp/conf"
+
+type Config struct {
+	Key  cfg.LazyValue[string]   ` + "`conf:\"required:lazy\"`" + `
+	Port cfg.LazyValue[int]      ` + "`conf:\"required:lazy\"`" + `
+	Tags cfg.LazyValue[[]string] ` + "`conf:\"required:lazy,sep:comma\"`" + `
+}
+`,
+			"config_test.go": `package app
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("APP_PORT", "80")
+	os.Setenv("APP_TAGS", "a,b")
+
+	cfg, err := ParseConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Port.Get() != 80 || len(cfg.Tags.Get()) != 2 {
+		t.Fatalf("got %+v", cfg)
+	}
+
+	if err := cfg.Key.Err(); err == nil || err.Error() != "required field Key (APP_KEY) is missing value" {
+		t.Fatalf("got error %v", err)
+	}
+}
+`,
+		})
+
+		if err := run(dir, "Config", "app", "", nil); err != nil {
+			t.Fatalf("\t%s\tShould be able to generate the code : %s.", failed, err)
+		}
+		t.Logf("\t%s\tShould be able to generate the code.", success)
+
+		cmd := exec.Command("go", "test", "./...")
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			src, _ := os.ReadFile(filepath.Join(dir, "config_conf.go"))
+			t.Fatalf("\t%s\tShould set the lazy values : %s\n%s\n%s", failed, err, out, src)
+		}
+		t.Logf("\t%s\tShould set the lazy values.", success)
+	}
+}
+
+// writeModule writes the files of a module depending on this version of
+// conf into the directory.
+func writeModule(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files["go.mod"] = "module app\n\ngo 1.22\n\nrequire github.com/virp/conf v0.0.0\n\nreplace github.com/virp/conf => " + root + "\n"
+	files["go.sum"] = string(sum)
+
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Confgen generates a function parsing a config struct from the environment
+// like conf.Parse, without reflection, for programs which start often and
+// briefly such as CLIs and serverless functions. Add a directive next to the
+// config struct and run go generate:
+//
+//	//go:generate go run github.com/virp/conf/cmd/confgen -type Config -prefix my_service
+//
+// This writes config_conf.go declaring ParseConfig() (Config, error). The
+// keys, defaults and required fields are those of conf.Parse. Tag options
+// the generated code can't honor, such as min or file, and types with the
+// methods conf calls, such as Set or Validate, make confgen fail rather than
+// generate a function behaving differently.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	var (
+		typeName = flag.String("type", "", "name of the config struct type; required")
+		prefix   = flag.String("prefix", "", "prefix of the env keys")
+		output   = flag.String("output", "", "output file name; default <type>_conf.go")
+		dir      = flag.String("dir", ".", "directory of the package declaring the type")
+	)
+	flag.Parse()
+
+	if *typeName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*dir, *typeName, *prefix, *output, os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "confgen:", err)
+		os.Exit(1)
+	}
+}
@@ -1,18 +1,76 @@
 package conf
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"os"
+	"io/fs"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
 )
 
 // Parse parses the specified config struct.
 // This function will apply the defaults first and then
-// apply environment variables to the struct.
-func Parse(prefix string, cfg any) error {
+// apply environment variables and command line flags to the struct.
+// Flags take precedence over environment variables.
+// A pointer to a named map or slice type is accepted as well,
+// in which case the prefix itself is used as the env variable name.
+//
+// The options configure the sources, flags and keys, see the With functions.
+func Parse(prefix string, cfg any, opts ...Option) error {
+	o := newOptions(opts)
+
+	lookup := func(fields []Field) (map[string]string, error) {
+		return resolveValues(fields, o)
+	}
+
+	return parse(prefix, cfg, o, lookup)
+}
+
+// ParseContext is like Parse but aborts once the context is done,
+// see WithContext.
+func ParseContext(ctx context.Context, prefix string, cfg any, opts ...Option) error {
+	return Parse(prefix, cfg, append(opts[:len(opts):len(opts)], WithContext(ctx))...)
+}
+
+// ParseWithArgs is like Parse but reads the flags from args in place of
+// os.Args[1:], so the flags can be tested deterministically, see WithArgs.
+// The args hold no program name, such as []string{"--port", "8080"}.
+func ParseWithArgs(prefix string, cfg any, args []string, opts ...Option) error {
+	return Parse(prefix, cfg, append(opts[:len(opts):len(opts)], WithArgs(args))...)
+}
+
+// ParseFor parses a new config struct of type T and returns it, sparing
+// the declaration of the variable:
+//
+//	cfg, err := conf.ParseFor[Config]("my_service")
+//
+// The zero value is returned with the error. The returned value is a copy
+// of the parsed one, so the functions reporting on the last parse such as
+// Sources don't know it, use Parse for them.
+func ParseFor[T any](prefix string, opts ...Option) (T, error) {
+	var cfg T
+	if err := Parse(prefix, &cfg, opts...); err != nil {
+		var zero T
+		return zero, err
+	}
+	provenance.Delete(&cfg)
+
+	return cfg, nil
+}
+
+// parse does the work for Parse, Record and Replay. The values for the
+// fields collected from the config struct are provided by lookup
+// keyed by the field env key.
+func parse(prefix string, cfg any, o *options, lookup func(fields []Field) (map[string]string, error)) error {
+	if err := o.invalidErr(); err != nil {
+		return err
+	}
 
 	// Get the list of fields from the configuration struct to process.
-	fields, err := extractFields(prefix, cfg)
+	fields, err := extractFields(prefix, o.separator, nil, nil, cfg)
 	if err != nil {
 		return fmt.Errorf("extract fields from config struct: %w", err)
 	}
@@ -21,79 +79,295 @@ func Parse(prefix string, cfg any) error {
 		return errors.New("no fields identified in config struct")
 	}
 
-	// Collect all env names for fields.
-	envNames := collectFieldsEnvNames(fields)
+	if err := checkKeys(reflect.TypeOf(cfg).Elem(), fields); err != nil {
+		return err
+	}
+
+	return parseFields(prefix, cfg, fields, o, lookup)
+}
+
+// parseFields parses the config struct into the fields extracted from it,
+// for parse and the Parser holding them compiled.
+func parseFields(prefix string, cfg any, fields []Field, o *options, lookup func(fields []Field) (map[string]string, error)) error {
+	o.env = o.environment()
+	_, o.versioned = findVersion(cfg)
+
+	if err := bindOptions(fields, o); err != nil {
+		return err
+	}
 
-	// Get all existed env variables values for fields.
-	envValues := getEnvValues(envNames)
+	// Let the structs construct their defaults in code.
+	applyDefaults(reflect.ValueOf(cfg).Elem())
+
+	// Make room for the elements of slices and maps of structs.
+	fields, err := expandElements(fields, o)
+	if err != nil {
+		return err
+	}
+
+	// Get all existed values for fields.
+	values, err := lookup(fields)
+	if err != nil {
+		return err
+	}
+
+	checkPrefix(prefix, cfg, values, o)
+
+	if err := checkUnknownKeys(prefix, fields, o); err != nil {
+		return err
+	}
+
+	if err := checkRemovals(cfg, fields, values, o); err != nil {
+		return err
+	}
+
+	checkDeprecations(fields, values, o)
+
+	if err := checkExpiries(fields, o); err != nil {
+		return err
+	}
+
+	origins := fieldOrigins(fields, values, o.origins)
 
 	// Process all fields found in the config struct provided.
-	if err := processFields(fields, envValues); err != nil {
+	if err := processFields(fields, values, o); err != nil {
 		return err
 	}
+	storeMapEntries(o)
+
+	// Let the structs check their fields against each other.
+	if err := validateStructs(reflect.ValueOf(cfg).Elem(), nil); err != nil {
+		return err
+	}
+
+	if o.provenance {
+		recordParse(cfg, prefix, origins, o)
+	}
 
 	return nil
 }
 
-func collectFieldsEnvNames(fields []Field) []string {
-	envNames := make([]string, 0, len(fields))
+// processFields sets the values into the fields. It doesn't stop at the
+// first bad field but returns the errors of all of them joined, so every
+// misconfigured value can be fixed at once. The time spent on every field
+// is added to its timing.
+func processFields(fields []Field, envValues map[string]string, o *options) error {
+	var errs []error
+
+	hints := missingHints(fields, envValues, o)
 
 	for _, field := range fields {
-		envNames = append(envNames, field.EnvKey)
+
+		// Once the context is done every other field would fail the same way.
+		if len(errs) > 0 && o.ctx.Err() != nil {
+			break
+		}
+
+		var saved reflect.Value
+		if o.partial {
+			saved = reflect.New(field.Field.Type()).Elem()
+			saved.Set(field.Field)
+		}
+
+		start := time.Now()
+		err := processValue(o.ctx, field, envValues, hints[field.EnvKey])
+		o.addTiming(field.EnvKey, time.Since(start))
+
+		if field.Options.Lazy {
+			lazyValue(field, envValues, hints[field.EnvKey])
+		}
+
+		var fe *FieldError
+		if err != nil && o.partial && o.ctx.Err() == nil && errors.As(err, &fe) {
+			err = partialValue(field, saved, err, o)
+		}
+
+		if err != nil {
+			errs = append(errs, err)
+		}
 	}
 
-	return envNames
+	return errors.Join(errs...)
 }
 
-func getEnvValues(envNames []string) map[string]string {
-	envValues := make(map[string]string)
+// partialValue leaves the field failing conversion at its default for
+// WithPartialParse, reporting the error as a warning. The field is reset
+// to the value it had before and its default set again without the value,
+// a default failing as well leaves it as it was.
+func partialValue(field Field, saved reflect.Value, err error, o *options) error {
+	field.Field.Set(saved)
 
-	for _, envName := range envNames {
-		if value, ok := os.LookupEnv(envName); ok {
-			envValues[envName] = value
+	if !field.Options.Required {
+		if derr := processValue(o.ctx, field, nil, ""); derr != nil {
+			field.Field.Set(saved)
 		}
 	}
 
-	return envValues
+	o.warn(Warning{Key: field.EnvKey, Message: fmt.Sprintf("%s, left at the default", err)})
+
+	return nil
 }
 
-func processFields(fields []Field, envValues map[string]string) error {
-	for _, field := range fields {
+// processValue sets the default and then the value found for the field.
+func processValue(ctx context.Context, field Field, envValues map[string]string, hint string) error {
 
-		// Set any default value into the struct for this field.
-		if field.Options.DefaultVal != "" {
-			if err := processField(true, field.Options.DefaultVal, field.Field); err != nil {
-				return &FieldError{
-					fieldName: field.Name,
-					envKey:    field.EnvKey,
-					typeName:  field.Field.Type().String(),
-					value:     field.Options.DefaultVal,
-					err:       err,
-				}
-			}
+	// Set any default value into the struct for this field.
+	if field.Options.DefaultVal != "" {
+		if err := convertField(ctx, true, field.Options.DefaultVal, field); err != nil {
+			return err
+		}
+	}
+
+	value, ok := envValues[field.EnvKey]
+
+	if field.Options.Required && !ok {
+		return &RequiredError{
+			Field:   strings.Join(field.Path, "."),
+			EnvKey:  field.EnvKey,
+			FlagKey: field.FlagKey,
+			found:   hint,
+		}
+	}
+
+	if field.Options.DefaultEmbed != "" && field.defaults == nil {
+		return fmt.Errorf("embedded default of field %s (%s) requires WithDefaultsFS", strings.Join(field.Path, "."), field.EnvKey)
+	}
+
+	// Load the default from the file only when no source provided a value.
+	if !ok && field.Options.DefaultFile != "" {
+		data, err := readFile(field.fsys, field.Options.DefaultFile)
+		if err != nil {
+			return fmt.Errorf("read default file for field %s (%s): %w", strings.Join(field.Path, "."), field.EnvKey, err)
 		}
 
-		value, ok := envValues[field.EnvKey]
+		if err := convertField(ctx, true, string(data), field); err != nil {
+			return err
+		}
+	}
 
-		if field.Options.Required && !ok {
-			return fmt.Errorf("required field %s (%s) is missing value", field.Name, field.EnvKey)
+	if !ok && field.Options.DefaultEmbed != "" {
+		data, err := fs.ReadFile(field.defaults, field.Options.DefaultEmbed)
+		if err != nil {
+			return fmt.Errorf("read embedded default for field %s (%s): %w", strings.Join(field.Path, "."), field.EnvKey, err)
 		}
 
-		if !ok {
-			continue
+		if err := convertField(ctx, true, string(data), field); err != nil {
+			return err
+		}
+	}
+
+	if !ok {
+		return nil
+	}
+
+	// A value was found so update the struct value with it.
+	return convertField(ctx, false, value, field)
+}
+
+// redactValue hides the value of masked fields and the password of DSN,
+// URL and proxy URL fields, so it can be reported in errors.
+func redactValue(value string, field Field) string {
+	typ := field.Field.Type()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch {
+	case field.Options.Mask || field.Options.Secret:
+		if field.redact != nil {
+			return field.redact(value)
+		}
+		return maskedValue
+	case typ == reflect.TypeOf(DSN{}):
+		return redactDSN(value)
+	case typ == urlType || typ == reflect.TypeOf(ProxyURL{}):
+		u, err := url.Parse(value)
+		if err != nil {
+			return maskedValue
 		}
+		return u.Redacted()
+	}
+
+	return value
+}
 
-		// A value was found so update the struct value with it.
-		if err := processField(false, value, field.Field); err != nil {
+// convertField sets the value into the field, giving up once ctx is done.
+func convertField(ctx context.Context, settingDefault bool, value string, field Field) error {
+	convert := func(target reflect.Value) error {
+		if err := checkLimits(value, field); err != nil {
 			return &FieldError{
-				fieldName: field.Name,
+				fieldName: strings.Join(field.Path, "."),
 				envKey:    field.EnvKey,
 				typeName:  field.Field.Type().String(),
-				value:     field.Options.DefaultVal,
+				value:     abbreviate(redactValue(value, field)),
 				err:       err,
 			}
 		}
+
+		err := processFieldSafe(settingDefault, value, target, field.Options)
+		if err == nil {
+			err = validateField(target, field.Options)
+		}
+
+		if err != nil {
+			return &FieldError{
+				fieldName: strings.Join(field.Path, "."),
+				envKey:    field.EnvKey,
+				typeName:  field.Field.Type().String(),
+				value:     redactValue(value, field),
+				err:       redactError(err, value, field),
+			}
+		}
+
+		return nil
 	}
 
-	return nil
+	if ctx.Done() == nil {
+		return convert(field.Field)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("parse aborted before field %s (%s): %w", strings.Join(field.Path, "."), field.EnvKey, err)
+	}
+
+	// Run the conversion on its own so a custom type doing I/O can't hang
+	// the parse. It converts into a copy of the field set into the struct
+	// once done in time, a stalled conversion running on in the background
+	// never touches the struct.
+	scratch := scratchValue(field.Field)
+
+	errc := make(chan error, 1)
+	go func() { errc <- convert(scratch) }()
+
+	select {
+	case err := <-errc:
+		if err != nil {
+			return err
+		}
+
+		if field.Field.Kind() == reflect.Ptr && !field.Field.IsNil() {
+			field.Field.Elem().Set(scratch.Elem())
+		} else {
+			field.Field.Set(scratch)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("converting field %s (%s) stalled: %w", strings.Join(field.Path, "."), field.EnvKey, ctx.Err())
+	}
+}
+
+// scratchValue returns a copy of the value to convert into apart from it,
+// a pointer pointing to a copy of its value.
+func scratchValue(v reflect.Value) reflect.Value {
+	scratch := reflect.New(v.Type()).Elem()
+
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		elem := reflect.New(v.Type().Elem())
+		elem.Elem().Set(v.Elem())
+		scratch.Set(elem)
+		return scratch
+	}
+
+	scratch.Set(v)
+	return scratch
 }
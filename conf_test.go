@@ -1,8 +1,12 @@
 package conf
 
 import (
+	"context"
+	"embed"
+	"errors"
 	"fmt"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -249,6 +253,30 @@ func TestParse_Required(t *testing.T) {
 			t.Logf("\t%s\tShoud fail for missing required value with env variablae name in message : %s.", success, err)
 		})
 	}
+	t.Log("When required values are missing the error should be typed.")
+	{
+		t.Run("required-error-type", func(t *testing.T) {
+			os.Clearenv()
+
+			var cfg struct {
+				DB struct {
+					Host string `conf:"required"`
+				}
+			}
+
+			err := Parse("test", &cfg, WithEnviron(nil), WithArgs(nil))
+
+			var re *RequiredError
+			if !errors.As(err, &re) {
+				t.Fatalf("\t%s\tShould fail with a RequiredError : %v.", failed, err)
+			}
+
+			if re.Field != "DB.Host" || re.EnvKey != "TEST_DB_HOST" || re.FlagKey != "db-host" {
+				t.Fatalf("\t%s\tShould carry the field and its keys : %+v.", failed, re)
+			}
+			t.Logf("\t%s\tShould fail with a RequiredError carrying the field and its keys.", success)
+		})
+	}
 }
 
 func TestParse_Errors(t *testing.T) {
@@ -296,3 +324,983 @@ func TestParse_Errors(t *testing.T) {
 		})
 	}
 }
+
+type hosts []string
+
+type labels map[string]int
+
+func TestParse_NonStruct(t *testing.T) {
+	t.Log("When passing a named slice to Parse.")
+	{
+		t.Run("named-slice", func(t *testing.T) {
+			os.Clearenv()
+			_ = os.Setenv("HOSTS", "a.local;b.local")
+
+			var cfg hosts
+			if err := Parse("hosts", &cfg); err != nil {
+				t.Fatalf("\t%s\tShould be able to parse into a named slice : %s.", failed, err)
+			}
+
+			if diff := cmp.Diff(hosts{"a.local", "b.local"}, cfg); diff != "" {
+				t.Fatalf("\t%s\tShould have properly initialized slice value\n%s", failed, diff)
+			}
+			t.Logf("\t%s\tShould have properly initialized slice value.", success)
+		})
+	}
+
+	t.Log("When passing a named map to Parse.")
+	{
+		t.Run("named-map", func(t *testing.T) {
+			os.Clearenv()
+			_ = os.Setenv("TEST_LABELS", "a:1;b:2")
+
+			var cfg labels
+			if err := Parse("test_labels", &cfg); err != nil {
+				t.Fatalf("\t%s\tShould be able to parse into a named map : %s.", failed, err)
+			}
+
+			if diff := cmp.Diff(labels{"a": 1, "b": 2}, cfg); diff != "" {
+				t.Fatalf("\t%s\tShould have properly initialized map value\n%s", failed, diff)
+			}
+			t.Logf("\t%s\tShould have properly initialized map value.", success)
+		})
+
+		t.Run("named-map-without-prefix", func(t *testing.T) {
+			var cfg labels
+			if err := Parse("", &cfg); err == nil {
+				t.Fatalf("\t%s\tShould NOT be able to parse into a named map without prefix.", failed)
+			}
+			t.Logf("\t%s\tShould NOT be able to parse into a named map without prefix.", success)
+		})
+	}
+}
+
+func TestParse_Flags(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("TEST_AN_INT", "1")
+	_ = os.Setenv("TEST_PASSWORD", "gopher")
+
+	args := os.Args
+	defer func() { os.Args = args }()
+	os.Args = []string{"app", "--an-int", "5", "--bool", "--ip-name=flag", "--debug-host", "http://flag:4000", "positional", "--", "--password", "ignored"}
+
+	want := config{5, "B", true, "", ip{"flag", "127.0.0.0", []string{"127.0.0.1:200", "127.0.0.1:829"}}, "http://flag:4000", "gopher", CustomValue{something: "@hello@"}, Embed{"sergey", time.Second}}
+
+	var cfg config
+	if err := Parse("test", &cfg); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse command line flags : %s.", failed, err)
+	}
+	t.Logf("\t%s\tShould be able to parse command line flags.", success)
+
+	if diff := cmp.Diff(want, cfg); diff != "" {
+		t.Fatalf("\t%s\tShould have flags take precedence over env variables\n%s", failed, diff)
+	}
+	t.Logf("\t%s\tShould have flags take precedence over env variables.", success)
+
+	os.Args = []string{"app", "--an-int"}
+	if err := Parse("test", &cfg); err == nil {
+		t.Fatalf("\t%s\tShould NOT be able to accept flag missing value.", failed)
+	}
+	t.Logf("\t%s\tShould NOT be able to accept flag missing value.", success)
+}
+
+func TestParse_FlagNames(t *testing.T) {
+	os.Clearenv()
+
+	type names struct {
+		DebugHost string `conf:"flag:debug,short:d"`
+		Verbose   bool   `conf:"short:v"`
+		Workers   int    `conf:"short:w"`
+	}
+
+	var cfg names
+	if err := Parse("test", &cfg, WithArgs([]string{"--debug", "http://flag", "-v", "-w=4"})); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse custom flag names : %s.", failed, err)
+	}
+
+	if want := (names{"http://flag", true, 4}); cfg != want {
+		t.Fatalf("\t%s\tShould have set fields from custom flag names, got %+v.", failed, cfg)
+	}
+	t.Logf("\t%s\tShould have set fields from custom flag names.", success)
+
+	if err := Parse("test", &cfg, WithArgs([]string{"--debug-host", "http://derived"})); err != nil || cfg.DebugHost != "http://flag" {
+		t.Fatalf("\t%s\tShould replace the derived flag name : %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould replace the derived flag name.", success)
+
+	t.Run("collision", func(t *testing.T) {
+		var long struct {
+			DebugHost string `conf:"flag:debug"`
+			Debug     bool
+		}
+
+		err := Parse("test", &long)
+		if err == nil || !strings.Contains(err.Error(), "DebugHost and Debug share the flag --debug") {
+			t.Fatalf("\t%s\tShould fail for fields sharing a flag : %v.", failed, err)
+		}
+		t.Logf("\t%s\tShould fail for fields sharing a flag : %s.", success, err)
+
+		var short struct {
+			Debug bool `conf:"short:d"`
+			Dry   bool `conf:"short:d"`
+		}
+
+		err = Parse("test", &short)
+		if err == nil || !strings.Contains(err.Error(), "Debug and Dry share the flag -d") {
+			t.Fatalf("\t%s\tShould fail for fields sharing a short flag : %v.", failed, err)
+		}
+		t.Logf("\t%s\tShould fail for fields sharing a short flag : %s.", success, err)
+	})
+}
+
+// level provides support for testing an enum value.
+type level string
+
+// Set implements the Setter interface
+func (l *level) Set(data string) error {
+	switch data {
+	case "debug", "info":
+		*l = level(data)
+		return nil
+	}
+	return fmt.Errorf("unknown level %q", data)
+}
+
+// Values implements the Enum interface
+func (level) Values() []string {
+	return []string{"debug", "info"}
+}
+
+func TestParse_ConversionErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		cfg  any
+		want string
+	}{
+		{"bool", "yes", &struct{ Value bool }{}, "expected one of true/false/1/0/t/f"},
+		{"duration", "7d", &struct{ Value time.Duration }{}, "expected a duration such as 300ms, 1.5h or 2h45m"},
+		{"int8", "300", &struct{ Value int8 }{}, "expected an integer from -128 to 127"},
+		{"uint16", "-1", &struct{ Value uint16 }{}, "expected an integer from 0 to 65535"},
+		{"enum", "trace", &struct{ Value level }{}, "expected one of debug/info"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			_ = os.Setenv("TEST_VALUE", tt.env)
+
+			err := Parse("test", tt.cfg)
+			if err == nil {
+				t.Fatalf("\t%s\tShould fail converting %q.", failed, tt.env)
+			}
+
+			if !strings.Contains(err.Error(), tt.want) || !strings.Contains(err.Error(), tt.env) {
+				t.Fatalf("\t%s\tShould describe accepted values and the bad value in error : %s.", failed, err)
+			}
+			t.Logf("\t%s\tShould describe accepted values and the bad value in error : %s.", success, err)
+		})
+	}
+}
+
+func TestParse_AllErrors(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("TEST_PORT", "http")
+	_ = os.Setenv("TEST_DEBUG", "yes")
+
+	var cfg struct {
+		Port  int
+		Debug bool
+		Host  string `conf:"required"`
+	}
+
+	err := Parse("test", &cfg)
+	if err == nil {
+		t.Fatalf("\t%s\tShould fail for misconfigured fields.", failed)
+	}
+
+	for _, want := range []string{"TEST_PORT", "TEST_DEBUG", "TEST_HOST"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("\t%s\tShould report every misconfigured field, missing %s : %s.", failed, want, err)
+		}
+	}
+	t.Logf("\t%s\tShould report every misconfigured field : %s.", success, err)
+
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.envKey != "TEST_PORT" {
+		t.Fatalf("\t%s\tShould keep the field errors accessible : %v.", failed, fe)
+	}
+	t.Logf("\t%s\tShould keep the field errors accessible.", success)
+}
+
+func TestParse_Durations(t *testing.T) {
+	type retry struct {
+		Backoff  []time.Duration          `conf:"min:10ms,max:1m"`
+		Timeouts map[string]time.Duration `conf:"max:30s"`
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		os.Clearenv()
+		_ = os.Setenv("TEST_BACKOFF", "100ms;1s;30s")
+		_ = os.Setenv("TEST_TIMEOUTS", "read:5s;write:10s")
+
+		var cfg retry
+		if err := Parse("test", &cfg); err != nil {
+			t.Fatalf("\t%s\tShould be able to parse durations lists and maps : %s.", failed, err)
+		}
+
+		want := retry{
+			Backoff:  []time.Duration{100 * time.Millisecond, time.Second, 30 * time.Second},
+			Timeouts: map[string]time.Duration{"read": 5 * time.Second, "write": 10 * time.Second},
+		}
+		if diff := cmp.Diff(want, cfg); diff != "" {
+			t.Fatalf("\t%s\tShould have parsed every element as a duration\n%s", failed, diff)
+		}
+		t.Logf("\t%s\tShould have parsed every element as a duration.", success)
+	})
+
+	tests := []struct {
+		name string
+		key  string
+		env  string
+		want string
+	}{
+		{"no-unit", "TEST_BACKOFF", "100ms;5", "element 1: time: missing unit"},
+		{"below-min", "TEST_BACKOFF", "1ms;1s", "element 0: value 1ms is out of bounds, min is 10ms"},
+		{"above-max", "TEST_TIMEOUTS", "read:5s;write:1m", "key write: value 1m0s is out of bounds, max is 30s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			_ = os.Setenv(tt.key, tt.env)
+
+			var cfg retry
+			err := Parse("test", &cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("\t%s\tShould describe the bad element in error, want %q : %v.", failed, tt.want, err)
+			}
+			t.Logf("\t%s\tShould describe the bad element in error : %s.", success, err)
+		})
+	}
+}
+
+func TestParse_Separators(t *testing.T) {
+	type seps struct {
+		Hosts  []string          `conf:"sep:comma"`
+		Labels map[string]string `conf:"sep:comma,kvsep:="`
+		Ports  []int
+	}
+
+	os.Clearenv()
+	_ = os.Setenv("TEST_HOSTS", "http://a:80,http://b:81")
+	_ = os.Setenv("TEST_LABELS", "env=prod,url=http://c:82")
+	_ = os.Setenv("TEST_PORTS", "80|81")
+
+	var cfg seps
+	if err := Parse("test", &cfg, WithListSeparator("|")); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse values with custom separators : %s.", failed, err)
+	}
+
+	want := seps{
+		Hosts:  []string{"http://a:80", "http://b:81"},
+		Labels: map[string]string{"env": "prod", "url": "http://c:82"},
+		Ports:  []int{80, 81},
+	}
+	if diff := cmp.Diff(want, cfg); diff != "" {
+		t.Fatalf("\t%s\tShould have split values on custom separators\n%s", failed, diff)
+	}
+	t.Logf("\t%s\tShould have split values on custom separators.", success)
+
+	out, err := String(&cfg, WithListSeparator("|"))
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to render values with custom separators : %s.", failed, err)
+	}
+
+	if wantOut := "HOSTS=http://a:80,http://b:81\nLABELS=env=prod,url=http://c:82\nPORTS=80|81\n"; out != wantOut {
+		t.Fatalf("\t%s\tShould render values with custom separators, got %q.", failed, out)
+	}
+	t.Logf("\t%s\tShould render values with custom separators.", success)
+
+	var bad struct {
+		Labels map[string]string `conf:"sep:=,kvsep:="`
+	}
+	if err := Parse("test", &bad); err == nil {
+		t.Fatalf("\t%s\tShould NOT be able to accept the same sep and kvsep.", failed)
+	}
+	t.Logf("\t%s\tShould NOT be able to accept the same sep and kvsep.", success)
+}
+
+// logLevel provides support for testing map keys of a custom type.
+type logLevel int
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (l *logLevel) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	case "error":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %q", text)
+	}
+	return nil
+}
+
+// String implements the fmt.Stringer interface.
+func (l logLevel) String() string {
+	return [...]string{"debug", "info", "error"}[l]
+}
+
+func TestParse_MapKeys(t *testing.T) {
+	type config struct {
+		Handlers map[logLevel]string
+		Weights  map[CustomValue]int
+	}
+
+	os.Clearenv()
+
+	env := map[string]string{"TEST_HANDLERS": "INFO:stdout;error:stderr", "TEST_WEIGHTS": "a:1"}
+
+	var cfg config
+	if err := Parse("test", &cfg, WithEnviron(env), WithArgs(nil)); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse maps with custom keys : %s.", failed, err)
+	}
+
+	if cfg.Handlers[1] != "stdout" || cfg.Handlers[2] != "stderr" || cfg.Weights[CustomValue{something: "@a@"}] != 1 {
+		t.Fatalf("\t%s\tShould convert the keys : %+v.", failed, cfg)
+	}
+	t.Logf("\t%s\tShould convert the keys.", success)
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"info:stdout;warn:stderr", `invalid key of map item "warn:stderr": unknown level "warn"`},
+		{"info:stdout;INFO:stderr", `duplicate key of map item "INFO:stderr"`},
+	}
+
+	for _, tt := range tests {
+		err := Parse("test", &cfg, WithEnviron(map[string]string{"TEST_HANDLERS": tt.value}), WithArgs(nil))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Fatalf("\t%s\tShould report the offending item of %q : %v.", failed, tt.value, err)
+		}
+		t.Logf("\t%s\tShould report the offending item of %q.", success, tt.value)
+	}
+}
+
+func TestParse_Time(t *testing.T) {
+	type times struct {
+		Start   time.Time
+		Holiday time.Time   `conf:"layout:2006-01-02"`
+		Windows []time.Time `conf:"layout:15:04"`
+	}
+
+	os.Clearenv()
+	_ = os.Setenv("TEST_START", "2024-03-01T10:30:00Z")
+	_ = os.Setenv("TEST_HOLIDAY", "2024-12-25")
+	_ = os.Setenv("TEST_WINDOWS", "08:00;20:30")
+
+	var cfg times
+	if err := Parse("test", &cfg); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse times : %s.", failed, err)
+	}
+
+	want := times{
+		Start:   time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC),
+		Holiday: time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC),
+		Windows: []time.Time{time.Date(0, 1, 1, 8, 0, 0, 0, time.UTC), time.Date(0, 1, 1, 20, 30, 0, 0, time.UTC)},
+	}
+	if diff := cmp.Diff(want, cfg); diff != "" {
+		t.Fatalf("\t%s\tShould have parsed times in their layouts\n%s", failed, diff)
+	}
+	t.Logf("\t%s\tShould have parsed times in their layouts.", success)
+
+	out, err := String(&cfg)
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to render times : %s.", failed, err)
+	}
+
+	if wantOut := "START=2024-03-01T10:30:00Z\nHOLIDAY=2024-12-25\nWINDOWS=08:00;20:30\n"; out != wantOut {
+		t.Fatalf("\t%s\tShould render times in their layouts, got %q.", failed, out)
+	}
+	t.Logf("\t%s\tShould render times in their layouts.", success)
+
+	os.Clearenv()
+	_ = os.Setenv("TEST_HOLIDAY", "25.12.2024")
+
+	err = Parse("test", &cfg)
+	if err == nil || !strings.Contains(err.Error(), "expected a time in the layout 2006-01-02") {
+		t.Fatalf("\t%s\tShould describe the layout in error : %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould describe the layout in error : %s.", success, err)
+}
+
+func TestParse_Validation(t *testing.T) {
+	type limits struct {
+		Port  uint16   `conf:"default:8080,min:1024,max:65535"`
+		Mode  string   `conf:"default:dev,oneof:dev|staging|prod"`
+		Token string   `conf:"len:32"`
+		Hosts []string `conf:"len:1..3"`
+	}
+
+	os.Clearenv()
+	_ = os.Setenv("TEST_HOSTS", "a;b")
+
+	var cfg limits
+	if err := Parse("test", &cfg); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse valid values : %s.", failed, err)
+	}
+	t.Logf("\t%s\tShould be able to parse valid values.", success)
+
+	tests := []struct {
+		name string
+		key  string
+		env  string
+		want string
+	}{
+		{"min", "TEST_PORT", "80", "value 80 is out of bounds, min is 1024"},
+		{"oneof", "TEST_MODE", "test", `value "test" is not one of dev/staging/prod`},
+		{"len", "TEST_TOKEN", "short", "length 5 is out of bounds, min is 32"},
+		{"len-range", "TEST_HOSTS", "a;b;c;d", "length 4 is out of bounds, max is 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			_ = os.Setenv(tt.key, tt.env)
+
+			var cfg limits
+			err := Parse("test", &cfg)
+
+			var fe *FieldError
+			if !errors.As(err, &fe) || fe.envKey != tt.key || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("\t%s\tShould fail with field error %q : %v.", failed, tt.want, err)
+			}
+			t.Logf("\t%s\tShould fail with field error : %s.", success, err)
+		})
+	}
+}
+
+// stalled provides support for testing a custom value whose conversion hangs.
+type stalled struct {
+	value string
+}
+
+// Set implements the Setter interface
+func (s *stalled) Set(data string) error {
+	time.Sleep(200 * time.Millisecond)
+	s.value = data
+	return nil
+}
+
+func TestParseContext(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("TEST_SLOW", "value")
+
+	var cfg struct {
+		Fast string `conf:"default:fast"`
+		Slow stalled
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := ParseContext(ctx, "test", &cfg)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("\t%s\tShould abort when the deadline is exceeded : %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould abort when the deadline is exceeded.", success)
+
+	if !strings.Contains(err.Error(), "TEST_SLOW") {
+		t.Fatalf("\t%s\tShould identify the stalled field in error : %s.", failed, err)
+	}
+	t.Logf("\t%s\tShould identify the stalled field in error : %s.", success, err)
+
+	time.Sleep(300 * time.Millisecond)
+	if cfg.Slow.value != "" {
+		t.Fatalf("\t%s\tShould leave the field untouched by the stalled conversion : %+v.", failed, cfg)
+	}
+	t.Logf("\t%s\tShould leave the field untouched by the stalled conversion.", success)
+}
+
+func TestParseWithArgs(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("TEST_PORT", "9090")
+
+	var cfg struct {
+		Host  string `conf:"default:localhost"`
+		Port  int    `conf:"default:8080"`
+		Debug bool   `conf:"short:d"`
+	}
+
+	if err := ParseWithArgs("test", &cfg, []string{"--host", "example.com", "-d"}); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse with args : %s.", failed, err)
+	}
+
+	if cfg.Host != "example.com" || cfg.Port != 9090 || !cfg.Debug {
+		t.Fatalf("\t%s\tShould read the flags from the args : %+v.", failed, cfg)
+	}
+	t.Logf("\t%s\tShould read the flags from the args.", success)
+
+	if err := ParseWithArgs("test", &cfg, []string{"--port=7070"}); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse with args : %s.", failed, err)
+	}
+
+	if cfg.Port != 7070 {
+		t.Fatalf("\t%s\tShould prefer the flags over the environment : %d.", failed, cfg.Port)
+	}
+	t.Logf("\t%s\tShould prefer the flags over the environment.", success)
+}
+
+// panicky provides support for testing a custom value which panics.
+type panicky struct{}
+
+// Set implements the Setter interface
+func (p *panicky) Set(data string) error {
+	panic("boom")
+}
+
+func TestParse_Panic(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("TEST_INNER_VALUE", "value")
+
+	var cfg struct {
+		Inner struct {
+			Value panicky
+		}
+	}
+
+	err := Parse("test", &cfg)
+
+	var pe *PanicError
+	if !errors.As(err, &pe) {
+		t.Fatalf("\t%s\tShould convert the panic into an error : %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould convert the panic into an error : %s.", success, err)
+
+	if pe.Value != "boom" || len(pe.Stack) == 0 {
+		t.Fatalf("\t%s\tShould carry the panic value and stack : %v.", failed, pe.Value)
+	}
+	t.Logf("\t%s\tShould carry the panic value and stack.", success)
+
+	if !strings.Contains(err.Error(), "Inner.Value") {
+		t.Fatalf("\t%s\tShould identify the field path in error : %s.", failed, err)
+	}
+	t.Logf("\t%s\tShould identify the field path in error.", success)
+}
+
+func TestParse_DefaultFile(t *testing.T) {
+	type banner struct {
+		Banner string `conf:"defaultfile:testdata/banner.txt"`
+	}
+
+	t.Run("default-file", func(t *testing.T) {
+		os.Clearenv()
+
+		var cfg banner
+		if err := Parse("test", &cfg); err != nil {
+			t.Fatalf("\t%s\tShould be able to parse default from file : %s.", failed, err)
+		}
+
+		if cfg.Banner != "Welcome to the service!\n" {
+			t.Fatalf("\t%s\tShould have loaded default from file : %q.", failed, cfg.Banner)
+		}
+		t.Logf("\t%s\tShould have loaded default from file.", success)
+	})
+
+	t.Run("default-file-overridden", func(t *testing.T) {
+		os.Clearenv()
+		_ = os.Setenv("TEST_BANNER", "Hi!")
+
+		var cfg banner
+		if err := Parse("test", &cfg); err != nil {
+			t.Fatalf("\t%s\tShould be able to parse env variables : %s.", failed, err)
+		}
+
+		if cfg.Banner != "Hi!" {
+			t.Fatalf("\t%s\tShould have taken value from env variable : %q.", failed, cfg.Banner)
+		}
+		t.Logf("\t%s\tShould have taken value from env variable.", success)
+	})
+
+	t.Run("default-file-missing", func(t *testing.T) {
+		os.Clearenv()
+
+		var cfg struct {
+			Banner string `conf:"defaultfile:testdata/missing.txt"`
+		}
+		if err := Parse("test", &cfg); err == nil || !strings.Contains(err.Error(), "TEST_BANNER") {
+			t.Fatalf("\t%s\tShould fail for missing default file : %v.", failed, err)
+		}
+		t.Logf("\t%s\tShould fail for missing default file.", success)
+	})
+}
+
+func TestParseFor(t *testing.T) {
+	os.Clearenv()
+
+	type server struct {
+		Host string `conf:"required"`
+		Port int    `conf:"default:8080"`
+	}
+
+	cfg, err := ParseFor[server]("test", WithEnviron(map[string]string{"TEST_HOST": "localhost"}), WithArgs(nil))
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to parse into a new value : %s.", failed, err)
+	}
+
+	if cfg != (server{Host: "localhost", Port: 8080}) {
+		t.Fatalf("\t%s\tShould return the parsed value : %+v.", failed, cfg)
+	}
+	t.Logf("\t%s\tShould return the parsed value.", success)
+
+	cfg, err = ParseFor[server]("test", WithEnviron(map[string]string{"TEST_PORT": "80"}), WithArgs(nil))
+	if err == nil || cfg != (server{}) {
+		t.Fatalf("\t%s\tShould return the zero value with the error : %+v, %v.", failed, cfg, err)
+	}
+	t.Logf("\t%s\tShould return the zero value with the error.", success)
+}
+
+//go:embed testdata/banner.txt
+var defaultsFS embed.FS
+
+func TestParse_DefaultEmbed(t *testing.T) {
+	type banner struct {
+		Banner string `conf:"default:embed:testdata/banner.txt"`
+	}
+
+	t.Run("default-embed", func(t *testing.T) {
+		os.Clearenv()
+
+		var cfg banner
+		if err := Parse("test", &cfg, WithDefaultsFS(defaultsFS), WithArgs(nil)); err != nil {
+			t.Fatalf("\t%s\tShould be able to parse embedded default : %s.", failed, err)
+		}
+
+		if cfg.Banner != "Welcome to the service!\n" {
+			t.Fatalf("\t%s\tShould have loaded embedded default : %q.", failed, cfg.Banner)
+		}
+		t.Logf("\t%s\tShould have loaded embedded default.", success)
+	})
+
+	t.Run("default-embed-overridden", func(t *testing.T) {
+		os.Clearenv()
+
+		var cfg struct {
+			Banner string `conf:"default:embed:testdata/missing.txt"`
+		}
+		env := map[string]string{"TEST_BANNER": "Hi!"}
+		if err := Parse("test", &cfg, WithDefaultsFS(defaultsFS), WithEnviron(env), WithArgs(nil)); err != nil {
+			t.Fatalf("\t%s\tShould not read embedded default when overridden : %s.", failed, err)
+		}
+
+		if cfg.Banner != "Hi!" {
+			t.Fatalf("\t%s\tShould have taken value from env variable : %q.", failed, cfg.Banner)
+		}
+		t.Logf("\t%s\tShould not read embedded default when overridden.", success)
+	})
+
+	t.Run("default-embed-missing", func(t *testing.T) {
+		os.Clearenv()
+
+		var cfg struct {
+			Banner string `conf:"default:embed:testdata/missing.txt"`
+		}
+		if err := Parse("test", &cfg, WithDefaultsFS(defaultsFS), WithArgs(nil)); err == nil || !strings.Contains(err.Error(), "TEST_BANNER") {
+			t.Fatalf("\t%s\tShould fail for missing embedded default : %v.", failed, err)
+		}
+		t.Logf("\t%s\tShould fail for missing embedded default.", success)
+	})
+
+	t.Run("default-embed-without-fs", func(t *testing.T) {
+		os.Clearenv()
+
+		var cfg banner
+		env := map[string]string{"TEST_BANNER": "Hi!"}
+		if err := Parse("test", &cfg, WithEnviron(env), WithArgs(nil)); err == nil || !strings.Contains(err.Error(), "WithDefaultsFS") {
+			t.Fatalf("\t%s\tShould fail without a file system : %v.", failed, err)
+		}
+		t.Logf("\t%s\tShould fail without a file system.", success)
+	})
+}
+
+// pool provides support for testing defaults constructed in code.
+type pool struct {
+	Size    int `conf:"default:5"`
+	Timeout time.Duration
+	Hosts   []string
+}
+
+// Defaults implements the Defaulter interface
+func (p *pool) Defaults() {
+	p.Timeout = 3 * time.Second
+	p.Hosts = []string{"a", "b"}
+}
+
+// service provides support for testing defaults of the top-level struct.
+type service struct {
+	Name string
+	Pool pool
+	Ptr  *pool
+}
+
+// Defaults implements the Defaulter interface
+func (s *service) Defaults() {
+	s.Name = "service"
+	s.Ptr.Size = 10
+}
+
+func TestParse_Defaulter(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("TEST_POOL_HOSTS", "c")
+
+	var cfg service
+	if err := Parse("test", &cfg); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse struct with defaults in code : %s.", failed, err)
+	}
+	t.Logf("\t%s\tShould be able to parse struct with defaults in code.", success)
+
+	want := service{
+		Name: "service",
+		Pool: pool{Size: 5, Timeout: 3 * time.Second, Hosts: []string{"c"}},
+		Ptr:  &pool{Size: 10, Timeout: 3 * time.Second, Hosts: []string{"a", "b"}},
+	}
+	if diff := cmp.Diff(want, cfg); diff != "" {
+		t.Fatalf("\t%s\tShould have applied defaults in code before sources\n%s", failed, diff)
+	}
+	t.Logf("\t%s\tShould have applied defaults in code before sources.", success)
+}
+
+type (
+	Primary struct {
+		Host string
+	}
+	Replica struct {
+		Host string
+	}
+)
+
+func TestParse_KeyCollision(t *testing.T) {
+	t.Run("embedded-collision", func(t *testing.T) {
+		os.Clearenv()
+
+		var cfg struct {
+			Primary
+			Replica
+		}
+
+		err := Parse("test", &cfg)
+		if err == nil {
+			t.Fatalf("\t%s\tShould fail for embedded structs sharing a key.", failed)
+		}
+
+		for _, want := range []string{"Primary.Host", "Replica.Host", "TEST_HOST", "prefix tag"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Fatalf("\t%s\tShould describe the collision in error, missing %q : %s.", failed, want, err)
+			}
+		}
+		t.Logf("\t%s\tShould describe the collision in error : %s.", success, err)
+	})
+
+	t.Run("embedded-prefix", func(t *testing.T) {
+		os.Clearenv()
+		_ = os.Setenv("TEST_HOST", "primary")
+		_ = os.Setenv("TEST_REPLICA_HOST", "replica")
+
+		var cfg struct {
+			Primary
+			Replica `conf:"prefix:replica"`
+		}
+
+		if err := Parse("test", &cfg, WithArgs([]string{"--replica-host=flag"})); err != nil {
+			t.Fatalf("\t%s\tShould be able to parse embedded structs with prefix : %s.", failed, err)
+		}
+
+		if cfg.Primary.Host != "primary" || cfg.Replica.Host != "flag" {
+			t.Fatalf("\t%s\tShould have used the prefix for keys : %+v.", failed, cfg)
+		}
+		t.Logf("\t%s\tShould have used the prefix for keys.", success)
+	})
+}
+
+// tlsConfig provides support for testing a nested struct validating its fields.
+type tlsConfig struct {
+	Cert string
+	Key  string
+}
+
+// Validate implements the Validator interface
+func (c *tlsConfig) Validate() error {
+	if c.Cert != "" && c.Key == "" {
+		return errors.New("cert requires key")
+	}
+	return nil
+}
+
+// server provides support for testing a top-level struct validating its fields.
+type server struct {
+	Port int
+	TLS  tlsConfig
+}
+
+// Validate implements the Validator interface
+func (s *server) Validate() error {
+	if s.TLS.Cert != "" && s.Port == 80 {
+		return errors.New("tls can't be served on port 80")
+	}
+	return nil
+}
+
+func TestParse_Validator(t *testing.T) {
+	tests := []struct {
+		name string
+		envs map[string]string
+		want string
+	}{
+		{"valid", map[string]string{"TEST_PORT": "443", "TEST_TLS_CERT": "c", "TEST_TLS_KEY": "k"}, ""},
+		{"nested", map[string]string{"TEST_PORT": "443", "TEST_TLS_CERT": "c"}, "validate TLS: cert requires key"},
+		{"top-level", map[string]string{"TEST_PORT": "80", "TEST_TLS_CERT": "c", "TEST_TLS_KEY": "k"}, "validate config: tls can't be served on port 80"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			for k, v := range tt.envs {
+				_ = os.Setenv(k, v)
+			}
+
+			var cfg server
+			err := Parse("test", &cfg)
+
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("\t%s\tShould pass validation : %s.", failed, err)
+				}
+				t.Logf("\t%s\tShould pass validation.", success)
+				return
+			}
+
+			if err == nil || err.Error() != tt.want {
+				t.Fatalf("\t%s\tShould fail validation with %q : %v.", failed, tt.want, err)
+			}
+			t.Logf("\t%s\tShould fail validation : %s.", success, err)
+		})
+	}
+}
+
+func TestFields(t *testing.T) {
+	cfg := struct {
+		Port     int      `conf:"default:8080,help:port to listen on"`
+		Password string   `conf:"mask"`
+		Hosts    []string `conf:"sep:comma"`
+		DB       struct {
+			Name string `conf:"required"`
+		}
+	}{
+		Port:     9090,
+		Password: "secret",
+		Hosts:    []string{"a", "b"},
+	}
+	cfg.DB.Name = "users"
+
+	fields, err := Fields("test", &cfg)
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to list fields : %s.", failed, err)
+	}
+	t.Logf("\t%s\tShould be able to list fields.", success)
+
+	type info struct {
+		EnvKey, FlagKey, Default, Help, Value string
+		Required                              bool
+	}
+
+	var got []info
+	for _, f := range fields {
+		got = append(got, info{f.EnvKey, f.FlagKey, f.Options.DefaultVal, f.Options.Help, f.Value(), f.Options.Required})
+	}
+
+	want := []info{
+		{"TEST_PORT", "port", "8080", "port to listen on", "9090", false},
+		{"TEST_PASSWORD", "password", "", "", "xxxxxx", false},
+		{"TEST_HOSTS", "hosts", "", "", "a,b", false},
+		{"TEST_DB_NAME", "db-name", "", "", "users", true},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("\t%s\tShould resolve the metadata of the fields :\n%s", failed, diff)
+	}
+	t.Logf("\t%s\tShould resolve the metadata of the fields.", success)
+}
+
+func TestExtractFields_Plan(t *testing.T) {
+	type inner struct {
+		Host string
+	}
+
+	type config struct {
+		Port  int
+		Inner *inner
+	}
+
+	var a, b config
+
+	if _, err := extractFields("plan", "_", nil, nil, &a); err != nil {
+		t.Fatalf("\t%s\tShould be able to extract the fields : %s.", failed, err)
+	}
+
+	fields, err := extractFields("plan", "_", nil, nil, &b)
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to extract the fields : %s.", failed, err)
+	}
+
+	fields[0].Field.SetInt(80)
+	fields[1].Field.SetString("localhost")
+
+	if a.Port != 0 || b.Port != 80 || b.Inner == nil || b.Inner.Host != "localhost" || a.Inner == b.Inner {
+		t.Fatalf("\t%s\tShould bind the cached fields to the target : %+v, %+v.", failed, a, b)
+	}
+	t.Logf("\t%s\tShould bind the cached fields to the target.", success)
+
+	plan, ok := fieldPlans.Load(planKey{typ: reflect.TypeOf(&b), sep: "_"})
+	if !ok || plan.([]Field)[0].Field.IsValid() {
+		t.Fatalf("\t%s\tShould cache the fields without the values.", failed)
+	}
+	t.Logf("\t%s\tShould cache the fields without the values.", success)
+
+	if fields[0].EnvKey != "PLAN_PORT" || fields[1].EnvKey != "PLAN_INNER_HOST" {
+		t.Fatalf("\t%s\tShould apply the prefix to the cached keys : %s, %s.", failed, fields[0].EnvKey, fields[1].EnvKey)
+	}
+
+	other, err := extractFields("other", "_", nil, nil, &b)
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to extract the fields : %s.", failed, err)
+	}
+
+	var plans int
+	fieldPlans.Range(func(key, _ any) bool {
+		if key.(planKey).typ == reflect.TypeOf(&b) {
+			plans++
+		}
+		return true
+	})
+
+	if other[0].EnvKey != "OTHER_PORT" || plans != 1 {
+		t.Fatalf("\t%s\tShould share the plan between prefixes : %s, %d plans.", failed, other[0].EnvKey, plans)
+	}
+	t.Logf("\t%s\tShould share the plan between prefixes.", success)
+}
+
+func BenchmarkParse(b *testing.B) {
+	env := map[string]string{"TEST_AN_INT": "1", "TEST_A_STRING": "s", "TEST_IP_NAME": "n"}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var cfg config
+		if err := Parse("test", &cfg, WithEnviron(env), WithArgs(nil)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
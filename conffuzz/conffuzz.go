@@ -0,0 +1,108 @@
+// Package conffuzz provides the fuzz targets of the conf package as helpers,
+// so they can be run by fuzzing infrastructure such as OSS-Fuzz and by the
+// fuzz tests of custom types:
+//
+//	func FuzzParseTag(f *testing.F) {
+//		f.Fuzz(conffuzz.ParseTag)
+//	}
+package conffuzz
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/virp/conf"
+)
+
+// key is the env key of the field the helpers parse.
+const key = "FUZZ_VALUE"
+
+// ParseTag parses the conf struct tag of a field and fails the test if an
+// accepted tag sets contradicting options, such as both required and a
+// default.
+func ParseTag(t *testing.T, tag string) {
+	cfg := newStruct(reflect.TypeOf(""), `conf:`+strconv.Quote(tag))
+
+	fields, err := conf.Fields("fuzz", cfg.Interface())
+	if err != nil || len(fields) == 0 {
+		return
+	}
+
+	opts := fields[0].Options
+	if opts.Required && opts.DefaultVal != "" {
+		t.Fatalf("accepted both required and default in %q", tag)
+	}
+}
+
+// ProcessField converts the value into a field of the type target points
+// to, such as new(time.Duration), and fails the test if the conversion
+// panics or the value it's rendered as doesn't convert back into the same
+// value.
+func ProcessField(t *testing.T, target any, value string) {
+	typ := reflect.TypeOf(target).Elem()
+
+	cfg := newStruct(typ, "")
+	if err := parse(cfg, value); err != nil {
+		var pe *conf.PanicError
+		if errors.As(err, &pe) {
+			t.Fatalf("converting %q to %s panicked: %s", value, typ, err)
+		}
+		return
+	}
+
+	formatted, ok := render(t, cfg)
+	if !ok {
+		return
+	}
+
+	again := newStruct(typ, "")
+	if err := parse(again, formatted); err != nil {
+		t.Fatalf("converting %q rendered from %q to %s failed: %s", formatted, value, typ, err)
+	}
+
+	if rendered, _ := render(t, again); rendered != formatted {
+		t.Fatalf("converting %q to %s doesn't round trip through %q", value, typ, formatted)
+	}
+}
+
+// newStruct returns a pointer to a new struct holding a single field of the
+// type with the tag.
+func newStruct(typ reflect.Type, tag string) reflect.Value {
+	st := reflect.StructOf([]reflect.StructField{{
+		Name: "Value",
+		Type: typ,
+		Tag:  reflect.StructTag(tag),
+	}})
+
+	return reflect.New(st)
+}
+
+// parse sets the value into the field of the struct, consulting neither
+// the environment nor the command line.
+func parse(cfg reflect.Value, value string) error {
+	return conf.Parse("fuzz", cfg.Interface(),
+		conf.WithEnviron(map[string]string{}),
+		conf.WithValues(map[string]string{key: value}),
+		conf.WithArgs(nil),
+	)
+}
+
+// render returns the value the field of the struct is rendered as, false if
+// it's left out as it holds no value.
+func render(t *testing.T, cfg reflect.Value) (string, bool) {
+	env, err := conf.Environ("fuzz", cfg.Interface())
+	if err != nil {
+		t.Fatalf("rendering %s failed: %s", cfg.Elem().Field(0).Type(), err)
+	}
+
+	for _, kv := range env {
+		if value, ok := strings.CutPrefix(kv, key+"="); ok {
+			return value, true
+		}
+	}
+
+	return "", false
+}
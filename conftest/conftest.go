@@ -0,0 +1,76 @@
+// Package conftest provides helpers for testing code which parses
+// configuration from the environment.
+package conftest
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// env serializes the tests changing the environment. The test holding it
+// and its subtests may change the environment, other tests wait until the
+// holder finishes.
+var env = struct {
+	sync.Mutex
+	cond   *sync.Cond
+	holder string
+	depth  int
+}{}
+
+func init() {
+	env.cond = sync.NewCond(&env.Mutex)
+}
+
+// Setenv sets the environment variables for the duration of the test and
+// restores their previous values once it finishes. Tests calling Setenv
+// run one at a time, even when they are parallel, so they never see the
+// variables of each other.
+func Setenv(t testing.TB, vars map[string]string) {
+	t.Helper()
+
+	acquire(t)
+
+	for key, value := range vars {
+		prev, ok := os.LookupEnv(key)
+		if err := os.Setenv(key, value); err != nil {
+			t.Fatalf("set env %s: %s", key, err)
+		}
+
+		t.Cleanup(func() {
+			if ok {
+				_ = os.Setenv(key, prev)
+			} else {
+				_ = os.Unsetenv(key)
+			}
+		})
+	}
+}
+
+// acquire waits until the test may change the environment
+// and releases it once the test finishes.
+func acquire(t testing.TB) {
+	name := t.Name()
+
+	env.Lock()
+	for env.holder != "" && env.holder != name && !strings.HasPrefix(name, env.holder+"/") {
+		env.cond.Wait()
+	}
+	if env.holder == "" {
+		env.holder = name
+	}
+	env.depth++
+	env.Unlock()
+
+	t.Cleanup(func() {
+		env.Lock()
+		defer env.Unlock()
+
+		env.depth--
+		if env.depth == 0 {
+			env.holder = ""
+			env.cond.Broadcast()
+		}
+	})
+}
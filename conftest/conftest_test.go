@@ -0,0 +1,80 @@
+package conftest
+
+import (
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/virp/conf"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func TestSetenv(t *testing.T) {
+	_ = os.Setenv("TEST_PORT", "1")
+	defer os.Unsetenv("TEST_PORT")
+
+	t.Run("scoped", func(t *testing.T) {
+		Setenv(t, map[string]string{"TEST_PORT": "8080", "TEST_HOST": "localhost"})
+
+		var cfg struct {
+			Port int
+			Host string
+		}
+		if err := conf.Parse("test", &cfg, conf.WithArgs(nil)); err != nil || cfg.Port != 8080 || cfg.Host != "localhost" {
+			t.Fatalf("\t%s\tShould parse the variables set : %+v : %v.", failed, cfg, err)
+		}
+		t.Logf("\t%s\tShould parse the variables set.", success)
+
+		t.Run("nested", func(t *testing.T) {
+			Setenv(t, map[string]string{"TEST_HOST": "nested"})
+
+			if got := os.Getenv("TEST_HOST"); got != "nested" {
+				t.Fatalf("\t%s\tShould set the variables in subtests, got %q.", failed, got)
+			}
+			t.Logf("\t%s\tShould set the variables in subtests.", success)
+		})
+
+		if got := os.Getenv("TEST_HOST"); got != "localhost" {
+			t.Fatalf("\t%s\tShould restore the variables of the subtest, got %q.", failed, got)
+		}
+	})
+
+	if got, ok := os.LookupEnv("TEST_HOST"); ok || os.Getenv("TEST_PORT") != "1" {
+		t.Fatalf("\t%s\tShould restore the environment, got TEST_HOST %q TEST_PORT %q.", failed, got, os.Getenv("TEST_PORT"))
+	}
+	t.Logf("\t%s\tShould restore the environment.", success)
+}
+
+func TestSetenv_Parallel(t *testing.T) {
+	var running, overlaps atomic.Int32
+
+	t.Run("group", func(t *testing.T) {
+		for _, value := range []string{"a", "b", "c", "d"} {
+			value := value
+			t.Run(value, func(t *testing.T) {
+				t.Parallel()
+
+				Setenv(t, map[string]string{"TEST_VALUE": value})
+
+				if running.Add(1) > 1 {
+					overlaps.Add(1)
+				}
+				time.Sleep(10 * time.Millisecond)
+				if got := os.Getenv("TEST_VALUE"); got != value {
+					overlaps.Add(1)
+				}
+				running.Add(-1)
+			})
+		}
+	})
+
+	if overlaps.Load() != 0 {
+		t.Fatalf("\t%s\tShould run the parallel tests one at a time, %d overlaps.", failed, overlaps.Load())
+	}
+	t.Logf("\t%s\tShould run the parallel tests one at a time.", success)
+}
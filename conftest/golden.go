@@ -0,0 +1,57 @@
+package conftest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/virp/conf"
+)
+
+// Update makes Golden write the output into the golden files instead of
+// comparing it. It's set when the CONFTEST_UPDATE environment variable is,
+// such as with CONFTEST_UPDATE=1 go test ./..., and can be bound to a flag
+// of the test binary by packages which want one.
+var Update = os.Getenv("CONFTEST_UPDATE") != ""
+
+// Golden compares the output with the content of the golden file at path,
+// failing the test with their difference. Run the tests with
+// CONFTEST_UPDATE=1 to write the output into the golden file instead.
+func Golden(t testing.TB, path string, got string) {
+	t.Helper()
+
+	if Update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("create golden file directory: %s", err)
+		}
+
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("write golden file: %s", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file, run the tests with CONFTEST_UPDATE=1 to create it: %s", err)
+	}
+
+	if diff := cmp.Diff(string(want), got); diff != "" {
+		t.Fatalf("output differs from golden file %s, run the tests with CONFTEST_UPDATE=1 if the change is intended (-want +got):\n%s", path, diff)
+	}
+}
+
+// GoldenUsage compares the usage of the config struct rendered by
+// conf.Usage with the golden file at path, guarding the flags, env
+// variables, types and defaults against accidental changes.
+func GoldenUsage(t testing.TB, path, prefix string, cfg any, opts ...conf.Option) {
+	t.Helper()
+
+	usage, err := conf.Usage(prefix, cfg, opts...)
+	if err != nil {
+		t.Fatalf("render usage: %s", err)
+	}
+
+	Golden(t, path, usage)
+}
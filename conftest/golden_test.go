@@ -0,0 +1,65 @@
+package conftest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type service struct {
+	Port    int           `conf:"default:8080,help:port to listen on"`
+	Timeout time.Duration `conf:"default:5s"`
+	Token   string        `conf:"required,help:API token"`
+}
+
+func TestGoldenUsage(t *testing.T) {
+	GoldenUsage(t, filepath.Join("testdata", "usage.golden"), "test", &service{})
+	t.Logf("\t%s\tShould match the golden usage.", success)
+}
+
+func TestGolden_Mismatch(t *testing.T) {
+	defer func(update bool) { Update = update }(Update)
+	Update = false
+
+	path := filepath.Join(t.TempDir(), "out.golden")
+	_ = os.WriteFile(path, []byte("want\n"), 0o644)
+
+	ft := &fakeT{TB: t}
+	func() {
+		defer func() { _ = recover() }()
+		Golden(ft, path, "got\n")
+	}()
+
+	if !ft.failed {
+		t.Fatalf("\t%s\tShould fail when the output differs from the golden file.", failed)
+	}
+	t.Logf("\t%s\tShould fail when the output differs from the golden file.", success)
+}
+
+// fakeT provides support for testing helpers failing the test.
+type fakeT struct {
+	testing.TB
+	failed bool
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Fatalf(format string, args ...any) {
+	t.failed = true
+	panic("fatal")
+}
+
+func TestGolden_Update(t *testing.T) {
+	defer func(update bool) { Update = update }(Update)
+	Update = true
+
+	path := filepath.Join(t.TempDir(), "testdata", "out.golden")
+	Golden(t, path, "got\n")
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "got\n" {
+		t.Fatalf("\t%s\tShould write the output into the golden file with Update set : %q, %v.", failed, data, err)
+	}
+	t.Logf("\t%s\tShould write the output into the golden file with Update set.", success)
+}
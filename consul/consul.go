@@ -0,0 +1,177 @@
+// Package consul provides a source reading configuration values from
+// the Consul KV store.
+package consul
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/virp/conf"
+)
+
+// An Option configures the Source.
+type Option func(*Source)
+
+// WithToken sets the ACL token of the requests to Consul.
+func WithToken(token string) Option {
+	return func(s *Source) {
+		s.token = token
+	}
+}
+
+// WithDatacenter sets the datacenter to read the keys from,
+// the datacenter of the agent by default.
+func WithDatacenter(dc string) Option {
+	return func(s *Source) {
+		s.dc = dc
+	}
+}
+
+// WithHTTPClient sets the client of the requests to Consul,
+// http.DefaultClient by default.
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Source) {
+		s.client = client
+	}
+}
+
+// Source provides the values of the keys under a prefix of the Consul KV
+// store. The key path below the prefix maps to the field path, matched
+// ignoring case, underscores and dashes, so with the prefix my-service
+// the key my-service/ip/debug_host provides the field IP.DebugHost.
+//
+// The keys are read on every parse, the values of the last successful
+// read keep being served when Consul can't be reached.
+type Source struct {
+	addr   string
+	prefix string
+	client *http.Client
+	token  string
+	dc     string
+
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+// New constructs the source reading the keys under the prefix from the
+// Consul agent at addr, for example New("http://localhost:8500", "my-service").
+func New(addr, prefix string, opts ...Option) *Source {
+	s := Source{
+		addr:   strings.TrimRight(addr, "/"),
+		prefix: strings.Trim(prefix, "/"),
+		client: http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	return &s
+}
+
+// Source implements the conf.Sourcer interface.
+func (s *Source) Source(fld conf.Field) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	value, ok := s.values[fieldKey(fld.Path)]
+	return value, ok
+}
+
+// Load implements the conf.Loader interface.
+func (s *Source) Load() error {
+	return s.LoadContext(context.Background())
+}
+
+// LoadContext implements the conf.ContextLoader interface.
+func (s *Source) LoadContext(ctx context.Context) error {
+	query := url.Values{"recurse": {"true"}}
+	if s.dc != "" {
+		query.Set("dc", s.dc)
+	}
+
+	// Read the folder of the prefix, not the keys merely starting with it.
+	folder := s.prefix
+	if folder != "" {
+		folder += "/"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.addr+"/v1/kv/"+folder+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	if s.token != "" {
+		req.Header.Set("X-Consul-Token", s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var pairs []struct {
+		Key   string
+		Value []byte
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if err := json.NewDecoder(resp.Body).Decode(&pairs); err != nil {
+			return fmt.Errorf("decode keys: %w", err)
+		}
+	case http.StatusNotFound:
+		// The store has no keys under the prefix.
+	default:
+		return fmt.Errorf("consul responded %s", resp.Status)
+	}
+
+	values := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key := strings.TrimPrefix(pair.Key, folder)
+
+		// Folders have no values.
+		if key == "" || strings.HasSuffix(key, "/") {
+			continue
+		}
+
+		values[fieldKey(strings.Split(key, "/"))] = string(pair.Value)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values = values
+
+	return nil
+}
+
+// Cached implements the conf.Cacher interface.
+func (s *Source) Cached() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.values != nil
+}
+
+func (s *Source) String() string {
+	return "consul " + s.prefix
+}
+
+// fieldKey makes the path comparable ignoring case, underscores and dashes.
+func fieldKey(path []string) string {
+	parts := make([]string, len(path))
+	for i, name := range path {
+		name = strings.ReplaceAll(name, "_", "")
+		name = strings.ReplaceAll(name, "-", "")
+		parts[i] = strings.ToLower(name)
+	}
+
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,147 @@
+package consul
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/virp/conf"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+// fakeConsul provides support for testing against the Consul KV HTTP API.
+type fakeConsul struct {
+	mu   sync.Mutex
+	kv   map[string]string
+	down bool
+}
+
+func (c *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.down {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if r.Header.Get("X-Consul-Token") != "token" || r.URL.Query().Get("recurse") != "true" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	type pair struct {
+		Key   string
+		Value []byte
+	}
+
+	prefix := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+
+	var pairs []pair
+	for k, v := range c.kv {
+		if strings.HasPrefix(k, prefix) {
+			pairs = append(pairs, pair{k, []byte(v)})
+		}
+	}
+
+	if len(pairs) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(pairs)
+}
+
+type config struct {
+	Port int
+	IP   struct {
+		Name      string
+		DebugHost string
+	}
+}
+
+func TestSource(t *testing.T) {
+	os.Clearenv()
+
+	fake := &fakeConsul{kv: map[string]string{
+		"my-service/":              "",
+		"my-service/port":          "8080",
+		"my-service/ip/name":       "consul",
+		"my-service/ip/debug_host": "http://debug",
+		"my-service-old/port":      "9090",
+	}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	src := New(srv.URL, "my-service", WithToken("token"))
+
+	var cfg config
+	if err := conf.Parse("test", &cfg, conf.WithSources(src), conf.WithArgs(nil)); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse from consul : %s.", failed, err)
+	}
+
+	if cfg.Port != 8080 || cfg.IP.Name != "consul" || cfg.IP.DebugHost != "http://debug" {
+		t.Fatalf("\t%s\tShould have mapped keys under the prefix to fields : %+v.", failed, cfg)
+	}
+	t.Logf("\t%s\tShould have mapped keys under the prefix to fields.", success)
+
+	fake.mu.Lock()
+	fake.down = true
+	fake.mu.Unlock()
+
+	cfg = config{}
+	if err := conf.Parse("test", &cfg, conf.WithSources(src), conf.WithArgs(nil)); err != nil || cfg.Port != 8080 {
+		t.Fatalf("\t%s\tShould keep serving the last keys when consul is down : %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould keep serving the last keys when consul is down.", success)
+
+	cfg = config{}
+	err := conf.Parse("test", &cfg, conf.WithSources(New(srv.URL, "my-service", WithToken("token"))), conf.WithArgs(nil))
+	if err == nil {
+		t.Fatalf("\t%s\tShould fail when consul is down without cached keys.", failed)
+	}
+	t.Logf("\t%s\tShould fail when consul is down without cached keys : %s.", success, err)
+}
+
+func TestSource_Empty(t *testing.T) {
+	os.Clearenv()
+
+	srv := httptest.NewServer(&fakeConsul{kv: map[string]string{}})
+	defer srv.Close()
+
+	var cfg config
+	if err := conf.Parse("test", &cfg, conf.WithSources(New(srv.URL, "my-service", WithToken("token"))), conf.WithArgs(nil)); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse without keys under the prefix : %s.", failed, err)
+	}
+	t.Logf("\t%s\tShould be able to parse without keys under the prefix.", success)
+}
+
+func TestSource_Context(t *testing.T) {
+	os.Clearenv()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var cfg config
+	err := conf.ParseContext(ctx, "test", &cfg, conf.WithSources(New(srv.URL, "my-service")), conf.WithArgs(nil))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("\t%s\tShould abort the request to consul once the context is done : %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould abort the request to consul once the context is done.", success)
+}
@@ -0,0 +1,206 @@
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// A Diagnosis reports in one pass what is wrong with the configuration of
+// a config struct in the environment, see Doctor. It is rendered for humans
+// by String and marshals to JSON for tools.
+type Diagnosis struct {
+	Prefix string `json:"prefix"`
+
+	// Expected are the env keys of the fields.
+	Expected []string `json:"expected"`
+
+	// Found are the names of the sources of the values found, keyed by
+	// the field env key.
+	Found map[string]string `json:"found"`
+
+	// Missing are the required fields without a value.
+	Missing []Warning `json:"missing,omitempty"`
+
+	// Invalid are the values which don't convert to the type of their
+	// field or fail its validation.
+	Invalid []Warning `json:"invalid,omitempty"`
+
+	// Unknown are the variables under the prefix not belonging to any field.
+	Unknown []Warning `json:"unknown,omitempty"`
+
+	// Warnings are the settings accepted but needing attention, such as
+	// fields due for removal.
+	Warnings []Warning `json:"warnings,omitempty"`
+
+	// SuggestedPrefix is the prefix the variables of the struct were found
+	// under when none were found under the prefix, see SuggestPrefix.
+	SuggestedPrefix string `json:"suggested_prefix,omitempty"`
+}
+
+// OK reports whether the config struct would be parsed without errors.
+func (d *Diagnosis) OK() bool {
+	return len(d.Missing) == 0 && len(d.Invalid) == 0
+}
+
+// String renders the diagnosis with one problem per line.
+func (d *Diagnosis) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Variables found: %d of %d expected\n", len(d.Found), len(d.Expected))
+
+	keys := make([]string, 0, len(d.Found))
+	for key := range d.Found {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fmt.Fprintf(&b, "  %s (%s)\n", key, d.Found[key])
+	}
+
+	if d.SuggestedPrefix != "" {
+		fmt.Fprintf(&b, "No variables found under the prefix %s, did you mean the prefix %s?\n", strings.ToUpper(d.Prefix), d.SuggestedPrefix)
+	}
+
+	sections := []struct {
+		title    string
+		warnings []Warning
+	}{
+		{"Missing required variables", d.Missing},
+		{"Invalid values", d.Invalid},
+		{"Unknown variables", d.Unknown},
+		{"Warnings", d.Warnings},
+	}
+
+	for _, s := range sections {
+		if len(s.warnings) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, "%s:\n", s.title)
+		for _, w := range s.warnings {
+			fmt.Fprintf(&b, "  %s\n", w)
+		}
+	}
+
+	if d.OK() {
+		b.WriteString("The configuration is valid.\n")
+	}
+
+	return b.String()
+}
+
+// Doctor diagnoses the configuration of the specified config struct found by
+// the sources without stopping at the first problem, e.g. for a doctor
+// command of the program. It reports the variables found and expected, the
+// required fields missing a value, the values not converting to their
+// fields, the variables under the prefix not belonging to any field and the
+// use of deprecated and expired fields. The values are converted into a
+// copy of the config struct, cfg is left untouched.
+func Doctor(prefix string, cfg any, opts ...Option) (*Diagnosis, error) {
+	typ := reflect.TypeOf(cfg)
+	if typ == nil || typ.Kind() != reflect.Ptr {
+		return nil, ErrInvalidStruct
+	}
+
+	o := newOptions(opts)
+
+	scratch := reflect.New(typ.Elem())
+
+	fields, err := extractFields(prefix, o.separator, nil, nil, scratch.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("extract fields from config struct: %w", err)
+	}
+
+	if err := checkKeys(typ.Elem(), fields); err != nil {
+		return nil, err
+	}
+
+	o.env = o.environment()
+	_, o.versioned = findVersion(cfg)
+
+	if err := bindOptions(fields, o); err != nil {
+		return nil, err
+	}
+	applyDefaults(scratch.Elem())
+
+	d := Diagnosis{
+		Prefix: prefix,
+		Found:  make(map[string]string),
+	}
+
+	fields, err = expandElements(fields, o)
+	if err != nil {
+		d.Invalid = append(d.Invalid, Warning{Key: strings.ToUpper(prefix), Message: err.Error()})
+	}
+
+	values, err := resolveValues(fields, o)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range fields {
+		d.Expected = append(d.Expected, field.EnvKey)
+		if _, ok := values[field.EnvKey]; ok {
+			d.Found[field.EnvKey] = o.origins[field.EnvKey]
+		}
+	}
+
+	if prefix != "" && len(values) == 0 {
+		if bare, err := extractFields("", o.separator, nil, nil, scratch.Interface()); err == nil {
+			if suggested := suggestPrefix(bare, o); suggested != "" && !strings.EqualFold(suggested, prefix) {
+				d.SuggestedPrefix = suggested
+			}
+		}
+	}
+
+	// The variables not belonging to any field are reported as warnings
+	// without strict mode.
+	o.strict, o.unknownWarnings = false, true
+	_ = checkUnknownKeys(prefix, fields, o)
+	d.Unknown, o.collected = o.collected, nil
+
+	hints := missingHints(fields, values, o)
+
+	for _, field := range fields {
+		if field.Options.RemovedIn != "" {
+			if err := checkRemovals(cfg, []Field{field}, values, o); err != nil {
+				d.Invalid = append(d.Invalid, Warning{Key: field.EnvKey, Message: err.Error()})
+				continue
+			}
+		}
+
+		checkDeprecations([]Field{field}, values, o)
+
+		if field.Options.Expires != "" {
+			if err := checkExpiries([]Field{field}, o); err != nil {
+				d.Invalid = append(d.Invalid, Warning{Key: field.EnvKey, Message: err.Error()})
+				continue
+			}
+		}
+
+		err := processValue(o.ctx, field, values, hints[field.EnvKey])
+		if err == nil {
+			continue
+		}
+
+		if _, ok := values[field.EnvKey]; !ok && field.Options.Required {
+			d.Missing = append(d.Missing, Warning{Key: field.EnvKey, Message: err.Error()})
+			continue
+		}
+
+		var fe *FieldError
+		if errors.As(err, &fe) {
+			err = fmt.Errorf("converting '%s' to type %s: %w", fe.value, fe.typeName, fe.err)
+		}
+		d.Invalid = append(d.Invalid, Warning{Key: field.EnvKey, Message: err.Error()})
+	}
+	storeMapEntries(o)
+
+	d.Warnings = o.collected
+
+	return &d, nil
+}
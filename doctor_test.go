@@ -0,0 +1,106 @@
+package conf
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDoctor(t *testing.T) {
+	os.Clearenv()
+
+	type config struct {
+		Host    string `conf:"required"`
+		Port    int    `conf:"default:8080"`
+		Timeout int
+		Legacy  string `conf:"removed_in:v3"`
+		Debug   struct {
+			Host string
+		}
+	}
+
+	env := map[string]string{
+		"TEST_PORT":       "eighty",
+		"TEST_TIMEOUT":    "5",
+		"TEST_LEGACY":     "on",
+		"TEST_HSOT":       "localhost",
+		"TEST_DEBUG_HSOT": "localhost",
+	}
+
+	var cfg config
+	d, err := Doctor("test", &cfg, WithEnviron(env), WithVersion("v2"), WithArgs(nil))
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to diagnose the config : %s.", failed, err)
+	}
+
+	if d.OK() {
+		t.Fatalf("\t%s\tShould report the config as broken.", failed)
+	}
+
+	if cfg.Host != "" || cfg.Port != 0 || cfg.Timeout != 0 {
+		t.Fatalf("\t%s\tShould leave the config struct untouched : %+v.", failed, cfg)
+	}
+	t.Logf("\t%s\tShould leave the config struct untouched.", success)
+
+	want := &Diagnosis{
+		Prefix:   "test",
+		Expected: []string{"TEST_HOST", "TEST_PORT", "TEST_TIMEOUT", "TEST_LEGACY", "TEST_DEBUG_HOST"},
+		Found:    map[string]string{"TEST_PORT": "env", "TEST_TIMEOUT": "env", "TEST_LEGACY": "env"},
+		Missing: []Warning{
+			{Key: "TEST_HOST", Message: "required field Host (TEST_HOST) is missing value, TEST_HSOT found; did you mean TEST_HOST?"},
+		},
+		Invalid: []Warning{
+			{Key: "TEST_PORT", Message: `converting 'eighty' to type int: strconv.ParseInt: parsing "eighty": invalid syntax, expected an integer from -9223372036854775808 to 9223372036854775807`},
+		},
+		Unknown: []Warning{
+			{Key: "TEST_DEBUG_HSOT", Message: "variable doesn't belong to any field, did you mean TEST_DEBUG_HOST?"},
+			{Key: "TEST_HSOT", Message: "variable doesn't belong to any field, did you mean TEST_HOST?"},
+		},
+		Warnings: []Warning{
+			{Key: "TEST_LEGACY", Message: "field Legacy is set but will be removed in v3"},
+		},
+	}
+
+	if diff := cmp.Diff(want, d); diff != "" {
+		t.Fatalf("\t%s\tShould report every problem :\n%s", failed, diff)
+	}
+	t.Logf("\t%s\tShould report every problem.", success)
+
+	out := d.String()
+	for _, line := range []string{
+		"Variables found: 3 of 5 expected\n",
+		"  TEST_PORT (env)\n",
+		"Missing required variables:\n  TEST_HOST: required field Host",
+		"Invalid values:\n  TEST_PORT: converting 'eighty'",
+		"Unknown variables:\n  TEST_DEBUG_HSOT: ",
+		"Warnings:\n  TEST_LEGACY: field Legacy is set but will be removed in v3\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Fatalf("\t%s\tShould render %q for humans :\n%s", failed, line, out)
+		}
+	}
+	t.Logf("\t%s\tShould render the problems for humans.", success)
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to marshal to JSON : %s.", failed, err)
+	}
+
+	if !strings.Contains(string(data), `"missing":[{"key":"TEST_HOST","message":"required field Host`) {
+		t.Fatalf("\t%s\tShould marshal to JSON : %s.", failed, data)
+	}
+	t.Logf("\t%s\tShould marshal to JSON.", success)
+
+	d, err = Doctor("app", &cfg, WithEnviron(map[string]string{"TEST_HOST": "localhost"}), WithArgs(nil))
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to diagnose the config : %s.", failed, err)
+	}
+
+	if d.SuggestedPrefix != "test" || !strings.Contains(d.String(), "did you mean the prefix test?") {
+		t.Fatalf("\t%s\tShould suggest the prefix : %q.", failed, d.SuggestedPrefix)
+	}
+	t.Logf("\t%s\tShould suggest the prefix.", success)
+}
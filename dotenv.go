@@ -0,0 +1,200 @@
+package conf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// WithEnvFile reads KEY=value pairs from the .env file at path, merged
+// below the sources which take precedence. The file is read on every parse,
+// comments, quoted values and the `export` prefix are supported.
+func WithEnvFile(path string) Option {
+	return withFile(&envFileSource{path: path})
+}
+
+type envFileSource struct {
+	path string
+
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+func (s *envFileSource) Source(fld Field) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	value, ok := s.values[fld.EnvKey]
+	return value, ok
+}
+
+func (s *envFileSource) Load() error {
+	return s.loadFS(nil)
+}
+
+func (s *envFileSource) loadFS(fsys fs.FS) error {
+	data, err := readFile(fsys, s.path)
+	if err != nil {
+		return err
+	}
+
+	values, err := parseDotenv(string(data))
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values = values
+
+	return nil
+}
+
+func (s *envFileSource) String() string {
+	return "env file " + s.path
+}
+
+// WriteDotenv writes the values of the specified config struct as a .env
+// file under the prefix, which WithEnvFile reads back, e.g. for reproducing
+// the effective config of production locally:
+//
+//	err := conf.WriteDotenv(f, "my_service", &cfg, conf.WithDeniedEnv("*_TOKEN"))
+//
+// The values are rendered like by Environ. Secrets are left to be pulled
+// by reference: fields tagged with `secret` are written as a comment naming
+// the secret read from the secrets dir, and values redacted in errors, such
+// as masked fields and passwords of DSNs, as a commented out assignment of
+// the redacted value to be filled in.
+func WriteDotenv(w io.Writer, prefix string, cfg any, opts ...Option) error {
+	o := newOptions(opts)
+
+	fields, err := envFields(prefix, cfg, o)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "# The config under the prefix %s.\n", strings.ToUpper(prefix))
+
+	for _, key := range keys {
+		field := fields[key]
+		value := envValue(field)
+
+		switch redacted := redactValue(value, field); {
+		case field.Options.Secret:
+			name := field.Options.SecretName
+			if name == "" {
+				name = strings.ToLower(key)
+			}
+			fmt.Fprintf(bw, "# %s is read from the secret %s\n", key, name)
+		case redacted != value:
+			fmt.Fprintf(bw, "# %s=%s\n", key, quoteDotenv(redacted))
+		default:
+			fmt.Fprintf(bw, "%s=%s\n", key, quoteDotenv(value))
+		}
+	}
+
+	return bw.Flush()
+}
+
+// quoteDotenv renders the value so parseDotenv gives it back, in double
+// quotes when it holds spaces, quotes, comments or line breaks.
+func quoteDotenv(value string) string {
+	if value == strings.TrimSpace(value) && !strings.ContainsAny(value, "\"'#\n\r\t\\") {
+		return value
+	}
+
+	r := strings.NewReplacer("\\", `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + r.Replace(value) + `"`
+}
+
+// parseDotenv parses the content of a .env file.
+func parseDotenv(data string) (map[string]string, error) {
+	values := make(map[string]string)
+
+	lines := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNum := i + 1
+		line := strings.TrimSpace(lines[i])
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: missing '=' in %q", lineNum, line)
+		}
+
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("line %d: missing key", lineNum)
+		}
+
+		value = strings.TrimSpace(value)
+
+		switch {
+		case strings.HasPrefix(value, `"`), strings.HasPrefix(value, "'"):
+			quote := value[:1]
+			value = value[1:]
+
+			// Quoted values may span multiple lines.
+			for closingQuote(value, quote) < 0 {
+				i++
+				if i == len(lines) {
+					return nil, fmt.Errorf("line %d: unterminated quoted value", lineNum)
+				}
+				value += "\n" + lines[i]
+			}
+
+			end := closingQuote(value, quote)
+			value = value[:end]
+			if quote == `"` {
+				value = unescapeDotenv(value)
+			}
+		default:
+
+			// Strip inline comments from unquoted values.
+			if idx := strings.Index(value, " #"); idx >= 0 {
+				value = strings.TrimSpace(value[:idx])
+			}
+		}
+
+		values[key] = value
+	}
+
+	return values, nil
+}
+
+// closingQuote returns the index of the unescaped quote ending the value.
+func closingQuote(value, quote string) int {
+	for i := 0; i < len(value); i++ {
+		switch {
+		case quote == `"` && value[i] == '\\':
+			i++
+		case value[i] == quote[0]:
+			return i
+		}
+	}
+
+	return -1
+}
+
+func unescapeDotenv(value string) string {
+	r := strings.NewReplacer(`\n`, "\n", `\r`, "\r", `\t`, "\t", `\"`, `"`, `\\`, `\`)
+	return r.Replace(value)
+}
@@ -0,0 +1,144 @@
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseDotenv(t *testing.T) {
+	data := `# comment
+export TEST_HOST=localhost
+TEST_PORT = 8080 # inline comment
+TEST_NAME="quoted # not a comment"
+TEST_RAW='single \n quoted'
+TEST_ESCAPED="line\nbreak \"quoted\""
+TEST_MULTI="first
+second"
+
+TEST_EMPTY=
+`
+
+	want := map[string]string{
+		"TEST_HOST":    "localhost",
+		"TEST_PORT":    "8080",
+		"TEST_NAME":    "quoted # not a comment",
+		"TEST_RAW":     `single \n quoted`,
+		"TEST_ESCAPED": "line\nbreak \"quoted\"",
+		"TEST_MULTI":   "first\nsecond",
+		"TEST_EMPTY":   "",
+	}
+
+	got, err := parseDotenv(data)
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to parse .env file : %s.", failed, err)
+	}
+	t.Logf("\t%s\tShould be able to parse .env file.", success)
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("\t%s\tShould have parsed all values\n%s", failed, diff)
+	}
+	t.Logf("\t%s\tShould have parsed all values.", success)
+
+	for _, bad := range []string{"TEST_HOST", `TEST_HOST="open`, "=value"} {
+		if _, err := parseDotenv(bad); err == nil {
+			t.Fatalf("\t%s\tShould NOT be able to parse %q.", failed, bad)
+		}
+	}
+	t.Logf("\t%s\tShould NOT be able to parse malformed lines.", success)
+}
+
+func TestParse_EnvFile(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("TEST_PORT", "9090")
+
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("TEST_HOST=file\nTEST_PORT=8080\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg struct {
+		Host string
+		Port int
+	}
+
+	if err := Parse("test", &cfg, WithEnvFile(path)); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse from .env file : %s.", failed, err)
+	}
+	t.Logf("\t%s\tShould be able to parse from .env file.", success)
+
+	if cfg.Host != "file" || cfg.Port != 9090 {
+		t.Fatalf("\t%s\tShould have merged .env file below env variables : %+v.", failed, cfg)
+	}
+	t.Logf("\t%s\tShould have merged .env file below env variables.", success)
+
+	if err := Parse("test", &cfg, WithEnvFile(filepath.Join(t.TempDir(), "missing"))); err == nil {
+		t.Fatalf("\t%s\tShould fail for missing .env file.", failed)
+	}
+	t.Logf("\t%s\tShould fail for missing .env file.", success)
+}
+
+func TestWriteDotenv(t *testing.T) {
+	os.Clearenv()
+
+	type config struct {
+		Host     string
+		Greeting string
+		Tags     []string
+		Token    string `conf:"mask"`
+		DB       DSN
+		Password string `conf:"secret:db_password"`
+		Timeout  *time.Duration
+	}
+
+	env := map[string]string{
+		"APP_HOST":     "prod.example.com",
+		"APP_GREETING": ` "hi" # there` + "\n",
+		"APP_TAGS":     `a;"b;c"`,
+		"APP_TOKEN":    "t0ken",
+		"APP_DB":       "postgres://app:s3cret@db:5432/app",
+	}
+
+	var cfg config
+	if err := Parse("app", &cfg, WithEnviron(env), WithArgs(nil), WithSecretsDir(t.TempDir())); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse : %s.", failed, err)
+	}
+
+	var b strings.Builder
+	if err := WriteDotenv(&b, "local", &cfg); err != nil {
+		t.Fatalf("\t%s\tShould be able to write the .env file : %s.", failed, err)
+	}
+
+	want := `# The config under the prefix LOCAL.
+# LOCAL_DB=postgres://app:xxxxxx@db:5432/app
+LOCAL_GREETING=" \"hi\" # there\n"
+LOCAL_HOST=prod.example.com
+# LOCAL_PASSWORD is read from the secret db_password
+LOCAL_TAGS="a;\"b;c\""
+# LOCAL_TOKEN=xxxxxx
+`
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Fatalf("\t%s\tShould write the values with the secrets left out :\n%s", failed, diff)
+	}
+	t.Logf("\t%s\tShould write the values with the secrets left out.", success)
+
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var local config
+	if err := Parse("local", &local, WithEnvFile(path), WithEnviron(map[string]string{}), WithArgs(nil)); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse the .env file : %s.", failed, err)
+	}
+
+	cfg.Token, cfg.DB = "", DSN{}
+	if diff := cmp.Diff(cfg, local); diff != "" {
+		t.Fatalf("\t%s\tShould reproduce the config :\n%s", failed, diff)
+	}
+	t.Logf("\t%s\tShould reproduce the config.", success)
+}
@@ -0,0 +1,82 @@
+package conf
+
+import (
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse_DSN(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("TEST_DB", "postgres://app:s3cret@db:5432/orders?sslmode=disable")
+
+	var cfg struct {
+		DB DSN
+	}
+
+	if err := Parse("test", &cfg); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse DSN : %s.", failed, err)
+	}
+
+	want := DSN{
+		Scheme:   "postgres",
+		User:     "app",
+		Password: "s3cret",
+		Host:     "db:5432",
+		Database: "orders",
+		Params:   url.Values{"sslmode": {"disable"}},
+	}
+	if diff := cmp.Diff(want, cfg.DB); diff != "" {
+		t.Fatalf("\t%s\tShould have parsed DSN components\n%s", failed, diff)
+	}
+	t.Logf("\t%s\tShould have parsed DSN components.", success)
+
+	if got := cfg.DB.URL(); got != "postgres://app:s3cret@db:5432/orders?sslmode=disable" {
+		t.Fatalf("\t%s\tShould render DSN in full with URL, got %s.", failed, got)
+	}
+	t.Logf("\t%s\tShould render DSN in full with URL.", success)
+
+	if got := cfg.DB.String(); got != "postgres://app:xxxxxx@db:5432/orders?sslmode=disable" {
+		t.Fatalf("\t%s\tShould redact password in String, got %s.", failed, got)
+	}
+	t.Logf("\t%s\tShould redact password in String.", success)
+
+	out, err := String(&cfg)
+	if err != nil || strings.Contains(out, "s3cret") {
+		t.Fatalf("\t%s\tShould redact password in printed config : %v\n%s", failed, err, out)
+	}
+	t.Logf("\t%s\tShould redact password in printed config.", success)
+}
+
+func TestParse_DSNErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		want string
+	}{
+		{"scheme", "app:s3cret@db/orders", "missing scheme"},
+		{"host", "postgres:///orders", "missing host"},
+		{"port", "postgres://app:s3cret@db:port/orders", "invalid port"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			_ = os.Setenv("TEST_DB", tt.env)
+
+			var cfg struct{ DB DSN }
+			err := Parse("test", &cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("\t%s\tShould fail for %s with %q : %v.", failed, tt.name, tt.want, err)
+			}
+
+			if strings.Contains(err.Error(), "s3cret") {
+				t.Fatalf("\t%s\tShould redact password in error : %s.", failed, err)
+			}
+			t.Logf("\t%s\tShould fail without revealing password : %s.", success, err)
+		})
+	}
+}
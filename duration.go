@@ -0,0 +1,137 @@
+package conf
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Day and Week are the units Duration accepts on top of those of
+// time.ParseDuration.
+const (
+	Day  = 24 * time.Hour
+	Week = 7 * Day
+)
+
+// Duration holds a duration set from values accepted by time.ParseDuration
+// extended with the units d for days and w for weeks, such as 7d, 2w or
+// 1d12h, commonly needed for retention settings. Days are 24 hours.
+type Duration time.Duration
+
+// Set implements the Setter interface.
+func (d *Duration) Set(data string) error {
+	invalid := fmt.Errorf("invalid duration %q, expected a duration such as 90s, 36h, 7d or 2w", data)
+	tooLarge := fmt.Errorf("duration %q is too large", data)
+
+	s := strings.TrimSpace(data)
+
+	neg := strings.HasPrefix(s, "-")
+	if neg || strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+
+	if s == "" {
+		return invalid
+	}
+
+	// Take the days and weeks out, the rest is left to time.ParseDuration.
+	var (
+		total time.Duration
+		rest  strings.Builder
+	)
+
+	for s != "" {
+		i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if i < 0 {
+			rest.WriteString(s)
+			break
+		}
+		if i == 0 {
+			return invalid
+		}
+
+		j := strings.IndexFunc(s[i:], func(r rune) bool { return r >= '0' && r <= '9' || r == '.' })
+		if j < 0 {
+			j = len(s) - i
+		}
+
+		num, unit := s[:i], s[i:i+j]
+		s = s[i+j:]
+
+		var mult time.Duration
+		switch unit {
+		case "d":
+			mult = Day
+		case "w":
+			mult = Week
+		default:
+			rest.WriteString(num + unit)
+			continue
+		}
+
+		n, err := strconv.ParseFloat(num, 64)
+		if err != nil {
+			return invalid
+		}
+
+		v := math.Round(n * float64(mult))
+		if v >= float64(math.MaxInt64-total) {
+			return tooLarge
+		}
+		total += time.Duration(v)
+	}
+
+	if rest.Len() > 0 {
+		std, err := time.ParseDuration(rest.String())
+		if err != nil {
+			return invalid
+		}
+		if std > math.MaxInt64-total {
+			return tooLarge
+		}
+		total += std
+	}
+
+	if neg {
+		total = -total
+	}
+
+	*d = Duration(total)
+
+	return nil
+}
+
+// Duration returns the duration as a time.Duration.
+func (d Duration) Duration() time.Duration {
+	return time.Duration(d)
+}
+
+// String renders the duration with weeks and days, such as 2w or 1d12h0m0s,
+// in the form accepted by Set.
+func (d Duration) String() string {
+	v := time.Duration(d)
+
+	var b strings.Builder
+	if v < 0 {
+		b.WriteByte('-')
+		v = -v
+	}
+
+	if w := v / Week; w > 0 {
+		fmt.Fprintf(&b, "%dw", w)
+		v -= w * Week
+	}
+
+	if days := v / Day; days > 0 {
+		fmt.Fprintf(&b, "%dd", days)
+		v -= days * Day
+	}
+
+	if v > 0 || b.Len() == 0 || b.String() == "-" {
+		b.WriteString(v.String())
+	}
+
+	return b.String()
+}
@@ -0,0 +1,82 @@
+package conf
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		str   string
+	}{
+		{"0", 0, "0s"},
+		{"90s", 90 * time.Second, "1m30s"},
+		{"7d", Week, "1w"},
+		{"2w", 2 * Week, "2w"},
+		{"1d12h", 36 * time.Hour, "1d12h0m0s"},
+		{"1w2d3h4m", Week + 2*Day + 3*time.Hour + 4*time.Minute, "1w2d3h4m0s"},
+		{"1.5d", 36 * time.Hour, "1d12h0m0s"},
+		{"-2d", -2 * Day, "-2d"},
+		{"1h30m", 90 * time.Minute, "1h30m0s"},
+	}
+
+	for _, tt := range tests {
+		var d Duration
+		if err := d.Set(tt.value); err != nil {
+			t.Fatalf("\t%s\tShould parse %q : %s.", failed, tt.value, err)
+		}
+
+		if d.Duration() != tt.want || d.String() != tt.str {
+			t.Fatalf("\t%s\tShould parse %q as %s rendered %s : got %s rendered %s.", failed, tt.value, tt.want, tt.str, d.Duration(), d)
+		}
+
+		var again Duration
+		if err := again.Set(d.String()); err != nil || again != d {
+			t.Fatalf("\t%s\tShould parse %q rendered from %q back : %v.", failed, d.String(), tt.value, err)
+		}
+	}
+	t.Logf("\t%s\tShould parse durations with days and weeks.", success)
+
+	for _, value := range []string{"", "d", "7", "7x", "1.2.3d", "1d-2h"} {
+		var d Duration
+		if err := d.Set(value); err == nil {
+			t.Fatalf("\t%s\tShould reject %q : got %s.", failed, value, d)
+		}
+	}
+	t.Logf("\t%s\tShould reject invalid durations.", success)
+
+	for _, value := range []string{"100000000w", "-100000000w", "15000w15000w", "15250w2562047h"} {
+		var d Duration
+		if err := d.Set(value); err == nil {
+			t.Fatalf("\t%s\tShould reject %q as too large : got %s.", failed, value, d)
+		}
+	}
+	t.Logf("\t%s\tShould reject durations overflowing time.Duration.", success)
+}
+
+func TestParse_Duration(t *testing.T) {
+	os.Clearenv()
+
+	var cfg struct {
+		Retention Duration `conf:"default:30d,min:1d,max:52w"`
+	}
+
+	if err := Parse("test", &cfg, WithEnviron(map[string]string{}), WithArgs(nil)); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse durations : %s.", failed, err)
+	}
+
+	if cfg.Retention.Duration() != 30*Day {
+		t.Fatalf("\t%s\tShould set the default : %s.", failed, cfg.Retention)
+	}
+	t.Logf("\t%s\tShould set the default.", success)
+
+	err := Parse("test", &cfg, WithEnviron(map[string]string{"TEST_RETENTION": "12h"}), WithArgs(nil))
+	if err == nil || !strings.Contains(err.Error(), "value 12h0m0s is out of bounds, min is 1d") {
+		t.Fatalf("\t%s\tShould bound the duration : %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould bound the duration : %s.", success, err)
+}
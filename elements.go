@@ -0,0 +1,331 @@
+package conf
+
+import (
+	"fmt"
+	"io/fs"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// isStructSlice reports whether the type is a slice of structs, or of
+// pointers to structs, whose elements are set from indexed keys such as
+// APP_ENDPOINTS_0_HOST rather than from a single value. Slice types
+// converting values themselves, such as Weights, aren't.
+func isStructSlice(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Slice || convertsItself(typ) {
+		return false
+	}
+
+	return isNested(reflect.New(structElem(typ)).Elem())
+}
+
+// isStructMap reports whether the type is a map of structs, or of pointers
+// to structs, whose values are set from keys naming the map key such as
+// APP_UPSTREAMS_API_URL rather than from a single value.
+func isStructMap(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Map || convertsItself(typ) {
+		return false
+	}
+
+	return isNested(reflect.New(structElem(typ)).Elem())
+}
+
+// convertsItself reports whether values of the type convert values
+// themselves.
+func convertsItself(typ reflect.Type) bool {
+	v := reflect.New(typ).Elem()
+	return setterFrom(v) != nil || textUnmarshaler(v) != nil || binaryUnmarshaler(v) != nil
+}
+
+// structElem returns the type of the elements of the slice or map type,
+// dereferencing pointers.
+func structElem(typ reflect.Type) reflect.Type {
+	elem := typ.Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+
+	return elem
+}
+
+// A mapEntry is a struct value of a map set once its fields are processed,
+// as the values of maps can't be set in place.
+type mapEntry struct {
+	m, key, value reflect.Value
+}
+
+// expandElements replaces the fields holding slices and maps of structs by
+// the fields of their elements, keyed by the index or the map key after the
+// key of the field, such as APP_ENDPOINTS_0_HOST or APP_UPSTREAMS_API_URL.
+// The elements are the ones found in the environment, the pinned values and
+// the files, the indexes of slices must run from 0 without gaps and the keys
+// of maps are lower case. Fields without any element are left untouched,
+// keeping the elements set by Defaults.
+func expandElements(fields []Field, o *options) ([]Field, error) {
+	var (
+		out  = make([]Field, 0, len(fields))
+		keys []string
+	)
+
+	for _, field := range fields {
+		typ := field.Field.Type()
+
+		isSlice, isMap := isStructSlice(typ), isStructMap(typ)
+		if !isSlice && !isMap {
+			out = append(out, field)
+			continue
+		}
+
+		if keys == nil {
+			keys = indexKeys(o)
+		}
+
+		var (
+			names []string
+			err   error
+		)
+		if isSlice {
+			names, err = sliceIndexes(field, keys, o)
+		} else {
+			names, err = mapKeys(field, keys, o)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(names) == 0 {
+			if field.Options.Required {
+				return nil, &RequiredError{
+					Field:    strings.Join(field.Path, "."),
+					EnvKey:   field.EnvKey,
+					expected: fmt.Sprintf("%s%s<%s>%s...", field.EnvKey, o.separator, elementKind(isSlice), o.separator),
+				}
+			}
+			continue
+		}
+
+		if o.maxItems > 0 && len(names) > o.maxItems {
+			return nil, fmt.Errorf("field %s (%s): %w, %d items exceed the limit of %d items", strings.Join(field.Path, "."), field.EnvKey, ErrValueTooLarge, len(names), o.maxItems)
+		}
+
+		var elems []reflect.Value
+		if isSlice {
+			sl := reflect.MakeSlice(typ, len(names), len(names))
+			field.Field.Set(sl)
+
+			for i := range names {
+				elems = append(elems, derefField(sl.Index(i)))
+			}
+		} else {
+			elems, err = makeMapEntries(field, names, o)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for i, name := range names {
+			inner, err := extractFields(field.EnvKey+o.separator+name, o.separator, append(field.Path[:len(field.Path):len(field.Path)], name), field.index, elems[i].Addr().Interface())
+			if err != nil {
+				return nil, err
+			}
+
+			if err := bindOptions(inner, o); err != nil {
+				return nil, err
+			}
+			applyDefaults(elems[i])
+
+			inner, err = expandElements(inner, o)
+			if err != nil {
+				return nil, err
+			}
+
+			out = append(out, inner...)
+		}
+	}
+
+	return out, nil
+}
+
+// elementKind names what follows the key of the field in the keys of
+// its elements.
+func elementKind(isSlice bool) string {
+	if isSlice {
+		return "index"
+	}
+	return "key"
+}
+
+// makeMapEntries sets a new map with the keys into the field and returns
+// the struct values to process for the keys in order. Struct values are
+// stored into the map by storeMapEntries once processed.
+func makeMapEntries(field Field, names []string, o *options) ([]reflect.Value, error) {
+	typ := field.Field.Type()
+
+	mp := reflect.MakeMapWithSize(typ, len(names))
+	field.Field.Set(mp)
+
+	elems := make([]reflect.Value, 0, len(names))
+	for _, name := range names {
+		key := reflect.New(typ.Key()).Elem()
+		if err := processField(false, name, key, field.Options); err != nil {
+			return nil, fmt.Errorf("field %s (%s): key %s: %w", strings.Join(field.Path, "."), field.EnvKey, name, err)
+		}
+
+		value := reflect.New(structElem(typ))
+		if typ.Elem().Kind() == reflect.Ptr {
+			mp.SetMapIndex(key, value)
+		} else {
+			o.entries = append(o.entries, mapEntry{m: mp, key: key, value: value})
+		}
+
+		elems = append(elems, value.Elem())
+	}
+
+	return elems, nil
+}
+
+// storeMapEntries stores the processed struct values into their maps.
+func storeMapEntries(o *options) {
+	for _, e := range o.entries {
+		e.m.SetMapIndex(e.key, e.value.Elem())
+	}
+	o.entries = nil
+}
+
+// indexKeys returns the keys of the environment and the pinned values
+// the elements of slices and maps of structs are looked for in.
+func indexKeys(o *options) []string {
+	keys := make([]string, 0, len(o.env)+len(o.values))
+	for key := range o.env {
+		keys = append(keys, key)
+	}
+	for key := range o.values {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// An elementLister is implemented by sources listing the elements of the
+// slice or map at the path, such as files.
+type elementLister interface {
+	elements(fsys fs.FS, path []string) []string
+}
+
+// listElements returns the elements of the slice or map at the path
+// listed by the sources.
+func listElements(path []string, o *options) []string {
+	var names []string
+
+	for _, src := range append(o.sources[:len(o.sources):len(o.sources)], o.files...) {
+		if l, ok := src.(elementLister); ok {
+			names = append(names, l.elements(o.fsys, path)...)
+		}
+	}
+
+	return names
+}
+
+// sliceIndexes returns the indexes of the elements of the slice field
+// following its key or listed by the sources.
+func sliceIndexes(field Field, keys []string, o *options) ([]string, error) {
+	head := field.EnvKey + o.separator
+
+	var found []string
+	for _, key := range keys {
+		if rest, ok := strings.CutPrefix(key, head); ok {
+			idx, _, _ := strings.Cut(rest, o.separator)
+			found = append(found, idx)
+		}
+	}
+	found = append(found, listElements(field.Path, o)...)
+
+	seen := make(map[int]bool)
+	for _, idx := range found {
+
+		// Leading zeros or signs would give two keys the same index.
+		i, err := strconv.Atoi(idx)
+		if err != nil || i < 0 || strconv.Itoa(i) != idx {
+			continue
+		}
+
+		seen[i] = true
+	}
+
+	indexes := make([]int, 0, len(seen))
+	for i := range seen {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	names := make([]string, len(indexes))
+	for want, i := range indexes {
+		if i != want {
+			return nil, fmt.Errorf("field %s (%s) is missing the element %s%d, indexes must run from 0 without gaps", strings.Join(field.Path, "."), field.EnvKey, head, want)
+		}
+		names[want] = strconv.Itoa(i)
+	}
+
+	return names, nil
+}
+
+// mapKeys returns the keys of the map field, in lower case and sorted,
+// named by the keys ending with the key of a field of the struct values
+// or listed by the sources. The longest field key wins, so
+// APP_UPSTREAMS_API_TLS_CERT is the key api rather than api_tls when the
+// struct has the fields TLS.Cert and Cert.
+func mapKeys(field Field, keys []string, o *options) ([]string, error) {
+	elemFields, err := extractFields("", o.separator, nil, nil, reflect.New(structElem(field.Field.Type())).Interface())
+	if err != nil {
+		return nil, fmt.Errorf("extract fields from values of %s: %w", strings.Join(field.Path, "."), err)
+	}
+
+	head := field.EnvKey + o.separator
+
+	found := listElements(field.Path, o)
+	for _, key := range keys {
+		rest, ok := strings.CutPrefix(key, head)
+		if !ok {
+			continue
+		}
+
+		var name string
+		for _, ef := range elemFields {
+			if ef.Options.EnvName != "" {
+				continue
+			}
+
+			n, ok := strings.CutSuffix(rest, o.separator+ef.EnvKey)
+
+			// Elements of slices and maps of the values follow their key.
+			if typ := ef.Field.Type(); !ok && (isStructSlice(typ) || isStructMap(typ)) {
+				if i := strings.Index(rest, o.separator+ef.EnvKey+o.separator); i > 0 {
+					n, ok = rest[:i], true
+				}
+			}
+
+			if ok && n != "" && (name == "" || len(n) < len(name)) {
+				name = n
+			}
+		}
+
+		if name != "" {
+			found = append(found, name)
+		}
+	}
+
+	seen := make(map[string]bool)
+	names := make([]string, 0, len(found))
+	for _, name := range found {
+		name = strings.ToLower(name)
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
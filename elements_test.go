@@ -0,0 +1,229 @@
+package conf
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type endpoint struct {
+	Host string `conf:"required"`
+	Port int    `conf:"default:80"`
+}
+
+func (e *endpoint) Validate() error {
+	if e.Host == "localhost" {
+		return errors.New("localhost isn't reachable")
+	}
+	return nil
+}
+
+type upstreams struct {
+	Endpoints []endpoint
+}
+
+func (u *upstreams) Defaults() {
+	u.Endpoints = []endpoint{{Host: "default", Port: 8080}}
+}
+
+func TestParse_StructSlices(t *testing.T) {
+	os.Clearenv()
+
+	var cfg struct {
+		Upstreams upstreams
+		Backups   []*endpoint
+	}
+
+	env := map[string]string{
+		"TEST_UPSTREAMS_ENDPOINTS_0_HOST": "a.example.com",
+		"TEST_UPSTREAMS_ENDPOINTS_1_HOST": "b.example.com",
+		"TEST_UPSTREAMS_ENDPOINTS_1_PORT": "8443",
+		"TEST_BACKUPS_0_HOST":             "c.example.com",
+	}
+	if err := Parse("test", &cfg, WithEnviron(env), WithStrict(), WithArgs(nil)); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse indexed keys : %s.", failed, err)
+	}
+
+	want := []endpoint{{Host: "a.example.com", Port: 80}, {Host: "b.example.com", Port: 8443}}
+	if diff := cmp.Diff(want, cfg.Upstreams.Endpoints); diff != "" {
+		t.Fatalf("\t%s\tShould set the elements from indexed keys :\n%s", failed, diff)
+	}
+
+	if len(cfg.Backups) != 1 || cfg.Backups[0].Host != "c.example.com" || cfg.Backups[0].Port != 80 {
+		t.Fatalf("\t%s\tShould set pointer elements from indexed keys : %+v.", failed, cfg.Backups)
+	}
+	t.Logf("\t%s\tShould set the elements from indexed keys.", success)
+
+	var defaults struct {
+		Upstreams upstreams
+	}
+	if err := Parse("test", &defaults, WithEnviron(map[string]string{}), WithArgs(nil)); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse without indexed keys : %s.", failed, err)
+	}
+
+	if diff := cmp.Diff([]endpoint{{Host: "default", Port: 8080}}, defaults.Upstreams.Endpoints); diff != "" {
+		t.Fatalf("\t%s\tShould keep the elements of Defaults without indexed keys :\n%s", failed, diff)
+	}
+	t.Logf("\t%s\tShould keep the elements of Defaults without indexed keys.", success)
+
+	p, err := NewParser("test", &upstreams{}, WithArgs(nil))
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to create a parser : %s.", failed, err)
+	}
+
+	for _, host := range []string{"a.example.com", "b.example.com"} {
+		var u upstreams
+		if err := p.Parse(&u, WithEnviron(map[string]string{"TEST_ENDPOINTS_0_HOST": host})); err != nil {
+			t.Fatalf("\t%s\tShould be able to parse indexed keys with a parser : %s.", failed, err)
+		}
+
+		if len(u.Endpoints) != 1 || u.Endpoints[0].Host != host {
+			t.Fatalf("\t%s\tShould set the elements with a parser : %+v.", failed, u.Endpoints)
+		}
+	}
+	t.Logf("\t%s\tShould set the elements with a parser.", success)
+}
+
+func TestParse_StructSlicesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{
+			name: "gap",
+			env:  map[string]string{"TEST_ENDPOINTS_0_HOST": "a", "TEST_ENDPOINTS_2_HOST": "c"},
+			want: "field Endpoints (TEST_ENDPOINTS) is missing the element TEST_ENDPOINTS_1",
+		},
+		{
+			name: "required-element-field",
+			env:  map[string]string{"TEST_ENDPOINTS_0_PORT": "81"},
+			want: "required field Endpoints.0.Host (TEST_ENDPOINTS_0_HOST) is missing value",
+		},
+		{
+			name: "validate",
+			env:  map[string]string{"TEST_ENDPOINTS_0_HOST": "localhost"},
+			want: "validate Endpoints[0]: localhost isn't reachable",
+		},
+		{
+			name: "required",
+			env:  map[string]string{},
+			want: "required field Endpoints (TEST_ENDPOINTS) is missing value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+
+			var cfg struct {
+				Endpoints []endpoint `conf:"required"`
+			}
+			err := Parse("test", &cfg, WithEnviron(tt.env), WithArgs(nil))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("\t%s\tShould fail with %q : got %v.", failed, tt.want, err)
+			}
+			t.Logf("\t%s\tShould fail with %q.", success, tt.want)
+		})
+	}
+}
+
+type upstream struct {
+	URL string `conf:"required"`
+	TLS struct {
+		Cert string
+	}
+	Timeout time.Duration `conf:"default:5s"`
+}
+
+func TestParse_StructMaps(t *testing.T) {
+	os.Clearenv()
+
+	var cfg struct {
+		Upstreams map[string]upstream
+		Backends  map[string]*endpoint
+	}
+
+	env := map[string]string{
+		"TEST_UPSTREAMS_API_URL":         "https://api.example.com",
+		"TEST_UPSTREAMS_API_TLS_CERT":    "api.pem",
+		"TEST_UPSTREAMS_AUTH_V2_URL":     "https://auth.example.com",
+		"TEST_UPSTREAMS_AUTH_V2_TIMEOUT": "1s",
+		"TEST_BACKENDS_EU_HOST":          "eu.example.com",
+	}
+	if err := Parse("test", &cfg, WithEnviron(env), WithStrict(), WithArgs(nil)); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse keyed variables : %s.", failed, err)
+	}
+
+	want := map[string]upstream{
+		"api":     {URL: "https://api.example.com", Timeout: 5 * time.Second},
+		"auth_v2": {URL: "https://auth.example.com", Timeout: time.Second},
+	}
+	api := want["api"]
+	api.TLS.Cert = "api.pem"
+	want["api"] = api
+
+	if diff := cmp.Diff(want, cfg.Upstreams); diff != "" {
+		t.Fatalf("\t%s\tShould set the values from keyed variables :\n%s", failed, diff)
+	}
+
+	if len(cfg.Backends) != 1 || cfg.Backends["eu"].Host != "eu.example.com" || cfg.Backends["eu"].Port != 80 {
+		t.Fatalf("\t%s\tShould set pointer values from keyed variables : %+v.", failed, cfg.Backends)
+	}
+	t.Logf("\t%s\tShould set the values from keyed variables.", success)
+
+	var invalid struct {
+		Backends map[string]endpoint
+	}
+	err := Parse("test", &invalid, WithEnviron(map[string]string{"TEST_BACKENDS_LOCAL_HOST": "localhost"}), WithArgs(nil))
+	if err == nil || !strings.Contains(err.Error(), "validate Backends[local]: localhost isn't reachable") {
+		t.Fatalf("\t%s\tShould validate the values : got %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould validate the values.", success)
+}
+
+const elementsYaml = `
+upstreams:
+  api:
+    url: https://api.example.com
+  billing:
+    url: https://billing.example.com
+endpoints:
+  - host: a.example.com
+  - host: b.example.com
+    port: 8443
+`
+
+func TestParse_ElementsFile(t *testing.T) {
+	os.Clearenv()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(elementsYaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg struct {
+		Upstreams map[string]upstream
+		Endpoints []endpoint
+	}
+
+	env := map[string]string{"TEST_UPSTREAMS_API_URL": "https://env.example.com"}
+	if err := Parse("test", &cfg, WithYamlFile(path), WithEnviron(env), WithArgs(nil)); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse elements from file : %s.", failed, err)
+	}
+
+	if len(cfg.Upstreams) != 2 || cfg.Upstreams["api"].URL != "https://env.example.com" || cfg.Upstreams["billing"].URL != "https://billing.example.com" {
+		t.Fatalf("\t%s\tShould merge map values from file below the environment : %+v.", failed, cfg.Upstreams)
+	}
+
+	want := []endpoint{{Host: "a.example.com", Port: 80}, {Host: "b.example.com", Port: 8443}}
+	if diff := cmp.Diff(want, cfg.Endpoints); diff != "" {
+		t.Fatalf("\t%s\tShould set slice elements from file :\n%s", failed, diff)
+	}
+	t.Logf("\t%s\tShould set elements from file.", success)
+}
@@ -0,0 +1,112 @@
+package conf
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Environ renders the values of the specified config struct as the KEY=value
+// variables configuring a child process with the same configuration under
+// the prefix, e.g. for supervisors spawning workers:
+//
+//	cmd.Env = append(os.Environ(), env...)
+//
+// The prefix may differ from the one the config struct was parsed with.
+// WithAllowedEnv and WithDeniedEnv filter the variables by their keys.
+// Unlike String the values aren't redacted, as the child needs them, and
+// slices and maps of structs are rendered with indexed keys. Fields without
+// a value other than strings are left out so the child applies its defaults.
+// The variables are sorted by key.
+func Environ(prefix string, cfg any, opts ...Option) ([]string, error) {
+	o := newOptions(opts)
+
+	fields, err := envFields(prefix, cfg, o)
+	if err != nil {
+		return nil, err
+	}
+
+	env := make([]string, 0, len(fields))
+	for key, field := range fields {
+		env = append(env, key+"="+envValue(field))
+	}
+	sort.Strings(env)
+
+	return env, nil
+}
+
+// envFields returns the fields of the config struct holding a value keyed by
+// the env keys under the prefix, with the elements of slices and maps of
+// structs under indexed keys. The keys are filtered by WithAllowedEnv and
+// WithDeniedEnv.
+func envFields(prefix string, cfg any, o *options) (map[string]Field, error) {
+	fields, err := extractFields(prefix, o.separator, nil, nil, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("extract fields from config struct: %w", err)
+	}
+
+	if err := bindOptions(fields, o); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]Field, len(fields))
+
+	for _, field := range fields {
+		typ := field.Field.Type()
+
+		var elems map[string]reflect.Value
+		switch {
+		case isStructSlice(typ):
+			elems = make(map[string]reflect.Value, field.Field.Len())
+			for i := 0; i < field.Field.Len(); i++ {
+				elems[strconv.Itoa(i)] = field.Field.Index(i)
+			}
+		case isStructMap(typ):
+			elems = make(map[string]reflect.Value, field.Field.Len())
+			iter := field.Field.MapRange()
+			for iter.Next() {
+				elems[formatValue(iter.Key(), field.Options)] = iter.Value()
+			}
+		default:
+			if o.allowEnv != nil && !matchKey(o.allowEnv, field.EnvKey) || matchKey(o.denyEnv, field.EnvKey) {
+				continue
+			}
+			if typ.Kind() != reflect.String && formatValue(field.Field, field.Options) == "" {
+				continue
+			}
+			values[field.EnvKey] = field
+			continue
+		}
+
+		for name, elem := range elems {
+			if elem.Kind() == reflect.Ptr {
+				if elem.IsNil() {
+					continue
+				}
+				elem = elem.Elem()
+			}
+
+			// Values of maps aren't addressable, render a copy.
+			v := reflect.New(elem.Type())
+			v.Elem().Set(elem)
+
+			inner, err := envFields(field.EnvKey+o.separator+name, v.Interface(), o)
+			if err != nil {
+				return nil, err
+			}
+
+			for key, f := range inner {
+				values[key] = f
+			}
+		}
+	}
+
+	return values, nil
+}
+
+// envValue renders the value of the field in full, unlike String which
+// redacts the passwords of DSNs and proxy URLs.
+func envValue(field Field) string {
+	return plainValue(field.Field, field.Options)
+}
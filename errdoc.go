@@ -0,0 +1,120 @@
+package conf
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// The codes of the errors in the documents of ErrorJSON. They are stable
+// so platforms can match them.
+const (
+	CodeMissingRequired = "missing_required"
+	CodeInvalidValue    = "invalid_value"
+	CodeValueTooLarge   = "value_too_large"
+	CodePanic           = "panic"
+	CodeTimeout         = "timeout"
+	CodeHelpWanted      = "help_wanted"
+	CodeVersionWanted   = "version_wanted"
+	CodeSampleWanted    = "sample_wanted"
+	CodeInvalidConfig   = "invalid_config"
+)
+
+type errorDoc struct {
+	Errors   []errorEntry `json:"errors"`
+	Warnings []Warning    `json:"warnings,omitempty"`
+}
+
+type errorEntry struct {
+	Code    string `json:"code"`
+	Field   string `json:"field,omitempty"`
+	EnvKey  string `json:"env_key,omitempty"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"`
+}
+
+// ErrorJSON renders the error of a parse and the warnings reported with
+// WithWarnings as a single line JSON document, for platforms scraping the
+// logs of containers for the reasons of failures:
+//
+//	{"errors":[{"code":"missing_required","field":"Host","env_key":"MY_SERVICE_HOST","message":"...","hint":"set MY_SERVICE_HOST or pass --host"}]}
+//
+// Every field error of the parse is an entry of its own with one of the
+// Code constants. Values are redacted like in the error messages.
+func ErrorJSON(err error, warnings ...Warning) []byte {
+	doc := errorDoc{
+		Errors:   errorEntries(err),
+		Warnings: warnings,
+	}
+
+	if doc.Errors == nil {
+		doc.Errors = []errorEntry{}
+	}
+
+	// The document holds only strings, so it always marshals.
+	data, _ := json.Marshal(doc)
+
+	return data
+}
+
+// errorEntries flattens the errors joined by the parse into entries.
+func errorEntries(err error) []errorEntry {
+	if err == nil {
+		return nil
+	}
+
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var entries []errorEntry
+		for _, err := range joined.Unwrap() {
+			entries = append(entries, errorEntries(err)...)
+		}
+		return entries
+	}
+
+	entry := errorEntry{Code: CodeInvalidConfig, Message: err.Error()}
+
+	var (
+		re *RequiredError
+		fe *FieldError
+		pe *PanicError
+	)
+
+	switch {
+	case errors.As(err, &re):
+		entry.Code = CodeMissingRequired
+		entry.Field, entry.EnvKey = re.Field, re.EnvKey
+
+		switch {
+		case re.found != "":
+			entry.Hint = "rename " + re.found + " to " + re.EnvKey
+		case re.FlagKey != "":
+			entry.Hint = "set " + re.EnvKey + " or pass --" + re.FlagKey
+		default:
+			entry.Hint = "set " + re.EnvKey
+		}
+	case errors.As(err, &fe):
+		entry.Code = CodeInvalidValue
+		entry.Field, entry.EnvKey = fe.fieldName, fe.envKey
+		entry.Hint = "fix the value of " + fe.envKey
+
+		switch {
+		case errors.Is(err, ErrValueTooLarge):
+			entry.Code = CodeValueTooLarge
+			entry.Hint = "shorten the value of " + fe.envKey
+		case errors.As(err, &pe):
+			entry.Code = CodePanic
+		}
+	case errors.Is(err, ErrValueTooLarge):
+		entry.Code = CodeValueTooLarge
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		entry.Code = CodeTimeout
+	case errors.Is(err, ErrHelpWanted):
+		entry.Code = CodeHelpWanted
+	case errors.Is(err, ErrVersionWanted):
+		entry.Code = CodeVersionWanted
+	case errors.Is(err, ErrSampleWanted):
+		entry.Code = CodeSampleWanted
+	}
+
+	return []errorEntry{entry}
+}
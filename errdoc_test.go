@@ -0,0 +1,93 @@
+package conf
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestErrorJSON(t *testing.T) {
+	os.Clearenv()
+
+	var cfg struct {
+		Host   string `conf:"required"`
+		Port   int
+		Name   string
+		Legacy string `conf:"removed_in:v3"`
+	}
+
+	env := map[string]string{
+		"TEST_PORT":   "eighty",
+		"TEST_HSOT":   "localhost",
+		"TEST_NAME":   "a-very-long-name",
+		"TEST_LEGACY": "on",
+	}
+
+	var warnings []Warning
+	err := Parse("test", &cfg, WithEnviron(env), WithMaxValueLength(10), WithVersion("v2"), WithUnknownWarnings(), WithWarnings(func(w Warning) { warnings = append(warnings, w) }), WithArgs(nil))
+	if err == nil {
+		t.Fatalf("\t%s\tShould fail to parse.", failed)
+	}
+
+	var doc struct {
+		Errors   []map[string]string `json:"errors"`
+		Warnings []Warning           `json:"warnings"`
+	}
+	if err := json.Unmarshal(ErrorJSON(err, warnings...), &doc); err != nil {
+		t.Fatalf("\t%s\tShould render valid JSON : %s.", failed, err)
+	}
+
+	want := []map[string]string{
+		{
+			"code":    CodeMissingRequired,
+			"field":   "Host",
+			"env_key": "TEST_HOST",
+			"message": "required field Host (TEST_HOST) is missing value, TEST_HSOT found; did you mean TEST_HOST?",
+			"hint":    "rename TEST_HSOT to TEST_HOST",
+		},
+		{
+			"code":    CodeInvalidValue,
+			"field":   "Port",
+			"env_key": "TEST_PORT",
+			"message": err.(interface{ Unwrap() []error }).Unwrap()[1].Error(),
+			"hint":    "fix the value of TEST_PORT",
+		},
+		{
+			"code":    CodeValueTooLarge,
+			"field":   "Name",
+			"env_key": "TEST_NAME",
+			"message": "error assigning to field Name (TEST_NAME): converting 'a-very-long-name' to type string. details: value too large, 16 bytes exceed the limit of 10 bytes",
+			"hint":    "shorten the value of TEST_NAME",
+		},
+	}
+
+	if diff := cmp.Diff(want, doc.Errors); diff != "" {
+		t.Fatalf("\t%s\tShould render an entry per error :\n%s", failed, diff)
+	}
+
+	if len(doc.Warnings) != 2 || doc.Warnings[1].Key != "TEST_LEGACY" {
+		t.Fatalf("\t%s\tShould render the warnings : %+v.", failed, doc.Warnings)
+	}
+	t.Logf("\t%s\tShould render an entry per error and the warnings.", success)
+
+	for _, tt := range []struct {
+		err  error
+		code string
+	}{
+		{ErrHelpWanted, CodeHelpWanted},
+		{errors.New("validate config: boom"), CodeInvalidConfig},
+	} {
+		if err := json.Unmarshal(ErrorJSON(tt.err), &doc); err != nil || doc.Errors[0]["code"] != tt.code {
+			t.Fatalf("\t%s\tShould render %v with the code %s : %+v.", failed, tt.err, tt.code, doc.Errors)
+		}
+	}
+	t.Logf("\t%s\tShould render other errors with their codes.", success)
+
+	if got := string(ErrorJSON(nil)); got != `{"errors":[]}` {
+		t.Fatalf("\t%s\tShould render no errors : %s.", failed, got)
+	}
+	t.Logf("\t%s\tShould render no errors.", success)
+}
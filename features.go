@@ -0,0 +1,44 @@
+package conf
+
+import (
+	"sort"
+	"strings"
+)
+
+// Features holds the set of enabled feature flags, set from a value such as
+// new-ui,beta-search. The oneof tag option limits the flags which can be
+// enabled, e.g. `conf:"oneof:new-ui|beta-search"`.
+type Features map[string]bool
+
+// Set implements the Setter interface.
+func (f *Features) Set(data string) error {
+	features := make(Features)
+
+	for _, name := range strings.Split(data, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			features[name] = true
+		}
+	}
+
+	*f = features
+
+	return nil
+}
+
+// Has reports whether the feature flag is enabled.
+func (f Features) Has(name string) bool {
+	return f[name]
+}
+
+// String renders the enabled feature flags in the form accepted by Set.
+func (f Features) String() string {
+	names := make([]string, 0, len(f))
+	for name, enabled := range f {
+		if enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ",")
+}
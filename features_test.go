@@ -0,0 +1,39 @@
+package conf
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParse_Features(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("TEST_FEATURES", "new-ui, beta-search")
+
+	var cfg struct {
+		Features Features `conf:"oneof:new-ui|beta-search|dark-mode"`
+	}
+
+	if err := Parse("test", &cfg); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse feature flags : %s.", failed, err)
+	}
+
+	if !cfg.Features.Has("new-ui") || !cfg.Features.Has("beta-search") || cfg.Features.Has("dark-mode") {
+		t.Fatalf("\t%s\tShould have enabled the listed feature flags only : %s.", failed, cfg.Features)
+	}
+	t.Logf("\t%s\tShould have enabled the listed feature flags only.", success)
+
+	if got := cfg.Features.String(); got != "beta-search,new-ui" {
+		t.Fatalf("\t%s\tShould render feature flags in the form accepted, got %s.", failed, got)
+	}
+	t.Logf("\t%s\tShould render feature flags in the form accepted.", success)
+
+	os.Clearenv()
+	_ = os.Setenv("TEST_FEATURES", "new-ui,new-checkout")
+
+	err := Parse("test", &cfg)
+	if err == nil || !strings.Contains(err.Error(), `value "new-checkout" is not one of new-ui/beta-search/dark-mode`) {
+		t.Fatalf("\t%s\tShould fail for undeclared feature flag : %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould fail for undeclared feature flag : %s.", success, err)
+}
@@ -4,11 +4,18 @@ import (
 	"encoding"
 	"errors"
 	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
 	"reflect"
+	"runtime/debug"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
+	"unicode/utf8"
 )
 
 var ErrInvalidStruct = errors.New("configuration must be a struct pointer")
@@ -27,23 +34,200 @@ func (err *FieldError) Error() string {
 	return fmt.Sprintf("error assigning to field %s (%s): converting '%s' to type %s. details: %s", err.fieldName, err.envKey, err.value, err.typeName, err.err)
 }
 
+// Unwrap returns the underlying conversion error.
+func (err *FieldError) Unwrap() error {
+	return err.err
+}
+
+// A RequiredError occurs when a required field received no value. Field
+// is the path of the field such as DB.Host, EnvKey and FlagKey are the
+// variable and the flag setting it, for rendering guidance to operators.
+type RequiredError struct {
+	Field   string
+	EnvKey  string
+	FlagKey string
+
+	// found is the variable of the environment likely meant to set
+	// the field, see missingHints.
+	found string
+
+	// expected describes the keys of the elements of slices and maps
+	// of structs.
+	expected string
+}
+
+func (err *RequiredError) Error() string {
+	msg := fmt.Sprintf("required field %s (%s) is missing value", err.Field, err.EnvKey)
+
+	switch {
+	case err.found != "":
+		msg += fmt.Sprintf(", %s found; did you mean %s?", err.found, err.EnvKey)
+	case err.expected != "":
+		msg += ", expected " + err.expected
+	}
+
+	return msg
+}
+
+// A PanicError is the cause of a FieldError when a custom type panicked
+// while converting the value. Stack holds the stack trace of the panic.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (err *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", err.Value)
+}
+
 // Field maintains information about a field in the configuration struct.
+// Path holds the names of the struct fields leading to the field, skipping
+// embedded structs, e.g. IP.Name is ["IP", "Name"].
 type Field struct {
-	Name    string
-	EnvKey  string
-	Field   reflect.Value
-	Options FieldOptions
+	Name      string
+	Path      []string
+	EnvKey    string
+	FlagKey   string
+	ShortFlag string
+	Field     reflect.Value
+	Options   FieldOptions
+
+	// index is the sequence of struct field indexes leading to the field.
+	index []int
+
+	// redact renders the value of the field if masked, see WithRedaction.
+	redact RedactionFunc
+
+	// defaults is the file system of the embedded defaults,
+	// see WithDefaultsFS.
+	defaults fs.FS
+
+	// fsys is the file system the default file is read from, see WithFS.
+	fsys fs.FS
+
+	// maxLen and maxItems limit the values of the field, see
+	// WithMaxValueLength and WithMaxElements.
+	maxLen   int
+	maxItems int
 }
 
 // FieldOptions maintain flag options for a given field.
 type FieldOptions struct {
-	DefaultVal string
-	EnvName    string
-	Required   bool
+	DefaultVal   string
+	DefaultFile  string
+	DefaultEmbed string
+	EnvName      string
+	Help         string
+	Required     bool
+	Mask         bool
+	NoPrint      bool
+	Prefix       string
+	Min          string
+	Max          string
+	Flag         string
+	Short        string
+	Sep          string
+	KVSep        string
+	Layout       string
+	OneOf        []string
+	Len          string
+	MimeType     bool
+	Secret       bool
+	SecretName   string
+	File         bool
+	RemovedIn    string
+	Expires      string
+	Aliases      []string
+	Deprecated   string
+	Chunked      bool
+	Lazy         bool
+}
+
+// Fields returns the fields of the specified config struct with the keys
+// derived the same way Parse does, so tools can build docs, admin UIs or
+// validation around them. The config struct isn't parsed, Field.Value
+// renders the value the struct currently holds.
+func Fields(prefix string, cfg any, opts ...Option) ([]Field, error) {
+	o := newOptions(opts)
+
+	fields, err := extractFields(prefix, o.separator, nil, nil, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("extract fields from config struct: %w", err)
+	}
+
+	if err := checkKeys(reflect.TypeOf(cfg).Elem(), fields); err != nil {
+		return nil, err
+	}
+
+	if err := bindOptions(fields, o); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+// Value renders the current value of the field in the form accepted by
+// the sources, values of fields tagged with `mask` or `secret` are
+// redacted, see WithRedaction, as are the passwords of URLs.
+func (f Field) Value() string {
+	return redactValue(formatValue(f.Field, f.Options), f)
+}
+
+// fieldPlans caches the fields extracted from the types of config structs,
+// so repeated parses of the same type only bind the fields to the value.
+// The plans are extracted without a prefix, which is applied to the env
+// keys on binding, so the cache grows with the types and not the prefixes.
+var fieldPlans sync.Map
+
+// planKey identifies the fields extracted from a type with the separator.
+type planKey struct {
+	typ reflect.Type
+	sep string
 }
 
 // extractFields uses reflection to examine the struct and generate the keys.
-func extractFields(prefix string, target any) ([]Field, error) {
+// Env keys start with the prefix followed by the separator, paths and flag
+// keys start with the path and indexes start with the index, both empty for
+// the top-level struct. The fields of top-level structs are extracted once
+// per type and bound to the target afterwards.
+func extractFields(prefix, sep string, path []string, index []int, target any) ([]Field, error) {
+	v := reflect.ValueOf(target)
+	if path != nil || index != nil || v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return walkFields(prefix, sep, path, index, target)
+	}
+
+	key := planKey{typ: v.Type(), sep: sep}
+	plan, ok := fieldPlans.Load(key)
+	if !ok {
+		fields, err := walkFields("", sep, nil, nil, target)
+		if err != nil {
+			return nil, err
+		}
+
+		// The plan doesn't hold on to the target.
+		for i := range fields {
+			fields[i].Field = reflect.Value{}
+		}
+
+		plan, _ = fieldPlans.LoadOrStore(key, fields)
+	}
+
+	fields := bindFields(plan.([]Field), v.Elem())
+
+	// Keys set with the env tag are used as they are.
+	if prefix != "" {
+		for i, field := range fields {
+			if field.Options.EnvName == "" {
+				fields[i].EnvKey = strings.ToUpper(prefix) + sep + field.EnvKey
+			}
+		}
+	}
+
+	return fields, nil
+}
+
+// walkFields does the work for extractFields.
+func walkFields(prefix, sep string, path []string, index []int, target any) ([]Field, error) {
 	s := reflect.ValueOf(target)
 
 	if s.Kind() != reflect.Ptr {
@@ -51,6 +235,13 @@ func extractFields(prefix string, target any) ([]Field, error) {
 	}
 
 	s = s.Elem()
+
+	// Named map and slice types are wrapped into a single field
+	// using the prefix as its key.
+	if (s.Kind() == reflect.Map || s.Kind() == reflect.Slice) && s.Type().Name() != "" {
+		return wrapValue(prefix, s)
+	}
+
 	if s.Kind() != reflect.Struct {
 		return nil, ErrInvalidStruct
 	}
@@ -66,8 +257,8 @@ func extractFields(prefix string, target any) ([]Field, error) {
 		// Get the conf tags associated with this item.
 		fieldTags := structField.Tag.Get("conf")
 
-		// If it's ignored or can't be set, move on.
-		if !f.CanSet() || fieldTags == "-" {
+		// If it's ignored, can't be set or holds the version, move on.
+		if !f.CanSet() || fieldTags == "-" || f.Type() == versionType {
 			continue
 		}
 
@@ -79,57 +270,70 @@ func extractFields(prefix string, target any) ([]Field, error) {
 		}
 
 		// Generate the field key.
-		fieldKey := strings.ToUpper(fmt.Sprintf("%s_%s", prefix, strings.Join(camelSplit(fieldName), "_")))
-		if prefix == "" {
-			fieldKey = fieldKey[1:]
+		fieldKey := strings.ToUpper(strings.Join(camelSplit(fieldName), "_"))
+		if prefix != "" {
+			fieldKey = strings.ToUpper(prefix) + sep + fieldKey
 		}
 
-		// Drill down through pointers until we bottom out at type or nil.
-		for f.Kind() == reflect.Ptr {
-			if f.IsNil() {
-
-				// It's not a struct so leave it alone.
-				if f.Type().Elem().Kind() != reflect.Struct {
-					break
-				}
+		fieldPath := append(path[:len(path):len(path)], fieldName)
+		fieldIndex := append(index[:len(index):len(index)], i)
 
-				// It's a struct so zero it out.
-				f.Set(reflect.New(f.Type().Elem()))
-			}
-			f = f.Elem()
-		}
+		f = derefField(f)
 
 		switch {
 
 		// If we found a struct that can't deserialize itself, drill down,
 		// appending fields as we go.
-		case f.Kind() == reflect.Struct && setterFrom(f) == nil && textUnmarshaler(f) == nil && binaryUnmarshaler(f) == nil:
+		case isNested(f):
 
 			// Prefix for any sub keys is the fieldKey, unless it's anonymous,
 			// then it's just the prefix so far.
-			innerPrefix := fieldKey
+			innerPrefix, innerPath := fieldKey, fieldPath
 			if structField.Anonymous {
-				innerPrefix = prefix
+				innerPrefix, innerPath = prefix, path
+			}
+
+			// The prefix tag replaces the name of the struct in the keys,
+			// which also gives embedded structs keys of their own.
+			if fieldOpts.Prefix != "" {
+				innerPrefix = strings.ToUpper(fieldOpts.Prefix)
+				if prefix != "" {
+					innerPrefix = strings.ToUpper(prefix) + sep + innerPrefix
+				}
+				innerPath = append(path[:len(path):len(path)], fieldOpts.Prefix)
 			}
 
 			embeddedPtr := f.Addr().Interface()
-			innerFields, err := extractFields(innerPrefix, embeddedPtr)
+			innerFields, err := walkFields(innerPrefix, sep, innerPath, fieldIndex, embeddedPtr)
 			if err != nil {
 				return nil, err
 			}
 			fields = append(fields, innerFields...)
 
 		default:
+			if fieldOpts.Lazy && !isLazy(f.Type()) {
+				return nil, fmt.Errorf("field %s: `required:lazy` requires a conf.LazyValue field, got %s", fieldName, f.Type())
+			}
+
 			envKey := fieldKey
 			if fieldOpts.EnvName != "" {
 				envKey = fieldOpts.EnvName
 			}
 
+			flagKey := flagName(fieldPath)
+			if fieldOpts.Flag != "" {
+				flagKey = fieldOpts.Flag
+			}
+
 			fld := Field{
-				Name:    fieldName,
-				EnvKey:  envKey,
-				Field:   f,
-				Options: fieldOpts,
+				Name:      fieldName,
+				Path:      fieldPath,
+				EnvKey:    envKey,
+				FlagKey:   flagKey,
+				ShortFlag: fieldOpts.Short,
+				Field:     f,
+				Options:   fieldOpts,
+				index:     fieldIndex,
 			}
 			fields = append(fields, fld)
 		}
@@ -138,6 +342,234 @@ func extractFields(prefix string, target any) ([]Field, error) {
 	return fields, nil
 }
 
+// Defaulter is implemented by config structs, top-level or nested,
+// constructing their defaults in code rather than in tags. Defaults is
+// called before the tag defaults and the sources are applied, nested
+// structs first. Tag defaults only apply to fields left zero by it.
+type Defaulter interface {
+	Defaults()
+}
+
+// applyDefaults calls Defaults on the struct value v and the nested
+// structs the fields are extracted from.
+func applyDefaults(v reflect.Value) {
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if !f.CanSet() || v.Type().Field(i).Tag.Get("conf") == "-" {
+			continue
+		}
+
+		f = derefField(f)
+		if isNested(f) {
+			applyDefaults(f)
+		}
+	}
+
+	if d, ok := v.Addr().Interface().(Defaulter); ok {
+		d.Defaults()
+	}
+}
+
+// Validator is implemented by config structs, top-level or nested,
+// checking their fields against each other, e.g. a certificate requiring
+// a key. Validate is called once all the fields are set, nested structs
+// first, and its error is returned by Parse.
+type Validator interface {
+	Validate() error
+}
+
+// validateStructs calls Validate on the struct value v and the nested
+// structs the fields are extracted from. The path names the struct value.
+func validateStructs(v reflect.Value, path []string) error {
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		structField := v.Type().Field(i)
+		if !f.CanSet() || structField.Tag.Get("conf") == "-" {
+			continue
+		}
+
+		innerPath := path
+		if !structField.Anonymous {
+			innerPath = append(path[:len(path):len(path)], structField.Name)
+		}
+
+		f = derefField(f)
+		if isNested(f) {
+			if err := validateStructs(f, innerPath); err != nil {
+				return err
+			}
+		}
+
+		if isStructSlice(f.Type()) {
+			for j := 0; j < f.Len(); j++ {
+				elemPath := append(path[:len(path):len(path)], fmt.Sprintf("%s[%d]", structField.Name, j))
+				if err := validateStructs(derefField(f.Index(j)), elemPath); err != nil {
+					return err
+				}
+			}
+		}
+
+		if isStructMap(f.Type()) {
+			keys := f.MapKeys()
+			sort.Slice(keys, func(a, b int) bool { return fmt.Sprint(keys[a]) < fmt.Sprint(keys[b]) })
+
+			for _, key := range keys {
+				// Values of maps aren't addressable, validate a copy.
+				elem := reflect.New(structElem(f.Type()))
+				elem.Elem().Set(reflect.Indirect(f.MapIndex(key)))
+
+				elemPath := append(path[:len(path):len(path)], fmt.Sprintf("%s[%v]", structField.Name, key))
+				if err := validateStructs(elem.Elem(), elemPath); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if val, ok := v.Addr().Interface().(Validator); ok {
+		if err := val.Validate(); err != nil {
+			if len(path) == 0 {
+				return fmt.Errorf("validate config: %w", err)
+			}
+			return fmt.Errorf("validate %s: %w", strings.Join(path, "."), err)
+		}
+	}
+
+	return nil
+}
+
+// checkKeys makes sure no two fields of the struct type share an env key,
+// which happens when embedded structs contribute the same field,
+// or a flag, which happens with the flag and short tags.
+func checkKeys(typ reflect.Type, fields []Field) error {
+	envKeys := make(map[string]Field, len(fields))
+	flags := make(map[string]Field, len(fields))
+	shorts := make(map[string]Field, len(fields))
+
+	for _, field := range fields {
+		if other, ok := envKeys[field.EnvKey]; ok {
+			return fmt.Errorf("fields %s and %s share the key %s, use the prefix tag on the embedded struct or the env tag to tell them apart", goPath(typ, other.index), goPath(typ, field.index), field.EnvKey)
+		}
+		envKeys[field.EnvKey] = field
+
+		if other, ok := flags[field.FlagKey]; ok {
+			return fmt.Errorf("fields %s and %s share the flag --%s, use the flag tag to tell them apart", goPath(typ, other.index), goPath(typ, field.index), field.FlagKey)
+		}
+		flags[field.FlagKey] = field
+
+		if field.ShortFlag == "" {
+			continue
+		}
+
+		if other, ok := shorts[field.ShortFlag]; ok {
+			return fmt.Errorf("fields %s and %s share the flag -%s, use the short tag to tell them apart", goPath(typ, other.index), goPath(typ, field.index), field.ShortFlag)
+		}
+		shorts[field.ShortFlag] = field
+	}
+
+	for _, field := range fields {
+		for _, alias := range field.Options.Aliases {
+			if other, ok := envKeys[alias]; ok {
+				return fmt.Errorf("the alias %s of field %s is the key of field %s", alias, goPath(typ, field.index), goPath(typ, other.index))
+			}
+		}
+	}
+
+	return nil
+}
+
+// goPath returns the selector of the field at the index of the struct type
+// including the names of embedded structs, e.g. Embed.Name.
+func goPath(typ reflect.Type, index []int) string {
+	names := make([]string, 0, len(index))
+
+	for _, i := range index {
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+
+		structField := typ.Field(i)
+		names = append(names, structField.Name)
+		typ = structField.Type
+	}
+
+	return strings.Join(names, ".")
+}
+
+// isNested reports whether the field is a struct whose fields are set one
+// by one rather than a value set as a whole.
+func isNested(f reflect.Value) bool {
+	if f.Kind() != reflect.Struct || f.Type() == ipNetType {
+		return false
+	}
+
+	return setterFrom(f) == nil && textUnmarshaler(f) == nil && binaryUnmarshaler(f) == nil && !isLazy(f.Type())
+}
+
+// derefField drills down through pointers until it bottoms out at type
+// or nil, allocating nil struct pointers on the way.
+func derefField(f reflect.Value) reflect.Value {
+	for f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+
+			// It's not a struct so leave it alone.
+			if f.Type().Elem().Kind() != reflect.Struct {
+				break
+			}
+
+			// It's a struct so zero it out.
+			f.Set(reflect.New(f.Type().Elem()))
+		}
+		f = f.Elem()
+	}
+
+	return f
+}
+
+// bindFields returns copies of the fields bound to the struct value v
+// of the same type the fields were extracted from.
+func bindFields(fields []Field, v reflect.Value) []Field {
+	out := make([]Field, len(fields))
+
+	for i, field := range fields {
+		f := v
+		for _, idx := range field.index {
+			f = derefField(f.Field(idx))
+		}
+
+		field.Field = f
+		out[i] = field
+	}
+
+	return out
+}
+
+// wrapValue makes a field from a top-level non-struct value so it can be
+// processed like any other field of a config struct.
+func wrapValue(prefix string, v reflect.Value) ([]Field, error) {
+	if prefix == "" {
+		return nil, fmt.Errorf("prefix is required for %s configuration", v.Type())
+	}
+
+	fld := Field{
+		Name:    v.Type().Name(),
+		Path:    []string{v.Type().Name()},
+		EnvKey:  strings.ToUpper(prefix),
+		FlagKey: strings.ToLower(strings.ReplaceAll(prefix, "_", "-")),
+		Field:   v,
+	}
+
+	return []Field{fld}, nil
+}
+
 func parseTag(tagStr string) (FieldOptions, error) {
 	var f FieldOptions
 
@@ -155,6 +587,18 @@ func parseTag(tagStr string) (FieldOptions, error) {
 			switch tagProp {
 			case "required":
 				f.Required = true
+			case "mask":
+				f.Mask = true
+			case "noprint":
+				f.NoPrint = true
+			case "mimetype":
+				f.MimeType = true
+			case "secret":
+				f.Secret = true
+			case "file":
+				f.File = true
+			case "chunked":
+				f.Chunked = true
 			}
 		case 2:
 			tagPropVal := strings.TrimSpace(vals[1])
@@ -164,9 +608,53 @@ func parseTag(tagStr string) (FieldOptions, error) {
 
 			switch tagProp {
 			case "default":
+				if path, ok := strings.CutPrefix(tagPropVal, "embed:"); ok {
+					f.DefaultEmbed = path
+					continue
+				}
 				f.DefaultVal = tagPropVal
+			case "defaultfile":
+				f.DefaultFile = tagPropVal
 			case "env":
 				f.EnvName = tagPropVal
+			case "help":
+				f.Help = tagPropVal
+			case "prefix":
+				f.Prefix = tagPropVal
+			case "min":
+				f.Min = tagPropVal
+			case "max":
+				f.Max = tagPropVal
+			case "flag":
+				f.Flag = tagPropVal
+			case "short":
+				f.Short = tagPropVal
+			case "sep":
+				f.Sep = separatorTag(tagPropVal)
+			case "kvsep":
+				f.KVSep = separatorTag(tagPropVal)
+			case "layout":
+				f.Layout = tagPropVal
+			case "secret":
+				f.Secret = true
+				f.SecretName = tagPropVal
+			case "oneof":
+				f.OneOf = strings.Split(tagPropVal, "|")
+			case "len":
+				f.Len = tagPropVal
+			case "removed_in":
+				f.RemovedIn = tagPropVal
+			case "expires":
+				f.Expires = tagPropVal
+			case "alias":
+				f.Aliases = strings.Split(tagPropVal, "|")
+			case "deprecated":
+				f.Deprecated = tagPropVal
+			case "required":
+				if tagPropVal != "lazy" {
+					return f, fmt.Errorf("invalid `required` %q, expected lazy", tagPropVal)
+				}
+				f.Lazy = true
 			}
 		}
 	}
@@ -176,9 +664,101 @@ func parseTag(tagStr string) (FieldOptions, error) {
 		return f, fmt.Errorf("cannot set both `required` and `default`")
 	}
 
+	if f.Lazy && (f.DefaultVal != "" || f.DefaultFile != "" || f.DefaultEmbed != "") {
+		return f, fmt.Errorf("cannot set both `required:lazy` and a default")
+	}
+
+	if f.DefaultFile != "" && (f.Required || f.DefaultVal != "") {
+		return f, fmt.Errorf("cannot set `defaultfile` with `required` or `default`")
+	}
+
+	if f.DefaultEmbed != "" && (f.Required || f.DefaultFile != "") {
+		return f, fmt.Errorf("cannot set `default:embed` with `required` or `defaultfile`")
+	}
+
+	if strings.HasPrefix(f.Flag, "-") || strings.ContainsAny(f.Flag, "= ") {
+		return f, fmt.Errorf("invalid `flag` %q, expected a name without dashes in front", f.Flag)
+	}
+
+	if f.Sep != "" && f.Sep == f.KVSep {
+		return f, fmt.Errorf("cannot set `sep` and `kvsep` to the same %q", f.Sep)
+	}
+
+	if f.Short != "" && (utf8.RuneCountInString(f.Short) != 1 || f.Short == "-" || f.Short == "=") {
+		return f, fmt.Errorf("invalid `short` %q, expected a single character", f.Short)
+	}
+
+	if _, ok := parseVersion(f.RemovedIn); f.RemovedIn != "" && !ok {
+		return f, fmt.Errorf("invalid `removed_in` %q, expected a version such as v2.0", f.RemovedIn)
+	}
+
+	for _, alias := range f.Aliases {
+		if alias == "" {
+			return f, fmt.Errorf("invalid `alias` %q, expected names separated by |", strings.Join(f.Aliases, "|"))
+		}
+	}
+
+	if _, err := time.Parse(time.DateOnly, f.Expires); f.Expires != "" && err != nil {
+		return f, fmt.Errorf("invalid `expires` %q, expected a date such as 2025-12-31", f.Expires)
+	}
+
 	return f, nil
 }
 
+// separatorTag returns the separator set by the sep and kvsep tag options.
+// A comma splits the tag itself, so it is written as the word comma.
+func separatorTag(val string) string {
+	if val == "comma" {
+		return ","
+	}
+
+	return val
+}
+
+// bindOptions sets the separators of the options into the fields left
+// without the sep and kvsep tag options, and the redaction into all of them.
+// Map fields whose items and keys would be split at the same separator are
+// rejected.
+func bindOptions(fields []Field, o *options) error {
+	for i := range fields {
+		fields[i].redact = o.redact
+		fields[i].defaults = o.defaults
+		fields[i].fsys = o.fsys
+		fields[i].maxLen = o.maxLen
+		fields[i].maxItems = o.maxItems
+
+		if fields[i].Options.Sep == "" {
+			fields[i].Options.Sep = o.listSep
+		}
+
+		if fields[i].Options.KVSep == "" {
+			fields[i].Options.KVSep = o.kvSep
+		}
+
+		typ := fields[i].Field.Type()
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+
+		if typ.Kind() == reflect.Map && fields[i].Options.Sep == fields[i].Options.KVSep {
+			return fmt.Errorf("field %s (%s): list separator %q equals the key/value separator", strings.Join(fields[i].Path, "."), fields[i].EnvKey, fields[i].Options.Sep)
+		}
+	}
+
+	return nil
+}
+
+// flagName generates the flag key from the field path,
+// e.g. ["IP", "DebugHost"] is ip-debug-host.
+func flagName(path []string) string {
+	parts := make([]string, 0, len(path))
+	for _, name := range path {
+		parts = append(parts, strings.Join(camelSplit(name), "-"))
+	}
+
+	return strings.ToLower(strings.Join(parts, "-"))
+}
+
 // camelSplit takes a string based on camel case and splits it.
 func camelSplit(src string) []string {
 	if src == "" {
@@ -252,6 +832,12 @@ type Setter interface {
 	Set(value string) error
 }
 
+// Enum is implemented by custom types accepting a fixed set of values.
+// The values are listed in the error when a conversion fails.
+type Enum interface {
+	Values() []string
+}
+
 func setterFrom(field reflect.Value) (s Setter) {
 	interfaceFrom(field, func(v any, ok *bool) { s, *ok = v.(Setter) })
 	return s
@@ -267,6 +853,24 @@ func binaryUnmarshaler(field reflect.Value) (b encoding.BinaryUnmarshaler) {
 	return b
 }
 
+func enumFrom(field reflect.Value) (e Enum) {
+	interfaceFrom(field, func(v any, ok *bool) { e, *ok = v.(Enum) })
+	return e
+}
+
+// expectEnum adds the values accepted by an Enum field to the err.
+func expectEnum(err error, field reflect.Value) error {
+	if err == nil {
+		return nil
+	}
+
+	if e := enumFrom(field); e != nil {
+		return fmt.Errorf("%w, expected one of %s", err, strings.Join(e.Values(), "/"))
+	}
+
+	return err
+}
+
 func interfaceFrom(field reflect.Value, fn func(any, *bool)) {
 	if !field.CanInterface() {
 		return
@@ -281,7 +885,39 @@ func interfaceFrom(field reflect.Value, fn func(any, *bool)) {
 	}
 }
 
-func processField(settingDefault bool, value string, field reflect.Value) error {
+// processFieldSafe calls processField turning a panic of a custom type
+// into a PanicError, so it can't crash the program without context.
+func processFieldSafe(settingDefault bool, value string, field reflect.Value, opts FieldOptions) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	return processField(settingDefault, value, field, opts)
+}
+
+var (
+	timeType  = reflect.TypeOf(time.Time{})
+	urlType   = reflect.TypeOf(url.URL{})
+	ipNetType = reflect.TypeOf(net.IPNet{})
+)
+
+// timeLayout returns the layout times are formatted in, RFC3339 with
+// fractional seconds unless set by the layout tag option.
+func timeLayout(opts FieldOptions) string {
+	if opts.Layout != "" {
+		return opts.Layout
+	}
+
+	return time.RFC3339Nano
+}
+
+// processField converts the value into the field. Slice and map items are
+// split on the Sep and map keys and values on the KVSep of the options,
+// times are parsed in their Layout.
+func processField(settingDefault bool, value string, field reflect.Value, opts FieldOptions) error {
+	field = lazyTarget(field)
 	typ := field.Type()
 
 	if typ.Kind() == reflect.Ptr {
@@ -296,17 +932,56 @@ func processField(settingDefault bool, value string, field reflect.Value) error
 		return nil
 	}
 
+	if typ == timeType {
+		layout := opts.Layout
+		if layout == "" {
+			layout = time.RFC3339
+		}
+
+		t, err := time.Parse(layout, value)
+		if err != nil {
+			return fmt.Errorf("%w, expected a time in the layout %s", err, layout)
+		}
+
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	if typ == ipNetType {
+		_, n, err := net.ParseCIDR(value)
+		if err != nil {
+			return fmt.Errorf("%w, expected a network such as 10.0.0.0/8 or fd00::/8", err)
+		}
+
+		field.Set(reflect.ValueOf(*n))
+		return nil
+	}
+
+	if typ == urlType {
+		u, err := url.Parse(value)
+		if err != nil {
+			return err
+		}
+
+		if !u.IsAbs() {
+			return fmt.Errorf("missing scheme in %q, expected an absolute URL such as https://example.com/path", value)
+		}
+
+		field.Set(reflect.ValueOf(*u))
+		return nil
+	}
+
 	setter := setterFrom(field)
 	if setter != nil {
-		return setter.Set(value)
+		return expectEnum(setter.Set(value), field)
 	}
 
 	if t := textUnmarshaler(field); t != nil {
-		return t.UnmarshalText([]byte(value))
+		return expectEnum(t.UnmarshalText([]byte(value)), field)
 	}
 
 	if b := binaryUnmarshaler(field); b != nil {
-		return b.UnmarshalBinary([]byte(value))
+		return expectEnum(b.UnmarshalBinary([]byte(value)), field)
 	}
 
 	switch typ.Kind() {
@@ -321,43 +996,51 @@ func processField(settingDefault bool, value string, field reflect.Value) error
 		if field.Kind() == reflect.Int64 && typ.PkgPath() == "time" && typ.Name() == "Duration" {
 			var d time.Duration
 			d, err = time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("%w, expected a duration such as 300ms, 1.5h or 2h45m", err)
+			}
 			val = int64(d)
 		} else {
 			val, err = strconv.ParseInt(value, 0, typ.Bits())
-		}
-		if err != nil {
-			return err
+			if err != nil {
+				minVal := int64(-1) << (typ.Bits() - 1)
+				return fmt.Errorf("%w, expected an integer from %d to %d", err, minVal, -(minVal + 1))
+			}
 		}
 
 		field.SetInt(val)
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		val, err := strconv.ParseUint(value, 0, typ.Bits())
 		if err != nil {
-			return err
+			return fmt.Errorf("%w, expected an integer from 0 to %d", err, uint64(1)<<typ.Bits()-1)
 		}
 
 		field.SetUint(val)
 	case reflect.Bool:
 		val, err := strconv.ParseBool(value)
 		if err != nil {
-			return err
+			return fmt.Errorf("%w, expected one of true/false/1/0/t/f", err)
 		}
 
 		field.SetBool(val)
 	case reflect.Float32, reflect.Float64:
 		val, err := strconv.ParseFloat(value, typ.Bits())
 		if err != nil {
-			return err
+			return fmt.Errorf("%w, expected a floating point number", err)
 		}
 
 		field.SetFloat(val)
 	case reflect.Slice:
-		vals := strings.Split(value, ";")
+		vals, err := splitList(value, opts.Sep, -1)
+		if err != nil {
+			return err
+		}
+
 		sl := reflect.MakeSlice(typ, len(vals), len(vals))
 		for i, val := range vals {
-			err := processField(false, val, sl.Index(i))
+			err := processField(false, unquoteItem(val, opts.Sep), sl.Index(i), opts)
 			if err != nil {
-				return err
+				return fmt.Errorf("element %d: %w", i, err)
 			}
 		}
 
@@ -365,23 +1048,38 @@ func processField(settingDefault bool, value string, field reflect.Value) error
 	case reflect.Map:
 		mp := reflect.MakeMap(typ)
 		if len(strings.TrimSpace(value)) != 0 {
-			pairs := strings.Split(value, ";")
+			pairs, err := splitList(value, opts.Sep, -1)
+			if err != nil {
+				return err
+			}
+
 			for _, pair := range pairs {
-				kvPair := strings.Split(pair, ":")
-				if len(kvPair) != 2 {
-					return fmt.Errorf("invalid map item: %q", pair)
+				kv, err := splitList(pair, opts.KVSep, 2)
+				if err != nil {
+					return err
+				}
+				if len(kv) != 2 {
+					return fmt.Errorf("invalid map item: %q, expected key%svalue", pair, opts.KVSep)
 				}
+				key, val := unquoteItem(kv[0], opts.Sep, opts.KVSep), unquoteItem(kv[1], opts.Sep, opts.KVSep)
 
+				// Keys go through the same conversions as values, so
+				// types such as a LogLevel implementing TextUnmarshaler
+				// can key maps.
 				k := reflect.New(typ.Key()).Elem()
-				err := processField(false, kvPair[0], k)
+				err = processField(false, key, k, opts)
 				if err != nil {
-					return err
+					return fmt.Errorf("invalid key of map item %q: %w", pair, err)
+				}
+
+				if mp.MapIndex(k).IsValid() {
+					return fmt.Errorf("duplicate key of map item %q", pair)
 				}
 
 				v := reflect.New(typ.Elem()).Elem()
-				err = processField(false, kvPair[1], v)
+				err = processField(false, val, v, opts)
 				if err != nil {
-					return err
+					return fmt.Errorf("key %s: %w", key, err)
 				}
 				mp.SetMapIndex(k, v)
 			}
@@ -393,3 +1091,216 @@ func processField(settingDefault bool, value string, field reflect.Value) error
 
 	return nil
 }
+
+// validateField enforces the min, max, len and oneof tag options
+// on the value converted into the field.
+func validateField(field reflect.Value, opts FieldOptions) error {
+	field = lazyTarget(field)
+
+	if err := checkBounds(field, opts); err != nil {
+		return err
+	}
+
+	if err := checkLen(field, opts.Len); err != nil {
+		return err
+	}
+
+	if err := checkMimeType(field, opts.MimeType); err != nil {
+		return err
+	}
+
+	return checkOneOf(field, opts)
+}
+
+// checkLen makes sure the length of the string, slice or map in the field
+// lies within the len tag option, either an exact length such as 5 or
+// a range such as 1..10, 1.. or ..10.
+func checkLen(field reflect.Value, bounds string) error {
+	if bounds == "" {
+		return nil
+	}
+
+	for field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+
+	switch field.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+	default:
+		return fmt.Errorf("len tag option is supported for strings, slices and maps only, not %q", field.Type())
+	}
+
+	minLen, maxLen, isRange := strings.Cut(bounds, "..")
+	if !isRange {
+		maxLen = minLen
+	}
+
+	n := field.Len()
+
+	if minLen != "" {
+		limit, err := strconv.Atoi(minLen)
+		if err != nil {
+			return fmt.Errorf("invalid len tag option %q: %w", bounds, err)
+		}
+
+		if n < limit {
+			return fmt.Errorf("length %d is out of bounds, min is %d", n, limit)
+		}
+	}
+
+	if maxLen != "" {
+		limit, err := strconv.Atoi(maxLen)
+		if err != nil {
+			return fmt.Errorf("invalid len tag option %q: %w", bounds, err)
+		}
+
+		if n > limit {
+			return fmt.Errorf("length %d is out of bounds, max is %d", n, limit)
+		}
+	}
+
+	return nil
+}
+
+// checkBounds makes sure the number or duration in the field lies within
+// the min and max tag options. For slices and maps every element is checked.
+func checkBounds(field reflect.Value, opts FieldOptions) error {
+	if opts.Min == "" && opts.Max == "" {
+		return nil
+	}
+
+	for field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+
+	switch field.Kind() {
+	case reflect.Slice:
+		for i := 0; i < field.Len(); i++ {
+			if err := checkBounds(field.Index(i), opts); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+
+		return nil
+	case reflect.Map:
+		keys := field.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+
+		for _, key := range keys {
+			if err := checkBounds(field.MapIndex(key), opts); err != nil {
+				return fmt.Errorf("key %v: %w", key.Interface(), err)
+			}
+		}
+
+		return nil
+	}
+
+	if opts.Min != "" {
+		if err := checkBound(field, opts.Min, "min", -1); err != nil {
+			return err
+		}
+	}
+
+	if opts.Max != "" {
+		if err := checkBound(field, opts.Max, "max", 1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkBound fails when the value compares to the bound as outside,
+// -1 for a min bound and 1 for a max bound. The bound is converted
+// like a value of the field, so durations take units.
+func checkBound(field reflect.Value, bound, name string, outside int) error {
+	b := reflect.New(field.Type()).Elem()
+	if err := processField(false, bound, b, FieldOptions{}); err != nil {
+		return fmt.Errorf("invalid %s tag option %q: %w", name, bound, err)
+	}
+
+	var cmp int
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		cmp = compare(field.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		cmp = compare(field.Uint(), b.Uint())
+	case reflect.Float32, reflect.Float64:
+		cmp = compare(field.Float(), b.Float())
+	default:
+		return fmt.Errorf("%s tag option is supported for numbers and durations only, not %q", name, field.Type())
+	}
+
+	if cmp == outside {
+		return fmt.Errorf("value %v is out of bounds, %s is %v", field.Interface(), name, b.Interface())
+	}
+
+	return nil
+}
+
+func compare[T int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// checkOneOf makes sure the string in the field is one of the oneof tag
+// option. For slices every element and for maps every key is checked.
+func checkOneOf(field reflect.Value, opts FieldOptions) error {
+	if len(opts.OneOf) == 0 {
+		return nil
+	}
+
+	for field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+
+	switch field.Kind() {
+	case reflect.Slice:
+		for i := 0; i < field.Len(); i++ {
+			if err := checkOneOf(field.Index(i), opts); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+
+		return nil
+	case reflect.Map:
+		keys := field.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+
+		for _, key := range keys {
+			if err := checkOneOf(key, opts); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case reflect.String:
+		for _, v := range opts.OneOf {
+			if field.String() == v {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("value %q is not one of %s", field.String(), strings.Join(opts.OneOf, "/"))
+	}
+
+	return fmt.Errorf("oneof tag option is supported for strings only, not %q", field.Type())
+}
@@ -0,0 +1,224 @@
+package conf
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fileSource provides values from a configuration file decoded into
+// a tree of nested maps, where nested keys map to nested struct fields.
+// Keys are matched ignoring case, underscores and dashes, so the field
+// IP.DebugHost is found under ip.debug_host as well as IP.debugHost.
+type fileSource struct {
+	path   string
+	format string
+	decode func(data []byte) (map[string]any, error)
+
+	mu   sync.RWMutex
+	tree map[string]any
+}
+
+func (s *fileSource) Source(fld Field) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var node any = s.tree
+	for _, name := range fld.Path {
+		node = childNode(node, name)
+		if node == nil {
+			return "", false
+		}
+	}
+
+	return formatNode(node, fld.Options), true
+}
+
+func (s *fileSource) Load() error {
+	return s.loadFS(nil)
+}
+
+func (s *fileSource) loadFS(fsys fs.FS) error {
+	data, err := readFile(fsys, s.path)
+	if err != nil {
+		return err
+	}
+
+	tree, err := s.decode(data)
+	if err != nil {
+		return fmt.Errorf("decode %s: %w", s.format, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tree = tree
+
+	return nil
+}
+
+func (s *fileSource) String() string {
+	return s.format + " file " + s.path
+}
+
+// readFile reads the file at the path from the file system set with WithFS,
+// or from the disk when none is set.
+func readFile(fsys fs.FS, path string) ([]byte, error) {
+	if fsys == nil {
+		return os.ReadFile(path)
+	}
+
+	name := strings.TrimPrefix(filepath.ToSlash(filepath.Clean(path)), "/")
+	if name == "" {
+		name = "."
+	}
+
+	return fs.ReadFile(fsys, name)
+}
+
+// configFileSource returns the source for the file passed with --config,
+// choosing the format by the file extension.
+func configFileSource(path string) Sourcer {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return jsonFileSource(path)
+	case ".toml":
+		return tomlFileSource(path)
+	default:
+		return yamlFileSource(path)
+	}
+}
+
+func (s *fileSource) elements(fsys fs.FS, path []string) []string {
+	data, err := readFile(fsys, s.path)
+	if err != nil {
+		return nil
+	}
+
+	// The file is decoded on its own as the elements are listed before
+	// the sources are loaded for the parse.
+	var node any
+	node, err = s.decode(data)
+	if err != nil {
+		return nil
+	}
+
+	for _, name := range path {
+		node = childNode(node, name)
+	}
+
+	var names []string
+	switch node := node.(type) {
+	case map[string]any:
+		for key := range node {
+			names = append(names, key)
+		}
+	case []any:
+		for i := range node {
+			names = append(names, strconv.Itoa(i))
+		}
+	}
+
+	return names
+}
+
+// childNode returns the child of the map node matching the field name,
+// or the item of the list node at the index.
+func childNode(node any, name string) any {
+	if list, ok := node.([]any); ok {
+		i, err := strconv.Atoi(name)
+		if err != nil || i < 0 || i >= len(list) {
+			return nil
+		}
+		return list[i]
+	}
+
+	m, ok := node.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	if child, ok := m[name]; ok {
+		return child
+	}
+
+	want := normalizeKey(name)
+	for key, child := range m {
+		if normalizeKey(key) == want {
+			return child
+		}
+	}
+
+	return nil
+}
+
+func normalizeKey(key string) string {
+	key = strings.ReplaceAll(key, "_", "")
+	key = strings.ReplaceAll(key, "-", "")
+	return strings.ToLower(key)
+}
+
+// formatNode renders a decoded value in the form accepted by processField.
+func formatNode(node any, opts FieldOptions) string {
+	switch node := node.(type) {
+	case string:
+		return node
+	case time.Time:
+		return node.Format(timeLayout(opts))
+	case []any:
+		items := make([]string, len(node))
+		for i, item := range node {
+			items[i] = formatNode(item, opts)
+		}
+		return strings.Join(items, opts.Sep)
+	case map[string]any:
+		items := make([]string, 0, len(node))
+		for k, v := range node {
+			items = append(items, k+opts.KVSep+formatNode(v, opts))
+		}
+		sort.Strings(items)
+		return strings.Join(items, opts.Sep)
+	}
+
+	if v := reflect.ValueOf(node); v.Kind() == reflect.Map {
+		items := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			items = append(items, fmt.Sprint(iter.Key().Interface())+opts.KVSep+formatNode(iter.Value().Interface(), opts))
+		}
+		sort.Strings(items)
+		return strings.Join(items, opts.Sep)
+	}
+
+	return fmt.Sprint(node)
+}
+
+// configFlag looks through the command line arguments for the path of
+// the config file passed with --config unless the flag is taken by a field.
+func configFlag(fields []Field, args []string) (string, bool) {
+	for _, field := range fields {
+		if field.FlagKey == "config" {
+			return "", false
+		}
+	}
+
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return "", false
+		case strings.HasPrefix(arg, "--config="):
+			return strings.TrimPrefix(arg, "--config="), true
+		case arg == "--config" && i+1 < len(args):
+			return args[i+1], true
+		}
+	}
+
+	return "", false
+}
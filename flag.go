@@ -0,0 +1,106 @@
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	// ErrHelpWanted is returned by Parse when -h or --help is passed,
+	// the usage can be printed with Usage.
+	ErrHelpWanted = errors.New("help wanted")
+
+	// ErrVersionWanted is returned by Parse when --version is passed and
+	// the config struct embeds a Version, which can be printed with
+	// VersionString.
+	ErrVersionWanted = errors.New("version wanted")
+)
+
+// checkHelp looks through the command line arguments for the help, version
+// and config sample flags unless they are taken by the fields. The version
+// flag is only looked for if the config struct is versioned.
+func checkHelp(fields []Field, args []string, versioned bool) error {
+	taken := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		taken["--"+field.FlagKey] = true
+		if field.ShortFlag != "" {
+			taken["-"+field.ShortFlag] = true
+		}
+	}
+
+	for _, arg := range args {
+		switch {
+		case arg == "--":
+			return nil
+		case (arg == "-h" && !taken["-h"]) || (arg == "--help" && !taken["--help"]):
+			return ErrHelpWanted
+		case arg == "--version" && versioned && !taken["--version"]:
+			return ErrVersionWanted
+		case (arg == "--config-sample" || strings.HasPrefix(arg, "--config-sample=")) && !taken["--config-sample"]:
+			return ErrSampleWanted
+		}
+	}
+
+	return nil
+}
+
+// parseFlags looks through the command line arguments for the flags of the
+// fields and returns their values keyed by the field env key. Long flags
+// are accepted as `--name value` or `--name=value` and short flags set with
+// the short tag as `-n value` or `-n=value`, boolean flags don't
+// require a value. Arguments which are not flags of the fields are ignored
+// and everything after the `--` terminator is left alone.
+func parseFlags(fields []Field, args []string) (map[string]string, error) {
+	byFlag := make(map[string]Field, len(fields))
+	for _, field := range fields {
+		byFlag["--"+field.FlagKey] = field
+		if field.ShortFlag != "" {
+			byFlag["-"+field.ShortFlag] = field
+		}
+	}
+
+	values := make(map[string]string)
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "--" {
+			break
+		}
+
+		name, value, hasValue := strings.Cut(arg, "=")
+
+		field, ok := byFlag[name]
+		if !ok {
+			continue
+		}
+
+		if !hasValue {
+			switch {
+			case isBoolField(field):
+				value = "true"
+			case i+1 < len(args):
+				i++
+				value = args[i]
+			default:
+				return nil, fmt.Errorf("flag %s is missing value", name)
+			}
+		}
+
+		values[field.EnvKey] = value
+	}
+
+	return values, nil
+}
+
+// isBoolField reports whether the field holds a bool or a pointer to bool.
+func isBoolField(field Field) bool {
+	typ := field.Field.Type()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	return typ.Kind() == reflect.Bool
+}
@@ -0,0 +1,56 @@
+package conf_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/virp/conf"
+	"github.com/virp/conf/conffuzz"
+)
+
+func FuzzParseTag(f *testing.F) {
+	for _, tag := range []string{
+		"",
+		"required",
+		"default:8080,help:port to listen on",
+		"env:API_KEY,mask",
+		"defaultfile:testdata/banner.txt",
+		"sep:comma,kvsep:=",
+		"min:1ms,max:1m,len:1..3",
+		"oneof:a|b|c,flag:debug,short:d",
+		"default:,",
+		"short:dd",
+	} {
+		f.Add(tag)
+	}
+
+	f.Fuzz(conffuzz.ParseTag)
+}
+
+func FuzzProcessField(f *testing.F) {
+	targets := []any{
+		new(string),
+		new(int8),
+		new(uint16),
+		new(float64),
+		new(bool),
+		new(time.Duration),
+		new(time.Time),
+		new([]int),
+		new([]string),
+		new(map[string]time.Duration),
+		new(map[string]string),
+		new(conf.Backoff),
+		new(conf.DSN),
+		new(conf.Weights),
+		new(conf.Labels),
+	}
+
+	for i, seed := range []string{"", "1", "-129", "true", "1.5h", "2024-03-01T10:30:00Z", "1;2;3", "a:1s;b:2m", "100ms..30s*2", "postgres://u:p@h/db", "a=70,b=30", "app=api", `"a;b";c`, `a\;b;c`, `"k:1":"v;2"`} {
+		f.Add(uint8(i), seed)
+	}
+
+	f.Fuzz(func(t *testing.T, target uint8, value string) {
+		conffuzz.ProcessField(t, targets[int(target)%len(targets)], value)
+	})
+}
@@ -0,0 +1,88 @@
+package conf
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Globs holds a list of glob patterns set from a value such as
+// *.go,docs/**/*.md, validated at parse time. Patterns use the syntax of
+// path.Match, a ** segment matches any number of directories.
+type Globs []string
+
+// Set implements the Setter interface.
+func (g *Globs) Set(data string) error {
+	var globs Globs
+
+	for _, pattern := range strings.Split(data, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+
+		for _, segment := range strings.Split(pattern, "/") {
+			if segment == "**" {
+				continue
+			}
+
+			if strings.Contains(segment, "**") {
+				return fmt.Errorf("invalid glob %q, ** must be a whole path segment", pattern)
+			}
+
+			if _, err := path.Match(segment, ""); err != nil {
+				return fmt.Errorf("invalid glob %q: %w", pattern, err)
+			}
+		}
+
+		globs = append(globs, pattern)
+	}
+
+	*g = globs
+
+	return nil
+}
+
+// String renders the patterns in the form accepted by Set.
+func (g Globs) String() string {
+	return strings.Join(g, ",")
+}
+
+// Match reports whether the slash separated name matches any of the patterns.
+func (g Globs) Match(name string) bool {
+	names := strings.Split(name, "/")
+
+	for _, pattern := range g {
+		if matchSegments(strings.Split(pattern, "/"), names) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchSegments matches the path segments against the pattern segments.
+func matchSegments(pattern, names []string) bool {
+	if len(pattern) == 0 {
+		return len(names) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(names); i++ {
+			if matchSegments(pattern[1:], names[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(names) == 0 {
+		return false
+	}
+
+	if ok, _ := path.Match(pattern[0], names[0]); !ok {
+		return false
+	}
+
+	return matchSegments(pattern[1:], names[1:])
+}
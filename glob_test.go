@@ -0,0 +1,52 @@
+package conf
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParse_Globs(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("TEST_INCLUDE", "*.go, docs/**/*.md,**/testdata/**")
+
+	var cfg struct {
+		Include Globs
+	}
+
+	if err := Parse("test", &cfg); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse globs : %s.", failed, err)
+	}
+	t.Logf("\t%s\tShould be able to parse globs.", success)
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"conf.go", true},
+		{"vault/vault.go", false},
+		{"docs/index.md", true},
+		{"docs/guide/setup/index.md", true},
+		{"docs/index.txt", false},
+		{"testdata/banner.txt", true},
+		{"vault/testdata/secret.json", true},
+	}
+
+	for _, tt := range tests {
+		if got := cfg.Include.Match(tt.name); got != tt.want {
+			t.Fatalf("\t%s\tShould match %s : %t, got %t.", failed, tt.name, tt.want, got)
+		}
+	}
+	t.Logf("\t%s\tShould match names against the globs.", success)
+
+	for _, env := range []string{"[a-", "docs/**.md"} {
+		os.Clearenv()
+		_ = os.Setenv("TEST_INCLUDE", env)
+
+		err := Parse("test", &cfg)
+		if err == nil || !strings.Contains(err.Error(), "invalid glob") {
+			t.Fatalf("\t%s\tShould fail for malformed glob %q : %v.", failed, env, err)
+		}
+		t.Logf("\t%s\tShould fail for malformed glob %q : %s.", success, env, err)
+	}
+}
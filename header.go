@@ -0,0 +1,57 @@
+package conf
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Header holds HTTP headers set from a value such as
+// X-Api-Key=secret,Accept=application/json. Keys are canonicalized and
+// repeated keys add values, Accept=text/html,Accept=text/plain sets two.
+type Header http.Header
+
+// Set implements the Setter interface.
+func (h *Header) Set(data string) error {
+	header := make(http.Header)
+
+	if strings.TrimSpace(data) != "" {
+		for _, item := range strings.Split(data, ",") {
+			key, value, ok := strings.Cut(item, "=")
+			key = strings.TrimSpace(key)
+			if !ok || key == "" {
+				return fmt.Errorf("invalid header %q, expected Key=Value", item)
+			}
+
+			header.Add(key, strings.TrimSpace(value))
+		}
+	}
+
+	*h = Header(header)
+
+	return nil
+}
+
+// String renders the headers in the form accepted by Set, sorted by key.
+func (h Header) String() string {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var items []string
+	for _, key := range keys {
+		for _, value := range h[key] {
+			items = append(items, key+"="+value)
+		}
+	}
+
+	return strings.Join(items, ",")
+}
+
+// HTTP returns the headers as http.Header.
+func (h Header) HTTP() http.Header {
+	return http.Header(h)
+}
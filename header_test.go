@@ -0,0 +1,46 @@
+package conf
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse_Header(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("TEST_HEADERS", "x-api-key=secret, accept=text/html,Accept=text/plain")
+
+	var cfg struct {
+		Headers Header
+	}
+
+	if err := Parse("test", &cfg); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse headers : %s.", failed, err)
+	}
+
+	want := http.Header{
+		"X-Api-Key": {"secret"},
+		"Accept":    {"text/html", "text/plain"},
+	}
+	if diff := cmp.Diff(want, cfg.Headers.HTTP()); diff != "" {
+		t.Fatalf("\t%s\tShould have canonicalized keys and kept repeated values\n%s", failed, diff)
+	}
+	t.Logf("\t%s\tShould have canonicalized keys and kept repeated values.", success)
+
+	if got := cfg.Headers.String(); got != "Accept=text/html,Accept=text/plain,X-Api-Key=secret" {
+		t.Fatalf("\t%s\tShould render headers in the form accepted, got %s.", failed, got)
+	}
+	t.Logf("\t%s\tShould render headers in the form accepted.", success)
+
+	os.Clearenv()
+	_ = os.Setenv("TEST_HEADERS", "Accept")
+
+	err := Parse("test", &cfg)
+	if err == nil || !strings.Contains(err.Error(), "expected Key=Value") {
+		t.Fatalf("\t%s\tShould fail for header without value : %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould fail for header without value : %s.", success, err)
+}
@@ -0,0 +1,137 @@
+package conf
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Loader is implemented by sources fetching their values before the fields
+// are resolved, such as files and remote stores. Load is called on every
+// parse and its outcome is reported by SourcesHealth.
+type Loader interface {
+	Load() error
+}
+
+// ContextLoader is implemented by loaders doing I/O which can be aborted,
+// such as remote stores. LoadContext is called in place of Load with the
+// context of the parse, see WithContext.
+type ContextLoader interface {
+	LoadContext(ctx context.Context) error
+}
+
+// An fsLoader is implemented by loaders reading files, which are read from
+// the file system set with WithFS.
+type fsLoader interface {
+	loadFS(fsys fs.FS) error
+}
+
+// Cacher is implemented by loaders able to keep serving the values of their
+// last successful load. A failed load of such a source doesn't fail the parse
+// while Cached reports true.
+type Cacher interface {
+	Cached() bool
+}
+
+// SourceHealth describes the outcome of the last load of a source.
+type SourceHealth struct {
+	Name      string
+	LastFetch time.Time
+	LastError error
+	Cached    bool
+
+	// Duration is how long the last load took.
+	Duration time.Duration
+}
+
+// Ready reports whether the source provides values, either fetched
+// by the last load or cached from a previous one.
+func (h SourceHealth) Ready() bool {
+	return h.LastError == nil || h.Cached
+}
+
+var health = struct {
+	sync.Mutex
+	sources map[string]SourceHealth
+}{sources: make(map[string]SourceHealth)}
+
+// SourcesHealth reports the health of every loader used by a parse,
+// suitable for readiness probes of services depending on remote config.
+func SourcesHealth() []SourceHealth {
+	health.Lock()
+	defer health.Unlock()
+
+	out := make([]SourceHealth, 0, len(health.sources))
+	for _, h := range health.sources {
+		out = append(out, h)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+
+	return out
+}
+
+// loadSource loads the source if it's a Loader and records its health.
+func loadSource(src Sourcer, o *options) error {
+	if rs, ok := src.(*rewriteSource); ok {
+		src = rs.src
+	}
+
+	loader, ok := src.(Loader)
+	if !ok {
+		return nil
+	}
+
+	name := sourceName(src)
+	start := o.clock.Now()
+
+	var err error
+	if l, ok := src.(fsLoader); ok && o.fsys != nil {
+		err = l.loadFS(o.fsys)
+	} else if l, ok := src.(ContextLoader); ok {
+		err = l.LoadContext(o.ctx)
+	} else {
+		err = loader.Load()
+	}
+	elapsed := o.clock.Now().Sub(start)
+
+	health.Lock()
+	defer health.Unlock()
+
+	h := health.sources[name]
+	h.Name = name
+	h.LastError = err
+	h.Cached = false
+	h.Duration = elapsed
+
+	switch {
+	case err == nil:
+		h.LastFetch = o.clock.Now()
+	case isCached(src):
+		h.Cached = true
+		err = nil
+	default:
+		err = fmt.Errorf("load source %s: %w", name, err)
+	}
+
+	health.sources[name] = h
+
+	return err
+}
+
+func isCached(src Sourcer) bool {
+	c, ok := src.(Cacher)
+	return ok && c.Cached()
+}
+
+// sourceName identifies the source in health reports.
+func sourceName(src Sourcer) string {
+	if s, ok := src.(fmt.Stringer); ok {
+		return s.String()
+	}
+
+	return fmt.Sprintf("%T", src)
+}
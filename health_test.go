@@ -0,0 +1,89 @@
+package conf
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+// remoteSource provides support for testing a source fetching its values.
+type remoteSource struct {
+	name   string
+	values map[string]string
+	err    error
+	cached bool
+}
+
+// Source implements the Sourcer interface
+func (s *remoteSource) Source(fld Field) (string, bool) {
+	value, ok := s.values[fld.EnvKey]
+	return value, ok
+}
+
+// Load implements the Loader interface
+func (s *remoteSource) Load() error {
+	if s.err != nil {
+		return s.err
+	}
+	s.cached = true
+	return nil
+}
+
+// Cached implements the Cacher interface
+func (s *remoteSource) Cached() bool {
+	return s.cached
+}
+
+// String implements the Stringer interface
+func (s *remoteSource) String() string {
+	return s.name
+}
+
+func TestSourcesHealth(t *testing.T) {
+	os.Clearenv()
+
+	src := &remoteSource{name: "remote-health", values: map[string]string{"TEST_PORT": "80"}}
+
+	var cfg struct {
+		Port int
+	}
+
+	find := func() SourceHealth {
+		for _, h := range SourcesHealth() {
+			if h.Name == src.name {
+				return h
+			}
+		}
+		t.Fatalf("\t%s\tShould report health of source %s.", failed, src.name)
+		return SourceHealth{}
+	}
+
+	if err := Parse("test", &cfg, WithSources(src)); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse from loaded source : %s.", failed, err)
+	}
+
+	if h := find(); !h.Ready() || h.LastFetch.IsZero() || h.Cached {
+		t.Fatalf("\t%s\tShould report healthy source : %+v.", failed, h)
+	}
+	t.Logf("\t%s\tShould report healthy source.", success)
+
+	src.err = errors.New("connection refused")
+	if err := Parse("test", &cfg, WithSources(src)); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse from cached source : %s.", failed, err)
+	}
+
+	if h := find(); !h.Ready() || !h.Cached || !errors.Is(h.LastError, src.err) {
+		t.Fatalf("\t%s\tShould report source serving cache : %+v.", failed, h)
+	}
+	t.Logf("\t%s\tShould report source serving cache.", success)
+
+	src.cached = false
+	if err := Parse("test", &cfg, WithSources(src)); !errors.Is(err, src.err) {
+		t.Fatalf("\t%s\tShould fail for source failing to load : %v.", failed, err)
+	}
+
+	if h := find(); h.Ready() {
+		t.Fatalf("\t%s\tShould report unhealthy source : %+v.", failed, h)
+	}
+	t.Logf("\t%s\tShould report unhealthy source.", success)
+}
@@ -0,0 +1,71 @@
+package conf
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"os"
+	"testing"
+)
+
+func TestParse_IP(t *testing.T) {
+	os.Clearenv()
+
+	type config struct {
+		Bind      net.IP `conf:"default:0.0.0.0"`
+		Subnet    net.IPNet
+		Gateway   *net.IPNet
+		Peer      netip.Addr
+		Range     netip.Prefix
+		Allowlist []net.IPNet  `conf:"sep:comma"`
+		Trusted   []netip.Addr `conf:"sep:comma"`
+	}
+
+	env := map[string]string{
+		"TEST_SUBNET":    "10.1.2.3/8",
+		"TEST_GATEWAY":   "fd00::/8",
+		"TEST_PEER":      "192.168.0.1",
+		"TEST_RANGE":     "192.168.0.0/16",
+		"TEST_ALLOWLIST": "10.0.0.0/8,172.16.0.0/12",
+		"TEST_TRUSTED":   "127.0.0.1,::1",
+	}
+
+	var cfg config
+	if err := Parse("test", &cfg, WithEnviron(env), WithArgs(nil)); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse addresses : %s.", failed, err)
+	}
+
+	switch {
+	case !cfg.Bind.Equal(net.IPv4zero),
+		cfg.Subnet.String() != "10.0.0.0/8",
+		cfg.Gateway.String() != "fd00::/8",
+		cfg.Peer != netip.MustParseAddr("192.168.0.1"),
+		cfg.Range != netip.MustParsePrefix("192.168.0.0/16"),
+		len(cfg.Allowlist) != 2 || !cfg.Allowlist[1].Contains(net.ParseIP("172.20.0.1")),
+		len(cfg.Trusted) != 2 || !cfg.Trusted[1].IsLoopback():
+		t.Fatalf("\t%s\tShould set the addresses : %+v.", failed, cfg)
+	}
+	t.Logf("\t%s\tShould set the addresses.", success)
+
+	out, err := String(&cfg)
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to render the config : %s.", failed, err)
+	}
+
+	want := "BIND=0.0.0.0\nSUBNET=10.0.0.0/8\nGATEWAY=fd00::/8\nPEER=192.168.0.1\nRANGE=192.168.0.0/16\nALLOWLIST=10.0.0.0/8,172.16.0.0/12\nTRUSTED=127.0.0.1,::1\n"
+	if out != want {
+		t.Fatalf("\t%s\tShould render the addresses :\n%s", failed, out)
+	}
+	t.Logf("\t%s\tShould render the addresses.", success)
+
+	for key, value := range map[string]string{"TEST_BIND": "10.0.0", "TEST_SUBNET": "10.0.0.0", "TEST_PEER": "localhost", "TEST_ALLOWLIST": "10.0.0.0/8,10.0.0.0/33"} {
+		var cfg config
+		err := Parse("test", &cfg, WithEnviron(map[string]string{key: value}), WithArgs(nil))
+
+		var fieldErr *FieldError
+		if !errors.As(err, &fieldErr) {
+			t.Fatalf("\t%s\tShould reject %s=%s : %v.", failed, key, value, err)
+		}
+		t.Logf("\t%s\tShould reject %s=%s : %s.", success, key, value, err)
+	}
+}
@@ -0,0 +1,29 @@
+package conf
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// WithJsonFile reads values from the JSON file at path, merged below the
+// sources which take precedence. Nested objects map to nested struct fields,
+// e.g. {"ip": {"name": ...}} sets IP.Name. The file is read on every parse.
+func WithJsonFile(path string) Option {
+	return withFile(jsonFileSource(path))
+}
+
+func jsonFileSource(path string) *fileSource {
+	return &fileSource{path: path, format: "json", decode: decodeJson}
+}
+
+func decodeJson(data []byte) (map[string]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	tree := make(map[string]any)
+	if err := dec.Decode(&tree); err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
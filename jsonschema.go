@@ -0,0 +1,227 @@
+package conf
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONSchema returns a JSON Schema (draft 2020-12) of the config files read
+// with WithYamlFile, WithJsonFile and WithTomlFile for the specified config
+// struct, for validating config files in CI and autocompletion in editors.
+// The properties are the snake_case names of the fields, nested structs are
+// nested objects and slices and maps of structs arrays and objects of them.
+// The schema carries the types, the defaults, the required fields, the
+// bounds of min, max and oneof and the help as descriptions. Fields which
+// may be set from the environment instead are still required by it.
+func JSONSchema(cfg any) ([]byte, error) {
+	o := newOptions(nil)
+
+	schema, err := objectSchema(cfg, o)
+	if err != nil {
+		return nil, err
+	}
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// objectSchema returns the schema of the object holding the fields of the
+// config struct.
+func objectSchema(cfg any, o *options) (map[string]any, error) {
+	fields, err := extractFields("", o.separator, nil, nil, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("extract fields from config struct: %w", err)
+	}
+
+	if err := bindOptions(fields, o); err != nil {
+		return nil, err
+	}
+
+	root := map[string]any{"type": "object", "properties": map[string]any{}}
+
+	for _, field := range fields {
+		obj := root
+		for _, name := range field.Path[:len(field.Path)-1] {
+			props := obj["properties"].(map[string]any)
+
+			key := propertyName(name)
+			child, ok := props[key].(map[string]any)
+			if !ok {
+				child = map[string]any{"type": "object", "properties": map[string]any{}}
+				props[key] = child
+			}
+			obj = child
+		}
+
+		prop, err := fieldSchema(field, o)
+		if err != nil {
+			return nil, err
+		}
+
+		key := propertyName(field.Path[len(field.Path)-1])
+		obj["properties"].(map[string]any)[key] = prop
+
+		if field.Options.Required {
+			required, _ := obj["required"].([]string)
+			obj["required"] = append(required, key)
+		}
+	}
+
+	return root, nil
+}
+
+// fieldSchema returns the schema of the value of the field.
+func fieldSchema(field Field, o *options) (map[string]any, error) {
+	typ := field.Field.Type()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	var prop map[string]any
+	switch {
+	case isStructSlice(typ), isStructMap(typ):
+		elem, err := objectSchema(reflect.New(structElem(typ)).Interface(), o)
+		if err != nil {
+			return nil, err
+		}
+
+		prop = map[string]any{"type": "array", "items": elem}
+		if typ.Kind() == reflect.Map {
+			prop = map[string]any{"type": "object", "additionalProperties": elem}
+		}
+	default:
+		prop = valueSchema(typ, field.Options)
+	}
+
+	if field.Options.Help != "" {
+		prop["description"] = field.Options.Help
+	}
+
+	if def := field.Options.DefaultVal; def != "" {
+		prop["default"] = defaultValue(typ, def, field.Options)
+	}
+
+	if field.Options.Deprecated != "" || field.Options.RemovedIn != "" {
+		prop["deprecated"] = true
+	}
+
+	if field.Options.Mask || field.Options.Secret {
+		prop["writeOnly"] = true
+	}
+
+	return prop, nil
+}
+
+// valueSchema returns the schema of values of the type, values converting
+// themselves are strings.
+func valueSchema(typ reflect.Type, opts FieldOptions) map[string]any {
+	v := reflect.New(typ).Elem()
+
+	switch {
+	case typ == timeType:
+		prop := map[string]any{"type": "string"}
+		if opts.Layout == "" {
+			prop["format"] = "date-time"
+		}
+		return prop
+	case typ == urlType:
+		return map[string]any{"type": "string", "format": "uri"}
+	case typ == reflect.TypeOf(time.Duration(0)):
+		return map[string]any{"type": "string"}
+	case typ == ipNetType, convertsItself(typ):
+		prop := map[string]any{"type": "string"}
+		if e := enumFrom(v); e != nil {
+			prop["enum"] = e.Values()
+		}
+		if len(opts.OneOf) > 0 {
+			prop["enum"] = opts.OneOf
+		}
+		return prop
+	}
+
+	var prop map[string]any
+	switch typ.Kind() {
+	case reflect.Bool:
+		prop = map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		prop = map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		prop = map[string]any{"type": "number"}
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string"}
+		}
+
+		items := valueSchema(typ.Elem(), opts)
+		return map[string]any{"type": "array", "items": items}
+	case reflect.Map:
+		prop = map[string]any{"type": "object", "additionalProperties": valueSchema(typ.Elem(), FieldOptions{Min: opts.Min, Max: opts.Max})}
+		if len(opts.OneOf) > 0 {
+			prop["propertyNames"] = map[string]any{"enum": opts.OneOf}
+		}
+		return prop
+	default:
+		prop = map[string]any{"type": "string"}
+		if len(opts.OneOf) > 0 {
+			prop["enum"] = opts.OneOf
+		}
+		return prop
+	}
+
+	if n, err := strconv.ParseFloat(opts.Min, 64); err == nil {
+		prop["minimum"] = n
+	}
+	if n, err := strconv.ParseFloat(opts.Max, 64); err == nil {
+		prop["maximum"] = n
+	}
+
+	return prop
+}
+
+// defaultValue returns the default of the field as the JSON value of its
+// schema, the default string itself if it doesn't convert.
+func defaultValue(typ reflect.Type, def string, opts FieldOptions) any {
+	schema := valueSchema(typ, opts)
+
+	switch schema["type"] {
+	case "boolean":
+		if b, err := strconv.ParseBool(def); err == nil {
+			return b
+		}
+	case "integer":
+		if n, err := strconv.ParseInt(def, 0, 64); err == nil {
+			return n
+		}
+		if n, err := strconv.ParseUint(def, 0, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(def, 64); err == nil {
+			return n
+		}
+	case "array":
+		items, err := splitList(def, opts.Sep, -1)
+		if err != nil {
+			return def
+		}
+
+		out := make([]any, len(items))
+		for i, item := range items {
+			out[i] = defaultValue(typ.Elem(), unquoteItem(item, opts.Sep), opts)
+		}
+		return out
+	}
+
+	return def
+}
+
+// propertyName returns the name of the field in config files, such as
+// debug_host for DebugHost.
+func propertyName(name string) string {
+	return strings.ToLower(strings.Join(camelSplit(name), "_"))
+}
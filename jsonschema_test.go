@@ -0,0 +1,97 @@
+package conf
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestJSONSchema(t *testing.T) {
+	os.Clearenv()
+
+	type backend struct {
+		Addr   string `conf:"required"`
+		Weight int    `conf:"default:1,min:1,max:100"`
+	}
+
+	type config struct {
+		DebugHost string        `conf:"default:0.0.0.0:4000,help:the debug listener"`
+		Level     string        `conf:"default:info,oneof:debug|info|warn"`
+		Timeout   time.Duration `conf:"default:5s"`
+		Verbose   bool          `conf:"default:true"`
+		Ratio     float64
+		Tags      []int  `conf:"default:1;2"`
+		Token     string `conf:"mask"`
+		Old       string `conf:"deprecated:use level"`
+		DB        struct {
+			User string `conf:"required"`
+		}
+		Backends []backend
+	}
+
+	data, err := JSONSchema(&config{})
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to export the JSON Schema : %s.", failed, err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("\t%s\tShould export valid JSON : %s.", failed, err)
+	}
+
+	want := map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type":    "object",
+		"properties": map[string]any{
+			"debug_host": map[string]any{"type": "string", "default": "0.0.0.0:4000", "description": "the debug listener"},
+			"level":      map[string]any{"type": "string", "default": "info", "enum": []any{"debug", "info", "warn"}},
+			"timeout":    map[string]any{"type": "string", "default": "5s"},
+			"verbose":    map[string]any{"type": "boolean", "default": true},
+			"ratio":      map[string]any{"type": "number"},
+			"tags":       map[string]any{"type": "array", "items": map[string]any{"type": "integer"}, "default": []any{1.0, 2.0}},
+			"token":      map[string]any{"type": "string", "writeOnly": true},
+			"old":        map[string]any{"type": "string", "deprecated": true},
+			"db": map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"user": map[string]any{"type": "string"}},
+				"required":   []any{"user"},
+			},
+			"backends": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"addr":   map[string]any{"type": "string"},
+						"weight": map[string]any{"type": "integer", "default": 1.0, "minimum": 1.0, "maximum": 100.0},
+					},
+					"required": []any{"addr"},
+				},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("\t%s\tShould export the types, defaults, bounds and required fields :\n%s", failed, diff)
+	}
+	t.Logf("\t%s\tShould export the types, defaults, bounds and required fields.", success)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	yaml := "debug_host: localhost:4000\ndb:\n  user: app\nbackends:\n  - addr: a:1\n"
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg config
+	if err := Parse("app", &cfg, WithYamlFile(path), WithEnviron(nil), WithArgs(nil)); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse a file following the schema : %s.", failed, err)
+	}
+
+	if cfg.DebugHost != "localhost:4000" || cfg.DB.User != "app" || len(cfg.Backends) != 1 || cfg.Backends[0].Addr != "a:1" {
+		t.Fatalf("\t%s\tShould read the properties of the schema from the file : %+v.", failed, cfg)
+	}
+	t.Logf("\t%s\tShould read the properties of the schema from the file.", success)
+}
@@ -0,0 +1,124 @@
+package conf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// WithConfigMap makes KubernetesEnv read the fields which aren't secrets
+// from the ConfigMap of the name, as rendered by KubernetesConfigMap.
+func WithConfigMap(name string) Option {
+	return func(o *options) {
+		o.configMap = name
+	}
+}
+
+// KubernetesEnv renders the env section of a Kubernetes container for the
+// specified config struct, ready to paste into a Deployment. Fields tagged
+// with `secret` or `mask` are read with secretKeyRef from the Secret named
+// after the prefix, under the secret name or the lowercase env key, other
+// fields are set to their defaults and preceded by their help. Fields
+// without a default are left as commented placeholders to be filled in, so
+// the application applies its own zero values. The prefix can't be empty,
+// it names the Secret.
+func KubernetesEnv(prefix string, cfg any, opts ...Option) (string, error) {
+	o := newOptions(opts)
+
+	secret, err := kubernetesName(prefix)
+	if err != nil {
+		return "", err
+	}
+
+	fields, err := Fields(prefix, cfg, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+
+	b.WriteString("env:\n")
+	for _, field := range fields {
+		if field.Options.Help != "" {
+			fmt.Fprintf(&b, "  # %s\n", strings.ReplaceAll(field.Options.Help, "\n", " "))
+		}
+
+		switch {
+		case field.Options.Secret || field.Options.Mask:
+			key := field.Options.SecretName
+			if key == "" {
+				key = strings.ToLower(field.EnvKey)
+			}
+			fmt.Fprintf(&b, "  - name: %s\n", field.EnvKey)
+			fmt.Fprintf(&b, "    valueFrom:\n      secretKeyRef:\n        name: %s\n        key: %s\n", secret, key)
+		case o.configMap != "":
+			fmt.Fprintf(&b, "  - name: %s\n", field.EnvKey)
+			fmt.Fprintf(&b, "    valueFrom:\n      configMapKeyRef:\n        name: %s\n        key: %s\n", o.configMap, field.EnvKey)
+			// The ConfigMap leaves the fields without a default out.
+			if field.Options.DefaultVal == "" {
+				b.WriteString("        optional: true\n")
+			}
+		case field.Options.DefaultVal == "":
+			fmt.Fprintf(&b, "  # - name: %s\n  #   value: \"\"%s\n", field.EnvKey, requiredNote(field))
+		default:
+			fmt.Fprintf(&b, "  - name: %s\n", field.EnvKey)
+			fmt.Fprintf(&b, "    value: %s\n", yamlString(field.Options.DefaultVal))
+		}
+	}
+
+	return b.String(), nil
+}
+
+// KubernetesConfigMap renders the Kubernetes ConfigMap of the name holding
+// the fields of the specified config struct which aren't secrets, set to
+// their defaults, to be read by the env section rendered by KubernetesEnv
+// with WithConfigMap. Fields without a default are left as commented
+// placeholders to be filled in.
+func KubernetesConfigMap(name, prefix string, cfg any, opts ...Option) (string, error) {
+	fields, err := Fields(prefix, cfg, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: %s\ndata:\n", name)
+	for _, field := range fields {
+		if field.Options.Secret || field.Options.Mask {
+			continue
+		}
+		if field.Options.DefaultVal == "" {
+			fmt.Fprintf(&b, "  # %s: \"\"%s\n", field.EnvKey, requiredNote(field))
+			continue
+		}
+		fmt.Fprintf(&b, "  %s: %s\n", field.EnvKey, yamlString(field.Options.DefaultVal))
+	}
+
+	return b.String(), nil
+}
+
+// kubernetesName returns the name of the Kubernetes object of the prefix,
+// such as my-service for my_service.
+func kubernetesName(prefix string) (string, error) {
+	if prefix == "" {
+		return "", errors.New("empty prefix, the Kubernetes objects are named after it")
+	}
+
+	return strings.ReplaceAll(strings.ToLower(prefix), "_", "-"), nil
+}
+
+// yamlString quotes the value as a YAML string.
+func yamlString(value string) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// requiredNote marks the placeholder of a required field to be filled in.
+func requiredNote(field Field) string {
+	if field.Options.Required {
+		return " # required"
+	}
+
+	return ""
+}
@@ -0,0 +1,109 @@
+package conf
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestKubernetesEnv(t *testing.T) {
+	os.Clearenv()
+
+	type config struct {
+		Host     string `conf:"required,help:the host to listen on"`
+		Port     int    `conf:"default:8080"`
+		Password string `conf:"required,secret:db_password"`
+		Token    string `conf:"mask"`
+	}
+
+	t.Run("env", func(t *testing.T) {
+		got, err := KubernetesEnv("my_service", &config{})
+		if err != nil {
+			t.Fatalf("\t%s\tShould be able to render the env section : %s.", failed, err)
+		}
+
+		want := `env:
+  # the host to listen on
+  # - name: MY_SERVICE_HOST
+  #   value: "" # required
+  - name: MY_SERVICE_PORT
+    value: "8080"
+  - name: MY_SERVICE_PASSWORD
+    valueFrom:
+      secretKeyRef:
+        name: my-service
+        key: db_password
+  - name: MY_SERVICE_TOKEN
+    valueFrom:
+      secretKeyRef:
+        name: my-service
+        key: my_service_token
+`
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("\t%s\tShould render the values and the secret references :\n%s", failed, diff)
+		}
+		t.Logf("\t%s\tShould render the values and the secret references.", success)
+	})
+
+	t.Run("config-map", func(t *testing.T) {
+		got, err := KubernetesEnv("my_service", &config{}, WithConfigMap("my-service-config"))
+		if err != nil {
+			t.Fatalf("\t%s\tShould be able to render the env section : %s.", failed, err)
+		}
+
+		want := `env:
+  # the host to listen on
+  - name: MY_SERVICE_HOST
+    valueFrom:
+      configMapKeyRef:
+        name: my-service-config
+        key: MY_SERVICE_HOST
+        optional: true
+  - name: MY_SERVICE_PORT
+    valueFrom:
+      configMapKeyRef:
+        name: my-service-config
+        key: MY_SERVICE_PORT
+  - name: MY_SERVICE_PASSWORD
+    valueFrom:
+      secretKeyRef:
+        name: my-service
+        key: db_password
+  - name: MY_SERVICE_TOKEN
+    valueFrom:
+      secretKeyRef:
+        name: my-service
+        key: my_service_token
+`
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("\t%s\tShould reference the ConfigMap :\n%s", failed, diff)
+		}
+		t.Logf("\t%s\tShould reference the ConfigMap.", success)
+
+		got, err = KubernetesConfigMap("my-service-config", "my_service", &config{})
+		if err != nil {
+			t.Fatalf("\t%s\tShould be able to render the ConfigMap : %s.", failed, err)
+		}
+
+		want = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-service-config
+data:
+  # MY_SERVICE_HOST: "" # required
+  MY_SERVICE_PORT: "8080"
+`
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("\t%s\tShould render the ConfigMap without the secrets :\n%s", failed, diff)
+		}
+		t.Logf("\t%s\tShould render the ConfigMap without the secrets.", success)
+	})
+
+	t.Run("empty-prefix", func(t *testing.T) {
+		if _, err := KubernetesEnv("", &config{}); err == nil {
+			t.Fatalf("\t%s\tShould reject an empty prefix naming the Secret.", failed)
+		}
+		t.Logf("\t%s\tShould reject an empty prefix naming the Secret.", success)
+	})
+}
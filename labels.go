@@ -0,0 +1,95 @@
+package conf
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	labelNameRe   = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+	labelPrefixRe = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+)
+
+// Labels holds Kubernetes-style labels set from a value such as
+// app=api,team=core. Keys are an optional DNS subdomain prefix of up to
+// 253 characters followed by a slash and a name of up to 63 characters,
+// e.g. example.com/tier. Values have up to 63 characters. The number of
+// labels can be limited with the len tag option.
+type Labels map[string]string
+
+// Set implements the Setter interface.
+func (l *Labels) Set(data string) error {
+	labels := make(Labels)
+
+	if strings.TrimSpace(data) != "" {
+		for _, item := range strings.Split(data, ",") {
+			key, value, ok := strings.Cut(item, "=")
+			if !ok {
+				return fmt.Errorf("invalid label %q, expected key=value", item)
+			}
+
+			key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+			if err := checkLabelKey(key); err != nil {
+				return err
+			}
+
+			if err := checkLabelValue(value); err != nil {
+				return fmt.Errorf("label %s: %w", key, err)
+			}
+
+			if _, dup := labels[key]; dup {
+				return fmt.Errorf("duplicate label %s", key)
+			}
+			labels[key] = value
+		}
+	}
+
+	*l = labels
+
+	return nil
+}
+
+// String renders the labels in the form accepted by Set, sorted by key,
+// which is also an equality-based label selector.
+func (l Labels) String() string {
+	items := make([]string, 0, len(l))
+	for key, value := range l {
+		items = append(items, key+"="+value)
+	}
+	sort.Strings(items)
+
+	return strings.Join(items, ",")
+}
+
+// checkLabelKey validates the syntax of the label key.
+func checkLabelKey(key string) error {
+	name := key
+	if prefix, n, ok := strings.Cut(key, "/"); ok {
+		if len(prefix) > 253 || !labelPrefixRe.MatchString(prefix) {
+			return fmt.Errorf("invalid label key %q, prefix must be a DNS subdomain of up to 253 characters", key)
+		}
+		name = n
+	}
+
+	if len(name) > 63 || !labelNameRe.MatchString(name) {
+		return fmt.Errorf("invalid label key %q, name must be up to 63 alphanumeric characters, '-', '_' or '.' starting and ending with an alphanumeric character", key)
+	}
+
+	return nil
+}
+
+// checkLabelValue validates the syntax of the label value, which may be empty.
+func checkLabelValue(value string) error {
+	if value == "" {
+		return nil
+	}
+
+	if len(value) > 63 || !labelNameRe.MatchString(value) {
+		return fmt.Errorf("invalid label value %q, must be up to 63 alphanumeric characters, '-', '_' or '.' starting and ending with an alphanumeric character", value)
+	}
+
+	return nil
+}
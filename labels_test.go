@@ -0,0 +1,60 @@
+package conf
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse_Labels(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("TEST_SELECTOR", "app=api, example.com/tier=backend,canary=")
+
+	var cfg struct {
+		Selector Labels `conf:"len:1..3"`
+	}
+
+	if err := Parse("test", &cfg); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse labels : %s.", failed, err)
+	}
+
+	want := Labels{"app": "api", "example.com/tier": "backend", "canary": ""}
+	if diff := cmp.Diff(want, cfg.Selector); diff != "" {
+		t.Fatalf("\t%s\tShould have parsed labels\n%s", failed, diff)
+	}
+	t.Logf("\t%s\tShould have parsed labels.", success)
+
+	if got := cfg.Selector.String(); got != "app=api,canary=,example.com/tier=backend" {
+		t.Fatalf("\t%s\tShould render labels as selector, got %s.", failed, got)
+	}
+	t.Logf("\t%s\tShould render labels as selector.", success)
+
+	tests := []struct {
+		name string
+		env  string
+		want string
+	}{
+		{"no-value", "app", "expected key=value"},
+		{"bad-name", "-app=api", `invalid label key "-app"`},
+		{"long-name", strings.Repeat("a", 64) + "=api", "name must be up to 63"},
+		{"bad-prefix", "Example.com/tier=backend", "prefix must be a DNS subdomain"},
+		{"bad-value", "app=api server", `label app: invalid label value "api server"`},
+		{"duplicate", "app=api,app=web", "duplicate label app"},
+		{"too-many", "a=1,b=2,c=3,d=4", "length 4 is out of bounds, max is 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			_ = os.Setenv("TEST_SELECTOR", tt.env)
+
+			err := Parse("test", &cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("\t%s\tShould fail for %q with %q : %v.", failed, tt.env, tt.want, err)
+			}
+			t.Logf("\t%s\tShould fail for %q : %s.", success, tt.env, err)
+		})
+	}
+}
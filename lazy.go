@@ -0,0 +1,106 @@
+package conf
+
+import (
+	"reflect"
+	"strings"
+)
+
+// A LazyValue holds the value of a field tagged with `required:lazy`. Such
+// fields don't fail the parse when missing, so optional subsystems can be
+// configured once they are activated, and fail loudly on the first access
+// through Get instead:
+//
+//	type Config struct {
+//		PaymentsKey conf.LazyValue[string] `conf:"required:lazy"`
+//	}
+//
+//	key := cfg.PaymentsKey.Get() // panics if MY_SERVICE_PAYMENTS_KEY is missing
+//
+// The value is converted like a field of type T with the tag options of the
+// field. The state is kept in the field, so copies of the config struct
+// keep failing as well.
+type LazyValue[T any] struct {
+	value T
+	err   error
+}
+
+// NewLazyValue returns the lazy value holding the value, or failing Get
+// with err if it isn't nil, for code setting config structs itself, such
+// as the code generated by confgen.
+func NewLazyValue[T any](value T, err error) LazyValue[T] {
+	return LazyValue[T]{value: value, err: err}
+}
+
+// Get returns the value, and panics with the RequiredError naming the env
+// key if the field was missing a value in the parse.
+func (l LazyValue[T]) Get() T {
+	if l.err != nil {
+		panic(l.err)
+	}
+
+	return l.value
+}
+
+// Err returns the RequiredError of the field if it was missing a value in
+// the parse, for checking it without a panic.
+func (l LazyValue[T]) Err() error {
+	return l.err
+}
+
+func (l *LazyValue[T]) lazyTarget() any {
+	return &l.value
+}
+
+func (l *LazyValue[T]) setMissing(err error) {
+	l.err = err
+}
+
+// lazyField is implemented by LazyValue.
+type lazyField interface {
+	lazyTarget() any
+	setMissing(err error)
+}
+
+// lazyType is the type of lazyField.
+var lazyType = reflect.TypeOf((*lazyField)(nil)).Elem()
+
+// isLazy reports whether the type is a LazyValue.
+func isLazy(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && reflect.PointerTo(typ).Implements(lazyType)
+}
+
+// lazyTarget returns the value held by the LazyValue to be converted and
+// rendered in its place, other values are returned as they are.
+func lazyTarget(v reflect.Value) reflect.Value {
+	if !isLazy(v.Type()) || !v.CanAddr() {
+		return v
+	}
+
+	return reflect.ValueOf(v.Addr().Interface().(lazyField).lazyTarget()).Elem()
+}
+
+// lazyValue sets the error of the field tagged with `required:lazy` into
+// the LazyValue if it's missing a value, for Get to report, and clears it
+// otherwise.
+func lazyValue(field Field, envValues map[string]string, hint string) {
+	if !field.Field.CanAddr() {
+		return
+	}
+
+	l, ok := field.Field.Addr().Interface().(lazyField)
+	if !ok {
+		return
+	}
+
+	if _, ok := envValues[field.EnvKey]; ok {
+		l.setMissing(nil)
+		return
+	}
+
+	l.setMissing(&RequiredError{
+		Field:   strings.Join(field.Path, "."),
+		EnvKey:  field.EnvKey,
+		FlagKey: field.FlagKey,
+		found:   hint,
+	})
+}
@@ -0,0 +1,94 @@
+package conf
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLazyValue(t *testing.T) {
+	os.Clearenv()
+
+	type payments struct {
+		Key     LazyValue[string]        `conf:"required:lazy"`
+		Region  LazyValue[string]        `conf:"required:lazy"`
+		Timeout LazyValue[time.Duration] `conf:"required:lazy"`
+		Hosts   LazyValue[[]string]      `conf:"required:lazy,sep:comma"`
+	}
+
+	type config struct {
+		Host     string
+		Payments payments
+	}
+
+	env := map[string]string{"APP_PAYMENTS_REGION": "eu", "APP_PAYMENTS_TIMEOUT": "5s", "APP_PAYMENTS_HOSTS": "a,b"}
+
+	var cfg config
+	if err := Parse("app", &cfg, WithEnviron(env), WithArgs(nil)); err != nil {
+		t.Fatalf("\t%s\tShould parse with lazy required fields missing : %s.", failed, err)
+	}
+	t.Logf("\t%s\tShould parse with lazy required fields missing.", success)
+
+	if cfg.Payments.Region.Get() != "eu" || cfg.Payments.Timeout.Get() != 5*time.Second || len(cfg.Payments.Hosts.Get()) != 2 {
+		t.Fatalf("\t%s\tShould return the values of lazy fields set : %+v.", failed, cfg.Payments)
+	}
+	t.Logf("\t%s\tShould return the values of lazy fields set.", success)
+
+	// A copy, as returned by ParseFor, keeps failing.
+	copied := cfg
+
+	func() {
+		defer func() {
+			err, _ := recover().(error)
+
+			var re *RequiredError
+			if !errors.As(err, &re) || !strings.Contains(err.Error(), "APP_PAYMENTS_KEY") {
+				t.Fatalf("\t%s\tShould panic naming the env key of the missing field : %v.", failed, err)
+			}
+			t.Logf("\t%s\tShould panic naming the env key of the missing field.", success)
+		}()
+
+		copied.Payments.Key.Get()
+	}()
+
+	if err := cfg.Payments.Key.Err(); err == nil {
+		t.Fatalf("\t%s\tShould report the error of the missing field.", failed)
+	}
+	t.Logf("\t%s\tShould report the error of the missing field.", success)
+
+	env["APP_PAYMENTS_KEY"] = "k3y"
+	if err := Parse("app", &cfg, WithEnviron(env), WithArgs(nil)); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse again : %s.", failed, err)
+	}
+
+	if key := cfg.Payments.Key.Get(); key != "k3y" {
+		t.Fatalf("\t%s\tShould return the field once set by a later parse : %q.", failed, key)
+	}
+	t.Logf("\t%s\tShould return the field once set by a later parse.", success)
+
+	out, err := String(&cfg)
+	if err != nil || !strings.Contains(out, "PAYMENTS_TIMEOUT=5s") {
+		t.Fatalf("\t%s\tShould print the values of lazy fields : %v\n%s", failed, err, out)
+	}
+	t.Logf("\t%s\tShould print the values of lazy fields.", success)
+
+	type invalid struct {
+		Key LazyValue[string] `conf:"required:lazy,default:x"`
+	}
+
+	if err := Parse("app", &invalid{}, WithEnviron(nil), WithArgs(nil)); err == nil {
+		t.Fatalf("\t%s\tShould reject a default on a lazy required field.", failed)
+	}
+	t.Logf("\t%s\tShould reject a default on a lazy required field.", success)
+
+	type plain struct {
+		Key string `conf:"required:lazy"`
+	}
+
+	if err := Parse("app", &plain{}, WithEnviron(nil), WithArgs(nil)); err == nil || !strings.Contains(err.Error(), "conf.LazyValue") {
+		t.Fatalf("\t%s\tShould reject a lazy required field of another type : %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould reject a lazy required field of another type.", success)
+}
@@ -0,0 +1,152 @@
+package conf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Warning reports a setting which was accepted but needs the attention
+// of operators, such as a field due for removal.
+type Warning struct {
+	Key     string `json:"key"`
+	Message string `json:"message"`
+}
+
+func (w Warning) String() string {
+	return w.Key + ": " + w.Message
+}
+
+// checkRemovals reports the fields tagged with `removed_in` which received
+// a value. Once the running version reaches the version of the removal the
+// field fails the parse, before that a warning is reported. The running
+// version is set with WithVersion and defaults to the build of the Version
+// embedded in the config struct, without a known version only warnings are
+// reported.
+func checkRemovals(cfg any, fields []Field, values map[string]string, o *options) error {
+	running := o.version
+	if running == "" {
+		if v, ok := findVersion(cfg); ok {
+			running = v.Build
+		}
+	}
+	current, known := parseVersion(running)
+
+	for _, field := range fields {
+		if field.Options.RemovedIn == "" {
+			continue
+		}
+
+		if _, ok := values[field.EnvKey]; !ok {
+			continue
+		}
+
+		removal, _ := parseVersion(field.Options.RemovedIn)
+		if known && compareVersions(current, removal) >= 0 {
+			return fmt.Errorf("field %s (%s) was removed in %s, the running version is %s", field.Name, field.EnvKey, field.Options.RemovedIn, running)
+		}
+
+		o.warn(Warning{
+			Key:     field.EnvKey,
+			Message: fmt.Sprintf("field %s is set but will be removed in %s", field.Name, field.Options.RemovedIn),
+		})
+	}
+
+	return nil
+}
+
+// checkDeprecations reports the fields tagged with `deprecated` which
+// received a value, with the message of the tag telling operators what to
+// use instead.
+func checkDeprecations(fields []Field, values map[string]string, o *options) {
+	for _, field := range fields {
+		if field.Options.Deprecated == "" {
+			continue
+		}
+
+		if _, ok := values[field.EnvKey]; !ok {
+			continue
+		}
+
+		o.warn(Warning{
+			Key:     field.EnvKey,
+			Message: fmt.Sprintf("field %s is deprecated: %s", field.Name, field.Options.Deprecated),
+		})
+	}
+}
+
+// checkExpiries reports the fields tagged with `expires` once the date has
+// passed, whether they received a value or not, so the temporary settings
+// such as feature toggles they stand for get cleaned up. A warning is
+// reported unless WithExpiryErrors makes them fail the parse. The date
+// passes at its end in UTC.
+func checkExpiries(fields []Field, o *options) error {
+	now := o.clock.Now()
+
+	for _, field := range fields {
+		if field.Options.Expires == "" {
+			continue
+		}
+
+		date, err := time.Parse(time.DateOnly, field.Options.Expires)
+		if err != nil || now.Before(date.AddDate(0, 0, 1)) {
+			continue
+		}
+
+		if o.expiryErrors {
+			return fmt.Errorf("field %s (%s) expired on %s, remove it", field.Name, field.EnvKey, field.Options.Expires)
+		}
+
+		o.warn(Warning{
+			Key:     field.EnvKey,
+			Message: fmt.Sprintf("field %s expired on %s and should be removed", field.Name, field.Options.Expires),
+		})
+	}
+
+	return nil
+}
+
+// parseVersion parses versions such as v2, v2.1 or 2.1.3, the pre-release
+// and build metadata are ignored.
+func parseVersion(s string) ([]int, bool) {
+	s = strings.TrimPrefix(s, "v")
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+
+	if s == "" {
+		return nil, false
+	}
+
+	parts := strings.Split(s, ".")
+	version := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		version[i] = n
+	}
+
+	return version, true
+}
+
+// compareVersions compares the versions part by part, missing parts are 0.
+func compareVersions(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+
+		if x != y {
+			return compare(int64(x), int64(y))
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,201 @@
+package conf
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRemovedIn(t *testing.T) {
+	os.Clearenv()
+
+	type config struct {
+		Version
+		Host    string
+		OldHost string `conf:"removed_in:v2.0"`
+	}
+
+	tests := []struct {
+		name     string
+		version  string
+		build    string
+		env      map[string]string
+		warnings int
+		err      string
+	}{
+		{"unset", "v2.0", "", map[string]string{"TEST_HOST": "a"}, 0, ""},
+		{"before", "v1.9.3", "", map[string]string{"TEST_OLD_HOST": "a"}, 1, ""},
+		{"unknown", "", "dev", map[string]string{"TEST_OLD_HOST": "a"}, 1, ""},
+		{"reached", "v2.0.0", "", map[string]string{"TEST_OLD_HOST": "a"}, 0, "field OldHost (TEST_OLD_HOST) was removed in v2.0, the running version is v2.0.0"},
+		{"build", "", "v2.1.0-rc.1", map[string]string{"TEST_OLD_HOST": "a"}, 0, "was removed in v2.0"},
+	}
+
+	for _, tt := range tests {
+		var warnings []Warning
+		opts := []Option{
+			WithEnviron(tt.env),
+			WithArgs(nil),
+			WithWarnings(func(w Warning) { warnings = append(warnings, w) }),
+		}
+		if tt.version != "" {
+			opts = append(opts, WithVersion(tt.version))
+		}
+
+		cfg := config{Version: Version{Build: tt.build}}
+		err := Parse("test", &cfg, opts...)
+
+		if tt.err == "" && err != nil {
+			t.Fatalf("\t%s\tShould parse %s : %s.", failed, tt.name, err)
+		}
+		if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Fatalf("\t%s\tShould fail %s with %q : %v.", failed, tt.name, tt.err, err)
+		}
+		if len(warnings) != tt.warnings {
+			t.Fatalf("\t%s\tShould report %d warnings for %s : %v.", failed, tt.warnings, tt.name, warnings)
+		}
+		t.Logf("\t%s\tShould handle %s.", success, tt.name)
+	}
+
+	var bad struct {
+		Host string `conf:"removed_in:next"`
+	}
+	if err := Parse("test", &bad, WithEnviron(map[string]string{})); err == nil {
+		t.Fatalf("\t%s\tShould reject an invalid removed_in version.", failed)
+	}
+	t.Logf("\t%s\tShould reject an invalid removed_in version.", success)
+}
+
+func TestExpires(t *testing.T) {
+	os.Clearenv()
+
+	type config struct {
+		Host        string
+		NewCheckout bool `conf:"expires:2000-01-31"`
+		NewSearch   bool `conf:"expires:2999-12-31"`
+	}
+
+	var warnings []Warning
+	opts := []Option{
+		WithEnviron(map[string]string{}),
+		WithArgs(nil),
+		WithWarnings(func(w Warning) { warnings = append(warnings, w) }),
+	}
+
+	var cfg config
+	if err := Parse("test", &cfg, opts...); err != nil {
+		t.Fatalf("\t%s\tShould parse with an expired field : %s.", failed, err)
+	}
+
+	want := []Warning{{Key: "TEST_NEW_CHECKOUT", Message: "field NewCheckout expired on 2000-01-31 and should be removed"}}
+	if len(warnings) != 1 || warnings[0] != want[0] {
+		t.Fatalf("\t%s\tShould warn about the expired field : %v.", failed, warnings)
+	}
+	t.Logf("\t%s\tShould warn about the expired field.", success)
+
+	err := Parse("test", &cfg, append(opts, WithExpiryErrors())...)
+	if err == nil || err.Error() != "field NewCheckout (TEST_NEW_CHECKOUT) expired on 2000-01-31, remove it" {
+		t.Fatalf("\t%s\tShould fail on the expired field with WithExpiryErrors : %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould fail on the expired field with WithExpiryErrors.", success)
+
+	var bad struct {
+		Toggle bool `conf:"expires:31/12/2025"`
+	}
+	if err := Parse("test", &bad, WithEnviron(map[string]string{})); err == nil {
+		t.Fatalf("\t%s\tShould reject an invalid expires date.", failed)
+	}
+	t.Logf("\t%s\tShould reject an invalid expires date.", success)
+}
+
+func TestAlias(t *testing.T) {
+	os.Clearenv()
+
+	type config struct {
+		DBHost string `conf:"alias:TEST_DATABASE_HOST|DB_HOST"`
+		Port   int    `conf:"default:5432,alias:TEST_DB_PORT"`
+	}
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		host     string
+		warnings []Warning
+	}{
+		{"new", map[string]string{"TEST_DB_HOST": "new"}, "new", nil},
+		{"old", map[string]string{"TEST_DATABASE_HOST": "old"}, "old", []Warning{{Key: "TEST_DATABASE_HOST", Message: "deprecated name of TEST_DB_HOST, rename it"}}},
+		{"order", map[string]string{"TEST_DATABASE_HOST": "old", "DB_HOST": "older"}, "old", []Warning{{Key: "TEST_DATABASE_HOST", Message: "deprecated name of TEST_DB_HOST, rename it"}}},
+		{"both", map[string]string{"TEST_DB_HOST": "new", "DB_HOST": "old"}, "new", nil},
+	}
+
+	for _, tt := range tests {
+		var warnings []Warning
+		var cfg config
+		err := Parse("test", &cfg, WithEnviron(tt.env), WithArgs(nil), WithStrict(),
+			WithWarnings(func(w Warning) { warnings = append(warnings, w) }))
+		if err != nil {
+			t.Fatalf("\t%s\tShould parse %s : %s.", failed, tt.name, err)
+		}
+
+		if cfg.DBHost != tt.host || cfg.Port != 5432 {
+			t.Fatalf("\t%s\tShould set the field from %s : %+v.", failed, tt.name, cfg)
+		}
+
+		if len(warnings) != len(tt.warnings) || len(warnings) > 0 && warnings[0] != tt.warnings[0] {
+			t.Fatalf("\t%s\tShould warn about the old names for %s : %v.", failed, tt.name, warnings)
+		}
+		t.Logf("\t%s\tShould handle %s.", success, tt.name)
+	}
+
+	var clash struct {
+		Host    string
+		OldHost string `conf:"alias:TEST_HOST"`
+	}
+	if err := Parse("test", &clash, WithEnviron(map[string]string{})); err == nil {
+		t.Fatalf("\t%s\tShould reject an alias taken by another field.", failed)
+	}
+	t.Logf("\t%s\tShould reject an alias taken by another field.", success)
+}
+
+func TestDeprecated(t *testing.T) {
+	os.Clearenv()
+
+	type config struct {
+		Host    string
+		Address string `conf:"deprecated:use TEST_HOST instead"`
+	}
+
+	var (
+		cfg      config
+		warnings []Warning
+	)
+	env := map[string]string{"TEST_ADDRESS": "a"}
+	err := Parse("test", &cfg, WithEnviron(env), WithArgs(nil), WithProvenance(), WithWarnings(func(w Warning) { warnings = append(warnings, w) }))
+	if err != nil {
+		t.Fatalf("\t%s\tShould parse a deprecated field : %s.", failed, err)
+	}
+
+	want := Warning{Key: "TEST_ADDRESS", Message: "field Address is deprecated: use TEST_HOST instead"}
+	if len(warnings) != 1 || warnings[0] != want {
+		t.Fatalf("\t%s\tShould warn about the deprecated field : %v.", failed, warnings)
+	}
+	t.Logf("\t%s\tShould warn about the deprecated field.", success)
+
+	if got := Warnings(&cfg); len(got) != 1 || got[0] != want {
+		t.Fatalf("\t%s\tShould keep the warnings of the parse : %v.", failed, got)
+	}
+	t.Logf("\t%s\tShould keep the warnings of the parse.", success)
+
+	d, err := Doctor("test", &cfg, WithEnviron(env), WithArgs(nil))
+	if err != nil || len(d.Warnings) != 1 || d.Warnings[0] != want {
+		t.Fatalf("\t%s\tShould diagnose the deprecated field : %v %v.", failed, d, err)
+	}
+	t.Logf("\t%s\tShould diagnose the deprecated field.", success)
+
+	if err := Parse("test", &cfg, WithEnviron(map[string]string{"TEST_HOST": "a"}), WithArgs(nil), WithProvenance()); err != nil {
+		t.Fatal(err)
+	}
+	if got := Warnings(&cfg); len(got) != 0 {
+		t.Fatalf("\t%s\tShould not warn about the unset deprecated field : %v.", failed, got)
+	}
+	t.Logf("\t%s\tShould not warn about the unset deprecated field.", success)
+}
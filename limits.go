@@ -0,0 +1,72 @@
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrValueTooLarge is the cause of a FieldError when a value exceeds the
+// limits set with WithMaxValueLength or WithMaxElements.
+var ErrValueTooLarge = errors.New("value too large")
+
+// shownValueLength is the number of bytes of a value exceeding the limits
+// kept in the error.
+const shownValueLength = 64
+
+// WithMaxValueLength rejects the values longer than n bytes before they are
+// converted, guarding []byte, slice and map fields against pathological
+// payloads of the environment blowing up memory. There is no limit by default.
+func WithMaxValueLength(n int) Option {
+	return func(o *options) {
+		o.maxLen = n
+	}
+}
+
+// WithMaxElements rejects the values of slice and map fields with more than
+// n items before they are split. There is no limit by default.
+func WithMaxElements(n int) Option {
+	return func(o *options) {
+		o.maxItems = n
+	}
+}
+
+// checkLimits enforces the limits set on the options of the field on the
+// raw value, before any memory is allocated for its conversion.
+func checkLimits(value string, field Field) error {
+	if field.maxLen > 0 && len(value) > field.maxLen {
+		return fmt.Errorf("%w, %d bytes exceed the limit of %d bytes", ErrValueTooLarge, len(value), field.maxLen)
+	}
+
+	if field.maxItems <= 0 {
+		return nil
+	}
+
+	typ := field.Field.Type()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch {
+	case typ.Kind() == reflect.Slice:
+	case typ.Kind() == reflect.Map && strings.TrimSpace(value) != "":
+	default:
+		return nil
+	}
+
+	if n := strings.Count(value, field.Options.Sep) + 1; n > field.maxItems {
+		return fmt.Errorf("%w, %d items exceed the limit of %d items", ErrValueTooLarge, n, field.maxItems)
+	}
+
+	return nil
+}
+
+// abbreviate shortens the value to the bytes kept in errors.
+func abbreviate(value string) string {
+	if len(value) <= shownValueLength {
+		return value
+	}
+
+	return value[:shownValueLength] + "..."
+}
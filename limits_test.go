@@ -0,0 +1,63 @@
+package conf
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParse_Limits(t *testing.T) {
+	os.Clearenv()
+
+	type config struct {
+		Name    string
+		Payload []byte
+		Hosts   []string
+		Labels  map[string]string
+	}
+
+	opts := []Option{WithMaxValueLength(16), WithMaxElements(3), WithArgs(nil)}
+
+	var cfg config
+	env := map[string]string{
+		"TEST_NAME":    "service",
+		"TEST_PAYLOAD": "1;2;3",
+		"TEST_HOSTS":   "a;b;c",
+		"TEST_LABELS":  "a:1;b:2",
+	}
+	if err := Parse("test", &cfg, append(opts, WithEnviron(env))...); err != nil {
+		t.Fatalf("\t%s\tShould accept values within the limits : %s.", failed, err)
+	}
+	t.Logf("\t%s\tShould accept values within the limits.", success)
+
+	tests := []struct {
+		key   string
+		value string
+		want  string
+	}{
+		{"TEST_PAYLOAD", strings.Repeat("x", 1<<20), "1048576 bytes exceed the limit of 16 bytes"},
+		{"TEST_HOSTS", "a;b;c;d", "4 items exceed the limit of 3 items"},
+		{"TEST_LABELS", "a:1;b:2;c:3;d:4", "4 items exceed the limit of 3 items"},
+	}
+
+	for _, tt := range tests {
+		var cfg config
+		err := Parse("test", &cfg, append(opts, WithEnviron(map[string]string{tt.key: tt.value}))...)
+		if !errors.Is(err, ErrValueTooLarge) {
+			t.Fatalf("\t%s\tShould reject %s exceeding the limits : got %v.", failed, tt.key, err)
+		}
+
+		if !strings.Contains(err.Error(), tt.want) || len(err.Error()) > 256 {
+			t.Fatalf("\t%s\tShould report %q with the value abbreviated : got %s.", failed, tt.want, err)
+		}
+	}
+	t.Logf("\t%s\tShould reject values exceeding the limits.", success)
+
+	var unlimited config
+	env = map[string]string{"TEST_HOSTS": strings.Repeat("a;", 1000)}
+	if err := Parse("test", &unlimited, WithEnviron(env), WithArgs(nil)); err != nil || len(unlimited.Hosts) != 1001 {
+		t.Fatalf("\t%s\tShould not limit values by default : %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould not limit values by default.", success)
+}
@@ -0,0 +1,91 @@
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// splitList splits the items of slice and map values at the separator,
+// like strings.SplitN, except for the separators within double quotes or
+// escaped with a backslash, such as "a;b";c or a\;b;c. The items keep the
+// quotes and escapes, see unquoteItem.
+func splitList(value, sep string, n int) ([]string, error) {
+	if sep == "" {
+		return nil, errors.New("empty separator")
+	}
+
+	var (
+		items  []string
+		start  int
+		quoted bool
+	)
+
+	for i := 0; i < len(value); {
+		switch {
+		case quoted && value[i] == '\\' && i+1 < len(value) && (value[i+1] == '"' || value[i+1] == '\\'):
+			i += 2
+		case value[i] == '"':
+			quoted = !quoted
+			i++
+		case quoted:
+			i++
+		case value[i] == '\\' && strings.HasPrefix(value[i+1:], sep):
+			i += 1 + len(sep)
+		case strings.HasPrefix(value[i:], sep) && (n < 0 || len(items) < n-1):
+			items = append(items, value[start:i])
+			i += len(sep)
+			start = i
+		default:
+			i++
+		}
+	}
+
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in %q", value)
+	}
+
+	return append(items, value[start:]), nil
+}
+
+// unquoteItem returns the item split by splitList as it was meant. Quoted
+// items lose the quotes and the backslashes escaping quotes and backslashes
+// within them, other items the backslashes escaping the separators.
+func unquoteItem(item string, seps ...string) string {
+	if len(item) >= 2 && item[0] == '"' && item[len(item)-1] == '"' {
+		var b strings.Builder
+		for i := 1; i < len(item)-1; i++ {
+			if item[i] == '\\' && i+1 < len(item)-1 && (item[i+1] == '"' || item[i+1] == '\\') {
+				i++
+			}
+			b.WriteByte(item[i])
+		}
+		return b.String()
+	}
+
+	for _, sep := range seps {
+		item = strings.ReplaceAll(item, `\`+sep, sep)
+	}
+
+	return item
+}
+
+// quoteItem renders the item so splitList and unquoteItem give it back,
+// quoting items holding separators, quotes or backslashes which would be
+// taken for escapes. Other backslashes, such as those of patterns, are
+// left alone.
+func quoteItem(item string, seps ...string) string {
+	special := strings.Contains(item, `"`) || strings.HasSuffix(item, `\`)
+	for _, sep := range seps {
+		special = special || strings.Contains(item, sep)
+	}
+
+	if !special {
+		return item
+	}
+
+	item = strings.ReplaceAll(item, `\`, `\\`)
+	item = strings.ReplaceAll(item, `"`, `\"`)
+
+	return `"` + item + `"`
+}
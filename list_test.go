@@ -0,0 +1,99 @@
+package conf
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse_QuotedLists(t *testing.T) {
+	os.Clearenv()
+
+	var cfg struct {
+		Conns  []string
+		Paths  []string
+		Labels map[string]string
+	}
+
+	env := map[string]string{
+		"TEST_CONNS":  `"host=a;port=1";host=b`,
+		"TEST_PATHS":  `a\;b;c;say "hi"`,
+		"TEST_LABELS": `"k:1":"v;2";plain:x`,
+	}
+	if err := Parse("test", &cfg, WithEnviron(env), WithArgs(nil)); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse quoted items : %s.", failed, err)
+	}
+
+	if diff := cmp.Diff([]string{"host=a;port=1", "host=b"}, cfg.Conns); diff != "" {
+		t.Fatalf("\t%s\tShould keep the separators of quoted items :\n%s", failed, diff)
+	}
+
+	if diff := cmp.Diff([]string{"a;b", "c", `say "hi"`}, cfg.Paths); diff != "" {
+		t.Fatalf("\t%s\tShould keep escaped separators :\n%s", failed, diff)
+	}
+
+	if diff := cmp.Diff(map[string]string{"k:1": "v;2", "plain": "x"}, cfg.Labels); diff != "" {
+		t.Fatalf("\t%s\tShould keep the separators of quoted keys and values :\n%s", failed, diff)
+	}
+	t.Logf("\t%s\tShould keep the separators of quoted and escaped items.", success)
+
+	out, err := String(&cfg)
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to render the config : %s.", failed, err)
+	}
+
+	want := "CONNS=\"host=a;port=1\";host=b\nPATHS=\"a;b\";c;\"say \\\"hi\\\"\"\nLABELS=\"k:1\":\"v;2\";plain:x\n"
+	if out != want {
+		t.Fatalf("\t%s\tShould quote the items holding separators :\n%s", failed, cmp.Diff(want, out))
+	}
+	t.Logf("\t%s\tShould quote the items holding separators.", success)
+
+	var bad struct {
+		Hosts []string
+	}
+	if err := Parse("test", &bad, WithEnviron(map[string]string{"TEST_HOSTS": `"a;b`}), WithArgs(nil)); err == nil {
+		t.Fatalf("\t%s\tShould fail on unterminated quotes.", failed)
+	}
+	t.Logf("\t%s\tShould fail on unterminated quotes.", success)
+}
+
+func TestParse_EmptySeparators(t *testing.T) {
+	os.Clearenv()
+
+	var cfg struct {
+		Tags   []string
+		Labels map[string]string
+	}
+
+	env := map[string]string{"TEST_TAGS": "a;b", "TEST_LABELS": "k:v"}
+
+	for name, opt := range map[string]Option{"list": WithListSeparator(""), "key-value": WithKeyValueSeparator("")} {
+		if err := Parse("test", &cfg, WithEnviron(env), WithArgs(nil), opt); err == nil {
+			t.Fatalf("\t%s\tShould reject an empty %s separator.", failed, name)
+		}
+		t.Logf("\t%s\tShould reject an empty %s separator.", success, name)
+	}
+
+	err := Parse("test", &cfg, WithEnviron(env), WithArgs(nil), WithListSeparator(""), WithKeyValueSeparator(""))
+	if err == nil || !strings.Contains(err.Error(), "WithListSeparator") || !strings.Contains(err.Error(), "WithKeyValueSeparator") {
+		t.Fatalf("\t%s\tShould report the error of every option : %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould report the error of every option.", success)
+
+	if err := Parse("test", &cfg, WithEnviron(env), WithArgs(nil), WithListSeparator(""), WithListSeparator(";")); err != nil {
+		t.Fatalf("\t%s\tShould clear the error once a valid separator is set : %s.", failed, err)
+	}
+	t.Logf("\t%s\tShould clear the error once a valid separator is set.", success)
+
+	if err := Parse("test", &cfg, WithEnviron(env), WithArgs(nil), WithListSeparator(":")); err == nil {
+		t.Fatalf("\t%s\tShould reject a map whose separators are the same.", failed)
+	}
+	t.Logf("\t%s\tShould reject a map whose separators are the same.", success)
+
+	if _, err := splitList("a;b", "", -1); err == nil {
+		t.Fatalf("\t%s\tShould fail to split at an empty separator.", failed)
+	}
+	t.Logf("\t%s\tShould fail to split at an empty separator.", success)
+}
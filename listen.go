@@ -0,0 +1,59 @@
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ListenAddr holds the address a server listens on, either a TCP address
+// such as :8080 or 0.0.0.0:8080 or a unix socket such as unix:///tmp/app.sock.
+type ListenAddr struct {
+	Network string
+	Address string
+}
+
+// Set implements the Setter interface.
+func (a *ListenAddr) Set(data string) error {
+	if path, ok := strings.CutPrefix(data, "unix://"); ok {
+		if path == "" {
+			return errors.New("invalid listen address, missing unix socket path")
+		}
+
+		*a = ListenAddr{Network: "unix", Address: path}
+		return nil
+	}
+
+	_, port, err := net.SplitHostPort(data)
+	if err != nil {
+		return fmt.Errorf("%w, expected an address such as :8080, 0.0.0.0:8080 or unix:///tmp/app.sock", err)
+	}
+
+	if n, err := strconv.ParseUint(port, 10, 16); err != nil || (n == 0 && port != "0") {
+		return fmt.Errorf("invalid listen address port %q, expected a number from 0 to 65535", port)
+	}
+
+	*a = ListenAddr{Network: "tcp", Address: data}
+
+	return nil
+}
+
+// String renders the address in the form accepted by Set.
+func (a ListenAddr) String() string {
+	if a.Network == "unix" {
+		return "unix://" + a.Address
+	}
+
+	return a.Address
+}
+
+// Listen announces on the address.
+func (a ListenAddr) Listen() (net.Listener, error) {
+	if a.Network == "" {
+		return nil, errors.New("listen address is not set")
+	}
+
+	return net.Listen(a.Network, a.Address)
+}
@@ -0,0 +1,72 @@
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse_ListenAddr(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "app.sock")
+
+	tests := []struct {
+		name string
+		env  string
+		want ListenAddr
+	}{
+		{"port", ":0", ListenAddr{"tcp", ":0"}},
+		{"host-port", "127.0.0.1:0", ListenAddr{"tcp", "127.0.0.1:0"}},
+		{"unix", "unix://" + sock, ListenAddr{"unix", sock}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			_ = os.Setenv("TEST_ADDR", tt.env)
+
+			var cfg struct{ Addr ListenAddr }
+			if err := Parse("test", &cfg); err != nil {
+				t.Fatalf("\t%s\tShould be able to parse listen address : %s.", failed, err)
+			}
+
+			if cfg.Addr != tt.want || cfg.Addr.String() != tt.env {
+				t.Fatalf("\t%s\tShould have parsed listen address, got %+v.", failed, cfg.Addr)
+			}
+			t.Logf("\t%s\tShould have parsed listen address.", success)
+
+			l, err := cfg.Addr.Listen()
+			if err != nil {
+				t.Fatalf("\t%s\tShould be able to listen on %s : %s.", failed, tt.env, err)
+			}
+			_ = l.Close()
+			t.Logf("\t%s\tShould be able to listen on %s.", success, tt.env)
+		})
+	}
+}
+
+func TestParse_ListenAddrErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		want string
+	}{
+		{"no-port", "localhost", "missing port in address"},
+		{"bad-port", ":http", "invalid listen address port"},
+		{"no-path", "unix://", "missing unix socket path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			_ = os.Setenv("TEST_ADDR", tt.env)
+
+			var cfg struct{ Addr ListenAddr }
+			err := Parse("test", &cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("\t%s\tShould fail for %q with %q : %v.", failed, tt.env, tt.want, err)
+			}
+			t.Logf("\t%s\tShould fail for %q : %s.", success, tt.env, err)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package conf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Markdown renders the documentation of the specified config struct as a
+// Markdown table listing every field with its env variable, flag, type,
+// default, required status and the text of the `help` tag. It is meant to
+// be generated into a committed CONFIGURATION.md so the docs never drift
+// from the code. The struct isn't parsed, the values of its fields are not
+// included.
+func Markdown(prefix string, cfg any, opts ...Option) (string, error) {
+	fields, err := Fields(prefix, cfg, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+
+	b.WriteString("| Variable | Flag | Type | Default | Required | Description |\n")
+	b.WriteString("|----------|------|------|---------|----------|-------------|\n")
+
+	for _, field := range fields {
+		flag := "`--" + field.FlagKey + "`"
+		if field.ShortFlag != "" {
+			flag = "`-" + field.ShortFlag + "`, " + flag
+		}
+
+		def := defaultText(field)
+		if def != "" {
+			def = markdownCode(def)
+		}
+
+		var required string
+		switch {
+		case field.Options.Required:
+			required = "yes"
+		case field.Options.Lazy:
+			required = "when used"
+		}
+
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s | %s |\n",
+			field.EnvKey, flag, typeName(field.Field.Type()), def, required, markdownCell(field.Options.Help))
+	}
+
+	return b.String(), nil
+}
+
+// markdownCell escapes the text for a cell of a Markdown table.
+func markdownCell(text string) string {
+	r := strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>")
+	return r.Replace(text)
+}
+
+// markdownCode renders the text as inline code in a cell of a Markdown
+// table, fenced with more backticks than it holds.
+func markdownCode(text string) string {
+	fence := "`"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+
+	text = markdownCell(text)
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		text = " " + text + " "
+	}
+
+	return fence + text + fence
+}
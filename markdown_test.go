@@ -0,0 +1,38 @@
+package conf
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMarkdown(t *testing.T) {
+	os.Clearenv()
+
+	type config struct {
+		Host    string `conf:"required,help:the host to listen on"`
+		Port    int    `conf:"default:8080,short:p"`
+		Mode    string `conf:"default:dev,help:dev | prod"`
+		Tags    []string
+		Comment string "conf:\"default:a`b,help:first line\\nsecond line\""
+	}
+
+	got, err := Markdown("app", &config{})
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to render the Markdown docs : %s.", failed, err)
+	}
+
+	want := "| Variable | Flag | Type | Default | Required | Description |\n" +
+		"|----------|------|------|---------|----------|-------------|\n" +
+		"| `APP_HOST` | `--host` | string |  | yes | the host to listen on |\n" +
+		"| `APP_PORT` | `-p`, `--port` | int | `8080` |  |  |\n" +
+		"| `APP_MODE` | `--mode` | string | `dev` |  | dev \\| prod |\n" +
+		"| `APP_TAGS` | `--tags` | []string |  |  |  |\n" +
+		"| `APP_COMMENT` | `--comment` | string | ``a`b`` |  | first line<br>second line |\n"
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("\t%s\tShould render a table of the fields :\n%s", failed, diff)
+	}
+	t.Logf("\t%s\tShould render a table of the fields.", success)
+}
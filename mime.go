@@ -0,0 +1,89 @@
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"reflect"
+	"strings"
+)
+
+// MediaType holds a MIME type with its parameters, set from a value such
+// as text/html; charset=utf-8. The type is lowercased and the charset,
+// if any, must not be empty.
+type MediaType struct {
+	Type   string
+	Params map[string]string
+}
+
+// Set implements the Setter interface.
+func (m *MediaType) Set(data string) error {
+	mediaType, params, err := parseMediaType(data)
+	if err != nil {
+		return err
+	}
+
+	*m = MediaType{Type: mediaType, Params: params}
+
+	return nil
+}
+
+// String renders the media type in the form accepted by Set.
+func (m MediaType) String() string {
+	return mime.FormatMediaType(m.Type, m.Params)
+}
+
+// Charset returns the charset parameter of the media type.
+func (m MediaType) Charset() string {
+	return m.Params["charset"]
+}
+
+// parseMediaType parses and validates the MIME type.
+func parseMediaType(value string) (string, map[string]string, error) {
+	mediaType, params, err := mime.ParseMediaType(value)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid MIME type: %w", err)
+	}
+
+	if !strings.Contains(mediaType, "/") {
+		return "", nil, fmt.Errorf("invalid MIME type %q, expected type/subtype such as application/json", mediaType)
+	}
+
+	if charset, ok := params["charset"]; ok && charset == "" {
+		return "", nil, errors.New("invalid MIME type, empty charset")
+	}
+
+	return mediaType, params, nil
+}
+
+// checkMimeType makes sure the string in the field is a valid MIME type
+// when the field is tagged with `mimetype`. For slices every element is
+// checked.
+func checkMimeType(field reflect.Value, mimeType bool) error {
+	if !mimeType {
+		return nil
+	}
+
+	for field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+
+	switch field.Kind() {
+	case reflect.Slice:
+		for i := 0; i < field.Len(); i++ {
+			if err := checkMimeType(field.Index(i), mimeType); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+
+		return nil
+	case reflect.String:
+		_, _, err := parseMediaType(field.String())
+		return err
+	}
+
+	return fmt.Errorf("mimetype tag option is supported for strings only, not %q", field.Type())
+}
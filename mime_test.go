@@ -0,0 +1,58 @@
+package conf
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse_MediaType(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("TEST_CONTENT_TYPE", "Text/HTML; charset=UTF-8")
+	_ = os.Setenv("TEST_ACCEPT", "application/json;text/plain")
+
+	var cfg struct {
+		ContentType MediaType
+		Accept      []string `conf:"mimetype"`
+	}
+
+	if err := Parse("test", &cfg); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse MIME types : %s.", failed, err)
+	}
+
+	want := MediaType{Type: "text/html", Params: map[string]string{"charset": "UTF-8"}}
+	if diff := cmp.Diff(want, cfg.ContentType); diff != "" {
+		t.Fatalf("\t%s\tShould have parsed MIME type\n%s", failed, diff)
+	}
+
+	if cfg.ContentType.Charset() != "UTF-8" || cfg.ContentType.String() != "text/html; charset=UTF-8" {
+		t.Fatalf("\t%s\tShould render MIME type with charset : %s.", failed, cfg.ContentType)
+	}
+	t.Logf("\t%s\tShould have parsed MIME type.", success)
+
+	tests := []struct {
+		name string
+		key  string
+		env  string
+		want string
+	}{
+		{"no-subtype", "TEST_CONTENT_TYPE", "json", "expected type/subtype"},
+		{"empty-charset", "TEST_CONTENT_TYPE", `text/html; charset=""`, "empty charset"},
+		{"malformed", "TEST_ACCEPT", "application/json;text/", "element 1: invalid MIME type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			_ = os.Setenv(tt.key, tt.env)
+
+			err := Parse("test", &cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) || !strings.Contains(err.Error(), tt.key) {
+				t.Fatalf("\t%s\tShould fail naming %s with %q : %v.", failed, tt.key, tt.want, err)
+			}
+			t.Logf("\t%s\tShould fail naming the env key : %s.", success, err)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Replaced in tests.
+var (
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+	exit             = os.Exit
+)
+
+// MustParse is like Parse but handles the errors the way a main function
+// would: the usage is printed and the program exits with status 0 for
+// -h/--help, the version for --version and the sample config for
+// --config-sample, and for any other error the usage followed by the error
+// is printed to stderr and the program exits with status 1.
+func MustParse(prefix string, cfg any, opts ...Option) {
+	err := Parse(prefix, cfg, opts...)
+	if err == nil {
+		return
+	}
+
+	switch {
+	case errors.Is(err, ErrHelpWanted):
+		usage, uerr := Usage(prefix, cfg, opts...)
+		if uerr != nil {
+			fmt.Fprintf(stderr, "error: %s\n", uerr)
+			exit(1)
+			return
+		}
+		fmt.Fprint(stdout, usage)
+		exit(0)
+
+	case errors.Is(err, ErrVersionWanted):
+		version, verr := VersionString(cfg)
+		if verr != nil {
+			fmt.Fprintf(stderr, "error: %s\n", verr)
+			exit(1)
+			return
+		}
+		fmt.Fprint(stdout, version)
+		exit(0)
+
+	case errors.Is(err, ErrSampleWanted):
+		sample, serr := Sample(prefix, cfg, sampleFormat(newOptions(opts).args), opts...)
+		if serr != nil {
+			fmt.Fprintf(stderr, "error: %s\n", serr)
+			exit(1)
+			return
+		}
+		fmt.Fprint(stdout, sample)
+		exit(0)
+
+	default:
+		if usage, uerr := Usage(prefix, cfg, opts...); uerr == nil {
+			fmt.Fprintf(stderr, "%s\n", usage)
+		}
+		fmt.Fprintf(stderr, "error: %s\n", err)
+		exit(1)
+	}
+}
@@ -0,0 +1,55 @@
+package conf
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMustParse(t *testing.T) {
+	os.Clearenv()
+
+	origStdout, origStderr, origExit := stdout, stderr, exit
+	defer func() { stdout, stderr, exit = origStdout, origStderr, origExit }()
+
+	type config struct {
+		Version
+		Port int `conf:"required,help:port to listen on"`
+	}
+
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stdout string
+		stderr []string
+	}{
+		{"ok", []string{"--port", "8080"}, -1, "", nil},
+		{"help", []string{"--help"}, 0, "--port", nil},
+		{"version", []string{"--version"}, 0, "Version: v1.0.0", nil},
+		{"error", nil, 1, "", []string{"--port", "error: required field Port (TEST_PORT) is missing value"}},
+	}
+
+	for _, tt := range tests {
+		var out, errOut bytes.Buffer
+		code := -1
+		stdout, stderr, exit = &out, &errOut, func(c int) { code = c }
+
+		cfg := config{Version: Version{Build: "v1.0.0"}}
+		MustParse("test", &cfg, WithArgs(tt.args))
+
+		if code != tt.code {
+			t.Fatalf("\t%s\tShould exit with %d for %s : got %d.", failed, tt.code, tt.name, code)
+		}
+		if !strings.Contains(out.String(), tt.stdout) || tt.stdout == "" && out.Len() != 0 {
+			t.Fatalf("\t%s\tShould print %q for %s : %q.", failed, tt.stdout, tt.name, out.String())
+		}
+		for _, want := range tt.stderr {
+			if !strings.Contains(errOut.String(), want) {
+				t.Fatalf("\t%s\tShould print %q to stderr for %s : %q.", failed, want, tt.name, errOut.String())
+			}
+		}
+		t.Logf("\t%s\tShould handle %s.", success, tt.name)
+	}
+}
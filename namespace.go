@@ -0,0 +1,104 @@
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Namespaces discovers the namespaces under the prefix holding a config
+// struct in the environment, such as acme and globex for APP_TENANT_ACME_HOST
+// and APP_TENANT_GLOBEX_PORT with the prefix app_tenant. A variable belongs
+// to a namespace when it ends with the key of a field of the struct, the
+// longest key wins. Fields with an env tag have fixed keys and don't tell
+// namespaces apart.
+// The namespaces are returned in lower case and sorted.
+func Namespaces(prefix string, cfg any, opts ...Option) ([]string, error) {
+	o := newOptions(opts)
+
+	fields, err := extractFields("", o.separator, nil, nil, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("extract fields from config struct: %w", err)
+	}
+
+	env := o.environment()
+
+	head := strings.ToUpper(prefix) + o.separator
+	if prefix == "" {
+		head = ""
+	}
+
+	found := make(map[string]bool)
+	for key := range env {
+		rest, ok := strings.CutPrefix(key, head)
+		if !ok {
+			continue
+		}
+
+		// The longest key wins, so APP_TENANT_ACME_DB_HOST belongs to acme
+		// rather than acme_db when there are fields DB.Host and Host.
+		var ns string
+		for _, field := range fields {
+			if field.Options.EnvName != "" {
+				continue
+			}
+
+			name, ok := strings.CutSuffix(rest, o.separator+field.EnvKey)
+			if ok && name != "" && (ns == "" || len(name) < len(ns)) {
+				ns = name
+			}
+		}
+
+		if ns != "" {
+			found[strings.ToLower(ns)] = true
+		}
+	}
+
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// ParseNamespaces parses a config struct of type T for every namespace
+// under the prefix found by Namespaces, keyed by the namespace. For example
+// with the prefix app_tenant the namespace acme is parsed with the prefix
+// app_tenant_acme. The command line flags aren't read unless passed with
+// WithArgs, as they can't tell the namespaces apart.
+func ParseNamespaces[T any](prefix string, opts ...Option) (map[string]T, error) {
+	names, err := Namespaces(prefix, new(T), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	o := newOptions(opts)
+	opts = append([]Option{WithArgs(nil)}, opts...)
+
+	out := make(map[string]T, len(names))
+	var errs []error
+
+	for _, name := range names {
+		nsPrefix := name
+		if prefix != "" {
+			nsPrefix = prefix + o.separator + name
+		}
+
+		var cfg T
+		if err := Parse(nsPrefix, &cfg, opts...); err != nil {
+			errs = append(errs, fmt.Errorf("parse namespace %s: %w", name, err))
+			continue
+		}
+		provenance.Delete(&cfg)
+		out[name] = cfg
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
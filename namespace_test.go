@@ -0,0 +1,67 @@
+package conf
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type tenant struct {
+	Host string
+	Port int `conf:"default:80"`
+	DB   struct {
+		Host string
+	}
+	Region string `conf:"env:REGION"`
+}
+
+func TestParseNamespaces(t *testing.T) {
+	os.Clearenv()
+
+	env := map[string]string{
+		"APP_TENANT_ACME_HOST":      "acme.example.com",
+		"APP_TENANT_ACME_DB_HOST":   "acme-db",
+		"APP_TENANT_GLOBEX_PORT":    "8080",
+		"APP_TENANT_GLOBEX_CO_HOST": "globex.example.com",
+		"APP_OTHER_HOST":            "other",
+		"APP_TENANT_HOST":           "no namespace",
+		"REGION":                    "eu",
+	}
+
+	names, err := Namespaces("app_tenant", &tenant{}, WithEnviron(env))
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to discover namespaces : %s.", failed, err)
+	}
+
+	if diff := cmp.Diff([]string{"acme", "globex", "globex_co"}, names); diff != "" {
+		t.Fatalf("\t%s\tShould discover the namespaces :\n%s", failed, diff)
+	}
+	t.Logf("\t%s\tShould discover the namespaces.", success)
+
+	tenants, err := ParseNamespaces[tenant]("app_tenant", WithEnviron(env))
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to parse namespaces : %s.", failed, err)
+	}
+
+	acme := tenant{Host: "acme.example.com", Port: 80, Region: "eu"}
+	acme.DB.Host = "acme-db"
+
+	want := map[string]tenant{
+		"acme":      acme,
+		"globex":    {Port: 8080, Region: "eu"},
+		"globex_co": {Host: "globex.example.com", Port: 80, Region: "eu"},
+	}
+
+	if diff := cmp.Diff(want, tenants); diff != "" {
+		t.Fatalf("\t%s\tShould parse a config per namespace :\n%s", failed, diff)
+	}
+	t.Logf("\t%s\tShould parse a config per namespace.", success)
+
+	env["APP_TENANT_ACME_PORT"] = "http"
+	if _, err := ParseNamespaces[tenant]("app_tenant", WithEnviron(env)); err == nil || !strings.Contains(err.Error(), "parse namespace acme:") {
+		t.Fatalf("\t%s\tShould name the namespace failing to parse : %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould name the namespace failing to parse.", success)
+}
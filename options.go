@@ -0,0 +1,370 @@
+package conf
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// An Option configures how the configuration is parsed.
+type Option func(*options)
+
+type options struct {
+	ctx       context.Context
+	args      []string
+	sources   []Sourcer
+	files     []Sourcer
+	environ   map[string]string
+	allowEnv  []string
+	denyEnv   []string
+	values    map[string]string
+	secrets   string
+	fileEnv   bool
+	strict    bool
+	separator string
+	listSep   string
+	kvSep     string
+	version   string
+	warnings  func(w Warning)
+	redact    RedactionFunc
+	defaults  fs.FS
+	maxLen    int
+	maxItems  int
+
+	// expiryErrors makes expired fields fail the parse.
+	expiryErrors bool
+
+	// usageValues makes Usage show the values of the fields.
+	usageValues bool
+
+	// chunkedEnv joins the values of all fields from <KEY>_PART<n>.
+	chunkedEnv bool
+
+	// partial leaves the fields failing conversion at their defaults,
+	// see WithPartialParse.
+	partial bool
+
+	// configMap is the ConfigMap KubernetesEnv reads the fields from.
+	configMap string
+
+	// versioned tells the config struct embeds a Version, which makes
+	// --version return ErrVersionWanted.
+	versioned bool
+
+	// unknownWarnings reports the variables under the prefix which don't
+	// belong to any field as warnings, see WithUnknownWarnings.
+	unknownWarnings bool
+
+	// env is the environment taken once at the start of the parse, every
+	// step reads it so they all see the same variables.
+	env map[string]string
+
+	// invalid are the errors of the options given an invalid argument
+	// keyed by the name of the option, failing the parse. A later call of
+	// the option with a valid argument clears its error.
+	invalid map[string]error
+
+	// provenance keeps the record of the parse for Sources, Warnings and
+	// SupportBundle, see WithProvenance.
+	provenance bool
+
+	// clock tells the time, see WithClock.
+	clock Clock
+
+	// fsys is the file system files are read from, see WithFS.
+	fsys fs.FS
+
+	// collected are the warnings of the parse.
+	collected []Warning
+
+	// timings are the time spent resolving and converting the values of
+	// the fields keyed by the field env key.
+	timings map[string]time.Duration
+
+	// entries are the struct values of maps stored once processed.
+	entries []mapEntry
+
+	// origins are the names of the sources of the values resolved for
+	// the parse, keyed by the field env key.
+	origins map[string]string
+
+	watchInterval time.Duration
+	watchErrors   func(err error)
+	watchFreeze   <-chan struct{}
+}
+
+func newOptions(opts []Option) *options {
+	o := options{
+		ctx:       context.Background(),
+		secrets:   "/run/secrets",
+		separator: "_",
+		listSep:   ";",
+		kvSep:     ":",
+		clock:     systemClock{},
+	}
+
+	if len(os.Args) > 1 {
+		o.args = os.Args[1:]
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &o
+}
+
+// WithContext bounds the parse by the context, the parse is aborted once
+// the context is done. This bounds the time spent converting values with
+// custom types doing I/O, the error identifies the field whose conversion
+// stalled.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+// WithArgs sets the command line arguments to read the flags from
+// in place of os.Args[1:].
+func WithArgs(args []string) Option {
+	return func(o *options) {
+		o.args = args
+	}
+}
+
+// WithSources adds the sources consulted in order in place of the
+// environment, the first source providing a value for a field wins.
+// Use Env to keep reading the environment.
+func WithSources(sources ...Sourcer) Option {
+	return func(o *options) {
+		o.sources = append(o.sources, sources...)
+	}
+}
+
+// WithValues pins the values of fields keyed by their env key, such as
+// TEST_IP_NAME. The values take precedence over the flags and every source,
+// so tests can set fields without touching the environment.
+func WithValues(values map[string]string) Option {
+	return func(o *options) {
+		if o.values == nil {
+			o.values = make(map[string]string, len(values))
+		}
+
+		for k, v := range values {
+			o.values[k] = v
+		}
+	}
+}
+
+// WithEnviron sets the environment the env sources read from in place
+// of the process environment.
+func WithEnviron(env map[string]string) Option {
+	return func(o *options) {
+		o.environ = env
+	}
+}
+
+// WithEnvironList sets the environment the env sources read from in place
+// of the process environment from KEY=value variables in the form of
+// os.Environ, e.g. those of an exec.Cmd or a Lambda event. Variables
+// without "=" are ignored, the last of duplicate keys wins.
+func WithEnvironList(env []string) Option {
+	m := make(map[string]string, len(env))
+	for _, kv := range env {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			m[k] = v
+		}
+	}
+
+	return WithEnviron(m)
+}
+
+// WithAllowedEnv limits the variables of the environment the parse may read
+// to the ones matching the patterns, which use the syntax of path.Match such
+// as PLUGIN_*. Every other variable is treated as unset, e.g. for configs of
+// plugins which must not read the secrets of the host process.
+func WithAllowedEnv(patterns ...string) Option {
+	return func(o *options) {
+		o.allowEnv = append(o.allowEnv[:len(o.allowEnv):len(o.allowEnv)], patterns...)
+	}
+}
+
+// WithDeniedEnv hides the variables of the environment matching the
+// patterns from the parse, the patterns are those of WithAllowedEnv.
+// Denied variables stay hidden even if they are allowed.
+func WithDeniedEnv(patterns ...string) Option {
+	return func(o *options) {
+		o.denyEnv = append(o.denyEnv[:len(o.denyEnv):len(o.denyEnv)], patterns...)
+	}
+}
+
+// WithDefaultsFS sets the file system, typically an embed.FS, holding the
+// defaults of the fields tagged with default:embed:<path>. The default is
+// read only when no source provides a value for the field.
+func WithDefaultsFS(fsys fs.FS) Option {
+	return func(o *options) {
+		o.defaults = fsys
+	}
+}
+
+// WithFS sets the file system the files are read from in place of the
+// disk: config files, env files, secrets, <KEY>_FILE variables and the
+// defaults of the `defaultfile` tag option. Absolute paths are
+// resolved from the root of the file system, so tests can provide the
+// secrets in an fstest.MapFS under run/secrets.
+func WithFS(fsys fs.FS) Option {
+	return func(o *options) {
+		o.fsys = fsys
+	}
+}
+
+// WithFileEnv makes the env sources read the value of every field from the
+// file named by the <KEY>_FILE variable, such as MY_SERVICE_DB_PASSWORD_FILE,
+// when the <KEY> variable isn't set. The `file` tag option enables it
+// for a single field.
+func WithFileEnv() Option {
+	return func(o *options) {
+		o.fileEnv = true
+	}
+}
+
+// WithChunkedEnv makes the env sources join the value of every field from
+// the <KEY>_PART1 to <KEY>_PARTn variables, such as MY_SERVICE_CERT_PART1,
+// when the <KEY> variable isn't set, for platforms capping the length of
+// variables. The `chunked` tag option enables it for a single field.
+func WithChunkedEnv() Option {
+	return func(o *options) {
+		o.chunkedEnv = true
+	}
+}
+
+// WithNamespaceSeparator sets the separator placed between the prefix and
+// the names of nested structs in env keys, "_" by default. For example
+// with "__" the field IP.DebugHost with the prefix app is APP__IP__DEBUG_HOST.
+func WithNamespaceSeparator(sep string) Option {
+	return func(o *options) {
+		o.separator = sep
+	}
+}
+
+// WithListSeparator sets the separator of the items of slice and map
+// values, ";" by default. The `sep` tag option overrides it per field.
+// An empty separator fails the parse.
+func WithListSeparator(sep string) Option {
+	return func(o *options) {
+		var err error
+		if sep == "" {
+			err = errors.New("WithListSeparator: empty separator")
+		}
+		o.setInvalid("WithListSeparator", err)
+		o.listSep = sep
+	}
+}
+
+// WithKeyValueSeparator sets the separator of the keys and values of map
+// items, ":" by default. The `kvsep` tag option overrides it per field.
+// An empty separator fails the parse.
+func WithKeyValueSeparator(kvsep string) Option {
+	return func(o *options) {
+		var err error
+		if kvsep == "" {
+			err = errors.New("WithKeyValueSeparator: empty separator")
+		}
+		o.setInvalid("WithKeyValueSeparator", err)
+		o.kvSep = kvsep
+	}
+}
+
+// WithVersion sets the running version of the program compared with the
+// `removed_in` tag option, such as v2.1. It defaults to the build of the
+// Version embedded in the config struct.
+func WithVersion(version string) Option {
+	return func(o *options) {
+		o.version = version
+	}
+}
+
+// WithExpiryErrors makes the fields tagged with `expires` fail the parse
+// once their date has passed, rather than report a warning.
+func WithExpiryErrors() Option {
+	return func(o *options) {
+		o.expiryErrors = true
+	}
+}
+
+// WithPartialParse makes values failing conversion or validation leave
+// their fields at the defaults and report a warning rather than fail the
+// parse, for non-critical tooling starting with a best-effort config.
+// Missing required fields and failing Validate methods still fail it.
+func WithPartialParse() Option {
+	return func(o *options) {
+		o.partial = true
+	}
+}
+
+// WithWarnings sets the function called with the warnings of the parse,
+// such as the use of a field due for removal.
+func WithWarnings(fn func(w Warning)) Option {
+	return func(o *options) {
+		o.warnings = fn
+	}
+}
+
+// setInvalid sets the error of the option, clearing it if err is nil.
+func (o *options) setInvalid(name string, err error) {
+	if err == nil {
+		delete(o.invalid, name)
+		return
+	}
+
+	if o.invalid == nil {
+		o.invalid = make(map[string]error)
+	}
+	o.invalid[name] = err
+}
+
+// invalidErr returns the errors of the options given an invalid argument
+// joined in the order of the option names, nil if there are none.
+func (o *options) invalidErr() error {
+	names := make([]string, 0, len(o.invalid))
+	for name := range o.invalid {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	errs := make([]error, len(names))
+	for i, name := range names {
+		errs[i] = o.invalid[name]
+	}
+
+	return errors.Join(errs...)
+}
+
+// warn collects the warning and reports it to the function set with
+// WithWarnings.
+func (o *options) warn(w Warning) {
+	o.collected = append(o.collected, w)
+
+	if o.warnings != nil {
+		o.warnings(w)
+	}
+}
+
+// addTiming adds the time spent on the field with the env key.
+func (o *options) addTiming(key string, d time.Duration) {
+	if o.timings == nil {
+		o.timings = make(map[string]time.Duration)
+	}
+	o.timings[key] += d
+}
+
+// withFile adds the file source merged below the sources.
+func withFile(src Sourcer) Option {
+	return func(o *options) {
+		o.files = append(o.files, src)
+	}
+}
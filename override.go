@@ -0,0 +1,131 @@
+package conf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Override returns a view of the config struct with the fields keyed by the
+// env keys of the values set from them, for scoping a config shared by a
+// multi-tenant service to a request, e.g. from its headers or the settings
+// of its tenant:
+//
+//	view, err := conf.Override("my_service", &cfg, map[string]string{"MY_SERVICE_TIMEOUT": "2s"})
+//
+// The view is a shallow copy: only the overridden fields and the nested
+// structs behind pointers leading to them are copied, the other fields
+// share their slices, maps and pointers with cfg, which is left untouched.
+// The values are converted and validated like by Parse, keys not belonging
+// to any field are an error. The options of the parse such as
+// WithNamespaceSeparator apply, the sources aren't read.
+func Override[T any](prefix string, cfg *T, values map[string]string, opts ...Option) (*T, error) {
+	if cfg == nil {
+		return nil, ErrInvalidStruct
+	}
+
+	view := new(T)
+	*view = *cfg
+
+	if len(values) == 0 {
+		return view, nil
+	}
+
+	o := newOptions(opts)
+
+	// The fields are extracted from the original, so nil pointers to nested
+	// structs are allocated in the view only when overridden.
+	fields, err := extractFields(prefix, o.separator, nil, nil, new(T))
+	if err != nil {
+		return nil, fmt.Errorf("extract fields from config struct: %w", err)
+	}
+
+	if err := bindOptions(fields, o); err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]Field, len(fields))
+	keys := make([]string, 0, len(fields))
+	for _, field := range fields {
+		byKey[field.EnvKey] = field
+		keys = append(keys, field.EnvKey)
+	}
+
+	overridden := make([]string, 0, len(values))
+	for key := range values {
+		overridden = append(overridden, key)
+	}
+	sort.Strings(overridden)
+
+	var errs []error
+	for _, key := range overridden {
+		field, ok := byKey[key]
+		if !ok {
+			msg := fmt.Sprintf("no field to override for %s", key)
+			if c := closest(key, keys); c != "" {
+				msg += ", did you mean " + c + "?"
+			}
+			errs = append(errs, errors.New(msg))
+			continue
+		}
+
+		field.Field = unshare(reflect.ValueOf(view).Elem(), field.index)
+		if err := convertField(o.ctx, false, values[key], field); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return view, nil
+}
+
+// unshare returns the field of the struct value at the index, copying the
+// structs behind the pointers on the way and the value behind a pointer
+// field, so setting it doesn't change the struct the view was copied from.
+func unshare(v reflect.Value, index []int) reflect.Value {
+	for _, i := range index {
+		if v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+
+		v = v.Field(i)
+		if v.Kind() != reflect.Ptr {
+			continue
+		}
+
+		p := reflect.New(v.Type().Elem())
+		if !v.IsNil() {
+			p.Elem().Set(v.Elem())
+		}
+		v.Set(p)
+	}
+
+	return v
+}
+
+// contextKey keys the config structs stored in contexts by their type.
+type contextKey struct {
+	typ reflect.Type
+}
+
+// NewContext returns a copy of the context carrying the config struct, e.g.
+// the view of the config for a request returned by Override, retrieved
+// with FromContext. A context carries one config struct per type.
+func NewContext[T any](ctx context.Context, cfg *T) context.Context {
+	return context.WithValue(ctx, contextKey{reflect.TypeOf(cfg)}, cfg)
+}
+
+// FromContext returns the config struct of type T carried by the context,
+// or the fallback, typically the config of the service, when there is none.
+func FromContext[T any](ctx context.Context, fallback *T) *T {
+	if cfg, ok := ctx.Value(contextKey{reflect.TypeOf(fallback)}).(*T); ok {
+		return cfg
+	}
+
+	return fallback
+}
@@ -0,0 +1,113 @@
+package conf
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOverride(t *testing.T) {
+	os.Clearenv()
+
+	type limits struct {
+		Rate  int `conf:"default:100,max:1000"`
+		Burst *int
+	}
+
+	type config struct {
+		Host    string        `conf:"default:localhost"`
+		Timeout time.Duration `conf:"default:5s"`
+		Tags    []string
+		Limits  *limits
+	}
+
+	var cfg config
+	env := map[string]string{"APP_TAGS": "a;b", "APP_LIMITS_BURST": "10"}
+	if err := Parse("app", &cfg, WithEnviron(env), WithArgs(nil)); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse : %s.", failed, err)
+	}
+	orig := cfg
+	origLimits := *cfg.Limits
+
+	t.Log("Given the need to override fields of a config for a request.")
+	{
+		view, err := Override("app", &cfg, map[string]string{
+			"APP_TIMEOUT":      "2s",
+			"APP_LIMITS_RATE":  "500",
+			"APP_LIMITS_BURST": "20",
+		})
+		if err != nil {
+			t.Fatalf("\t%s\tShould be able to override : %s.", failed, err)
+		}
+		t.Logf("\t%s\tShould be able to override.", success)
+
+		if view.Timeout != 2*time.Second || view.Limits.Rate != 500 || *view.Limits.Burst != 20 {
+			t.Fatalf("\t%s\tShould set the overridden fields : %+v %+v.", failed, view, view.Limits)
+		}
+		t.Logf("\t%s\tShould set the overridden fields.", success)
+
+		if view.Host != "localhost" || &view.Tags[0] != &cfg.Tags[0] {
+			t.Fatalf("\t%s\tShould share the other fields : %+v.", failed, view)
+		}
+		t.Logf("\t%s\tShould share the other fields.", success)
+
+		if diff := cmp.Diff(orig, cfg); diff != "" || *cfg.Limits != origLimits || *cfg.Limits.Burst != 10 {
+			t.Fatalf("\t%s\tShould leave the config untouched :\n%s", failed, diff)
+		}
+		t.Logf("\t%s\tShould leave the config untouched.", success)
+	}
+
+	t.Log("Given the need to reject invalid overrides.")
+	{
+		_, err := Override("app", &cfg, map[string]string{
+			"APP_TIMOUT":      "2s",
+			"APP_LIMITS_RATE": "5000",
+		})
+		if err == nil {
+			t.Fatalf("\t%s\tShould fail on invalid overrides.", failed)
+		}
+
+		for _, msg := range []string{
+			"no field to override for APP_TIMOUT, did you mean APP_TIMEOUT?",
+			"field Limits.Rate (APP_LIMITS_RATE)",
+		} {
+			if !strings.Contains(err.Error(), msg) {
+				t.Fatalf("\t%s\tShould report %q : %s.", failed, msg, err)
+			}
+		}
+		t.Logf("\t%s\tShould fail on invalid overrides.", success)
+
+		if cfg.Limits.Rate != 100 {
+			t.Fatalf("\t%s\tShould leave the config untouched : %d.", failed, cfg.Limits.Rate)
+		}
+		t.Logf("\t%s\tShould leave the config untouched.", success)
+	}
+}
+
+func TestContext(t *testing.T) {
+	type config struct {
+		Host string
+	}
+
+	cfg := config{Host: "a"}
+	view := config{Host: "b"}
+
+	t.Log("Given the need to carry the view of a config in a context.")
+	{
+		ctx := context.Background()
+		if got := FromContext(ctx, &cfg); got != &cfg {
+			t.Fatalf("\t%s\tShould fall back without a config : %+v.", failed, got)
+		}
+		t.Logf("\t%s\tShould fall back without a config.", success)
+
+		ctx = NewContext(ctx, &view)
+		if got := FromContext(ctx, &cfg); got != &view {
+			t.Fatalf("\t%s\tShould return the config of the context : %+v.", failed, got)
+		}
+		t.Logf("\t%s\tShould return the config of the context.", success)
+	}
+}
@@ -0,0 +1,85 @@
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// A Parser parses configuration into values of a single config struct type.
+// The metadata of the struct is compiled once by NewParser and never changes
+// afterwards, while the state of every parse is kept apart, so a Parser is
+// safe for concurrent use by multiple goroutines, e.g. to parse per-tenant
+// configs. The sources must be safe for concurrent use as well, which is
+// true for all sources of this package. Unlike Parse, the Parser keeps no
+// record of the config structs it parses, so parsing per tenant or per
+// request doesn't pile them up for Sources and Warnings.
+type Parser struct {
+	prefix string
+	typ    reflect.Type
+	fields []Field
+	opts   []Option
+}
+
+// NewParser compiles the metadata of the type of the specified config struct
+// for parsing with the prefix and the options. The cfg is only used to learn
+// its type and is left untouched.
+func NewParser(prefix string, cfg any, opts ...Option) (*Parser, error) {
+	typ := reflect.TypeOf(cfg)
+	if typ == nil || typ.Kind() != reflect.Ptr {
+		return nil, ErrInvalidStruct
+	}
+
+	// Extract the fields from a value of our own so the metadata isn't
+	// tied to any of the values parsed later.
+	o := newOptions(opts)
+	if err := o.invalidErr(); err != nil {
+		return nil, err
+	}
+
+	fields, err := extractFields(prefix, o.separator, nil, nil, reflect.New(typ.Elem()).Interface())
+	if err != nil {
+		return nil, fmt.Errorf("extract fields from config struct: %w", err)
+	}
+
+	if len(fields) == 0 {
+		return nil, errors.New("no fields identified in config struct")
+	}
+
+	if err := checkKeys(typ.Elem(), fields); err != nil {
+		return nil, err
+	}
+
+	p := Parser{
+		prefix: prefix,
+		typ:    typ,
+		fields: fields,
+		opts:   append([]Option(nil), opts...),
+	}
+
+	return &p, nil
+}
+
+// Parse parses the specified config struct like the package Parse.
+// The options are applied after the options of the parser.
+func (p *Parser) Parse(cfg any, opts ...Option) error {
+	o := newOptions(append(p.opts[:len(p.opts):len(p.opts)], opts...))
+	if err := o.invalidErr(); err != nil {
+		return err
+	}
+
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return ErrInvalidStruct
+	}
+
+	if v.Type() != p.typ {
+		return fmt.Errorf("parser of %s can't parse %T", p.typ, cfg)
+	}
+
+	lookup := func(fields []Field) (map[string]string, error) {
+		return resolveValues(fields, o)
+	}
+
+	return parseFields(p.prefix, cfg, bindFields(p.fields, v.Elem()), o, lookup)
+}
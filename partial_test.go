@@ -0,0 +1,65 @@
+package conf
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithPartialParse(t *testing.T) {
+	os.Clearenv()
+
+	type config struct {
+		Port    int           `conf:"default:8080"`
+		Timeout time.Duration `conf:"default:5s,max:1m"`
+		Tags    []int         `conf:"default:1;2"`
+		Token   string        `conf:"mask,len:4"`
+		Host    string
+	}
+
+	env := map[string]string{
+		"APP_PORT":    "eighty",
+		"APP_TIMEOUT": "1h",
+		"APP_TAGS":    "3;x",
+		"APP_TOKEN":   "s3cret",
+		"APP_HOST":    "localhost",
+	}
+
+	var cfg config
+	if err := Parse("app", &cfg, WithEnviron(env), WithArgs(nil)); err == nil {
+		t.Fatalf("\t%s\tShould fail on invalid values by default.", failed)
+	}
+	t.Logf("\t%s\tShould fail on invalid values by default.", success)
+
+	cfg = config{Token: "t0ke"}
+	var warnings []Warning
+	if err := Parse("app", &cfg, WithEnviron(env), WithArgs(nil), WithPartialParse(), WithWarnings(func(w Warning) { warnings = append(warnings, w) })); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse with invalid values : %s.", failed, err)
+	}
+
+	if cfg.Port != 8080 || cfg.Timeout != 5*time.Second || len(cfg.Tags) != 2 || cfg.Tags[1] != 2 || cfg.Token != "t0ke" || cfg.Host != "localhost" {
+		t.Fatalf("\t%s\tShould leave the invalid fields at their defaults : %+v.", failed, cfg)
+	}
+	t.Logf("\t%s\tShould leave the invalid fields at their defaults.", success)
+
+	if len(warnings) != 4 || warnings[0].Key != "APP_PORT" || !strings.Contains(warnings[0].Message, "left at the default") {
+		t.Fatalf("\t%s\tShould report the invalid values as warnings : %v.", failed, warnings)
+	}
+	for _, w := range warnings {
+		if strings.Contains(w.Message, "s3cret") {
+			t.Fatalf("\t%s\tShould keep masked values out of the warnings : %v.", failed, w)
+		}
+	}
+	t.Logf("\t%s\tShould report the invalid values as warnings.", success)
+
+	type required struct {
+		Port int `conf:"required"`
+	}
+
+	var req required
+	if err := Parse("app", &req, WithEnviron(nil), WithArgs(nil), WithPartialParse()); err == nil {
+		t.Fatalf("\t%s\tShould still fail on missing required fields.", failed)
+	}
+	t.Logf("\t%s\tShould still fail on missing required fields.", success)
+}
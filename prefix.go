@@ -0,0 +1,88 @@
+package conf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SuggestPrefix inspects the environment for the prefix the variables of
+// the specified config struct were most likely set under, e.g. my_service
+// for MY_SERVICE_DEBUG_HOST and MY_SERVICE_PORT, to diagnose a parse which
+// found no variables at all. The prefix matching the keys of the most fields
+// wins, ties go to the shortest one. Fields with an env tag have fixed keys
+// and don't tell prefixes apart.
+// The prefix is returned in lower case, empty if no variable ends with the
+// key of a field.
+func SuggestPrefix(cfg any, opts ...Option) (string, error) {
+	o := newOptions(opts)
+
+	fields, err := extractFields("", o.separator, nil, nil, cfg)
+	if err != nil {
+		return "", fmt.Errorf("extract fields from config struct: %w", err)
+	}
+
+	o.env = o.environment()
+
+	return suggestPrefix(fields, o), nil
+}
+
+// suggestPrefix does the work for SuggestPrefix with the fields extracted
+// without a prefix.
+func suggestPrefix(fields []Field, o *options) string {
+	matches := make(map[string]map[string]bool)
+
+	for key := range o.env {
+		for _, field := range fields {
+			if field.Options.EnvName != "" {
+				continue
+			}
+
+			prefix, ok := strings.CutSuffix(key, o.separator+field.EnvKey)
+			if !ok || prefix == "" {
+				continue
+			}
+
+			if matches[prefix] == nil {
+				matches[prefix] = make(map[string]bool)
+			}
+			matches[prefix][field.EnvKey] = true
+		}
+	}
+
+	var best string
+	for prefix, keys := range matches {
+		n, bestN := len(keys), len(matches[best])
+
+		switch {
+		case best == "", n > bestN:
+		case n == bestN && (len(prefix) < len(best) || len(prefix) == len(best) && prefix < best):
+		default:
+			continue
+		}
+
+		best = prefix
+	}
+
+	return strings.ToLower(best)
+}
+
+// checkPrefix warns when no source provided a value under the prefix while
+// the environment holds the variables of the struct under another one,
+// which is usually a misspelled or forgotten prefix.
+func checkPrefix(prefix string, cfg any, values map[string]string, o *options) {
+	if prefix == "" || len(values) > 0 {
+		return
+	}
+
+	fields, err := extractFields("", o.separator, nil, nil, cfg)
+	if err != nil {
+		return
+	}
+
+	if suggested := suggestPrefix(fields, o); suggested != "" && !strings.EqualFold(suggested, prefix) {
+		o.warn(Warning{
+			Key:     strings.ToUpper(prefix),
+			Message: fmt.Sprintf("no variables found under the prefix, did you mean the prefix %s?", suggested),
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package conf
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSuggestPrefix(t *testing.T) {
+	os.Clearenv()
+
+	var cfg struct {
+		Port  int
+		Debug struct {
+			Host string
+		}
+		Token string `conf:"env:API_TOKEN"`
+	}
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"none", map[string]string{"HOME": "/root"}, ""},
+		{"single", map[string]string{"MY_SERVICE_PORT": "80"}, "my_service"},
+		{"most-fields", map[string]string{"APP_PORT": "80", "BILLING_PORT": "81", "BILLING_DEBUG_HOST": "x"}, "billing"},
+		{"shortest", map[string]string{"ACME_DEBUG_HOST": "x"}, "acme"},
+		{"env-tag", map[string]string{"API_TOKEN": "x", "X_API_TOKEN": "y"}, ""},
+	}
+
+	for _, tt := range tests {
+		got, err := SuggestPrefix(&cfg, WithEnviron(tt.env))
+		if err != nil {
+			t.Fatalf("\t%s\tShould be able to suggest a prefix for %s : %s.", failed, tt.name, err)
+		}
+
+		if got != tt.want {
+			t.Fatalf("\t%s\tShould suggest %q for %s : got %q.", failed, tt.want, tt.name, got)
+		}
+	}
+	t.Logf("\t%s\tShould suggest the prefix matching the most fields.", success)
+}
+
+func TestParse_PrefixWarning(t *testing.T) {
+	os.Clearenv()
+
+	var cfg struct {
+		Port  int
+		Debug struct {
+			Host string
+		}
+	}
+
+	var warnings []Warning
+	env := map[string]string{"MY_SERVICE_PORT": "80", "MY_SERVICE_DEBUG_HOST": "x"}
+	if err := Parse("myservice", &cfg, WithEnviron(env), WithWarnings(func(w Warning) { warnings = append(warnings, w) }), WithArgs(nil)); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse : %s.", failed, err)
+	}
+
+	want := "MYSERVICE: no variables found under the prefix, did you mean the prefix my_service?"
+	if len(warnings) != 1 || warnings[0].String() != want {
+		t.Fatalf("\t%s\tShould warn about the prefix : got %v.", failed, warnings)
+	}
+	t.Logf("\t%s\tShould warn about the prefix.", success)
+
+	warnings = nil
+	if err := Parse("my_service", &cfg, WithEnviron(env), WithWarnings(func(w Warning) { warnings = append(warnings, w) }), WithArgs(nil)); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse : %s.", failed, err)
+	}
+
+	if len(warnings) != 0 {
+		t.Fatalf("\t%s\tShould not warn with the right prefix : got %v.", failed, warnings)
+	}
+	t.Logf("\t%s\tShould not warn with the right prefix.", success)
+}
@@ -0,0 +1,126 @@
+package conf
+
+import (
+	"sync"
+	"time"
+)
+
+// provenance holds the records of the last successful parse of the config
+// structs keyed by the pointer to the struct, until they are forgotten.
+var provenance sync.Map
+
+// parseRecord describes a successful parse of a config struct.
+type parseRecord struct {
+	prefix   string
+	origins  map[string]string
+	warnings []Warning
+	timings  map[string]time.Duration
+	at       time.Time
+}
+
+// recordParse keeps the record of the parse of the config struct.
+func recordParse(cfg any, prefix string, origins map[string]string, o *options) {
+	provenance.Store(cfg, parseRecord{
+		prefix:   prefix,
+		origins:  origins,
+		warnings: o.collected,
+		timings:  o.timings,
+		at:       o.clock.Now(),
+	})
+}
+
+// WithProvenance keeps the record of the parse of the config struct for
+// Sources, Warnings and SupportBundle. The record holds on to the config
+// struct until it's released with Forget, so it's kept only on request.
+func WithProvenance() Option {
+	return func(o *options) {
+		o.provenance = true
+	}
+}
+
+// Forget releases the record kept of the specified config struct by a
+// parse with WithProvenance, so it can be garbage collected. Programs
+// parsing config structs over and over, such as per request, call it once
+// done with them.
+func Forget(cfg any) {
+	provenance.Delete(cfg)
+}
+
+// Sources returns where every field of the specified config struct got its
+// value from in the last successful parse with WithProvenance, keyed by the
+// field env key.
+// The origins are:
+//
+//   - value for values pinned with WithValues,
+//   - flag for command line flags,
+//   - env for the environment,
+//   - secret for Docker secrets,
+//   - the String of other sources such as "yaml file config.yaml",
+//     or their type if they don't implement fmt.Stringer,
+//   - default for defaults from tags or a Defaulter.
+//
+// Fields left to their zero value are missing.
+func Sources(cfg any) map[string]string {
+	rec, ok := provenance.Load(cfg)
+	if !ok {
+		return nil
+	}
+
+	origins := rec.(parseRecord).origins
+
+	out := make(map[string]string, len(origins))
+	for k, v := range origins {
+		out[k] = v
+	}
+
+	return out
+}
+
+// Warnings returns the warnings of the last successful parse with
+// WithProvenance of the specified config struct, such as the use of
+// deprecated fields, for
+// programs reporting them after the parse rather than with WithWarnings.
+func Warnings(cfg any) []Warning {
+	rec, ok := provenance.Load(cfg)
+	if !ok {
+		return nil
+	}
+
+	return append([]Warning(nil), rec.(parseRecord).warnings...)
+}
+
+// originName returns the origin reported by Sources for the source.
+func originName(src Sourcer) string {
+	switch src.(type) {
+	case envSource:
+		return "env"
+	case secretsSource:
+		return "secret"
+	}
+
+	return sourceName(src)
+}
+
+// fieldOrigins returns the origins of the fields given the origins of
+// the resolved values. It must be called after the defaults were applied
+// in code but before the values are set.
+func fieldOrigins(fields []Field, values, resolved map[string]string) map[string]string {
+	origins := make(map[string]string, len(fields))
+
+	for _, field := range fields {
+		if _, ok := values[field.EnvKey]; ok {
+			origin, ok := resolved[field.EnvKey]
+			if !ok {
+				origin = "source"
+			}
+			origins[field.EnvKey] = origin
+			continue
+		}
+
+		if field.Options.DefaultVal != "" || field.Options.DefaultFile != "" || field.Options.DefaultEmbed != "" || !field.Field.IsZero() {
+			origins[field.EnvKey] = "default"
+		}
+	}
+
+	return origins
+}
@@ -0,0 +1,116 @@
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type provenanceConfig struct {
+	Host    string
+	Port    int `conf:"default:8080"`
+	Debug   bool
+	Name    string
+	Region  string
+	Token   string
+	Timeout int
+	Unset   string
+}
+
+func (c *provenanceConfig) Defaults() {
+	c.Timeout = 30
+}
+
+func TestSources(t *testing.T) {
+	os.Clearenv()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("region: eu\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg provenanceConfig
+	err := Parse("test", &cfg,
+		WithEnviron(map[string]string{"TEST_HOST": "db", "TEST_NAME": "env"}),
+		WithArgs([]string{"--debug"}),
+		WithValues(map[string]string{"TEST_NAME": "pinned"}),
+		WithSecretsDir(t.TempDir()),
+		WithYamlFile(path),
+		WithProvenance(),
+	)
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to parse : %s.", failed, err)
+	}
+	t.Logf("\t%s\tShould be able to parse.", success)
+
+	want := map[string]string{
+		"TEST_HOST":    "env",
+		"TEST_PORT":    "default",
+		"TEST_DEBUG":   "flag",
+		"TEST_NAME":    "value",
+		"TEST_REGION":  "yaml file " + path,
+		"TEST_TIMEOUT": "default",
+	}
+
+	if diff := cmp.Diff(want, Sources(&cfg)); diff != "" {
+		t.Fatalf("\t%s\tShould report where every field got its value from :\n%s", failed, diff)
+	}
+	t.Logf("\t%s\tShould report where every field got its value from.", success)
+
+	var other provenanceConfig
+	if got := Sources(&other); got != nil {
+		t.Fatalf("\t%s\tShould report nothing for a config struct never parsed : %v.", failed, got)
+	}
+	t.Logf("\t%s\tShould report nothing for a config struct never parsed.", success)
+}
+
+func TestForget(t *testing.T) {
+	os.Clearenv()
+
+	env := map[string]string{"TEST_HOST": "db"}
+
+	var plain provenanceConfig
+	if err := Parse("test", &plain, WithEnviron(env), WithArgs(nil)); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse : %s.", failed, err)
+	}
+
+	if _, ok := provenance.Load(&plain); ok {
+		t.Fatalf("\t%s\tShould keep no record of a parse without WithProvenance.", failed)
+	}
+	t.Logf("\t%s\tShould keep no record of a parse without WithProvenance.", success)
+
+	var cfg provenanceConfig
+	if err := Parse("test", &cfg, WithEnviron(env), WithArgs(nil), WithProvenance()); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse : %s.", failed, err)
+	}
+
+	Forget(&cfg)
+	if _, ok := provenance.Load(&cfg); ok {
+		t.Fatalf("\t%s\tShould release the record of a forgotten config struct.", failed)
+	}
+	t.Logf("\t%s\tShould release the record of a forgotten config struct.", success)
+
+	p, err := NewParser("test", &provenanceConfig{}, WithEnviron(env), WithArgs(nil))
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to compile config struct : %s.", failed, err)
+	}
+
+	var tenant provenanceConfig
+	if err := p.Parse(&tenant); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse with parser : %s.", failed, err)
+	}
+
+	var result provenanceConfig
+	if _, err := ParseResult("test", &result, WithEnviron(env), WithArgs(nil), WithProvenance()); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse : %s.", failed, err)
+	}
+
+	for _, v := range []any{&tenant, &result} {
+		if _, ok := provenance.Load(v); ok {
+			t.Fatalf("\t%s\tShould keep no record of parses by a Parser or ParseResult.", failed)
+		}
+	}
+	t.Logf("\t%s\tShould keep no record of parses by a Parser or ParseResult.", success)
+}
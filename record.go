@@ -0,0 +1,111 @@
+package conf
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Record parses the specified config struct like Parse and writes every
+// key/value resolved from the sources and flags into the golden file
+// at path. The file is written even if processing the values fails, so it
+// can be attached to bug reports and served back later with Replay.
+//
+// The values are redacted like in the errors: masked and secret fields
+// are hidden and DSN and URL fields lose their password, so these values
+// have to be filled in the golden file before it's replayed. The file is
+// readable by its owner only.
+func Record(path, prefix string, cfg any, opts ...Option) error {
+	o := newOptions(opts)
+
+	var resolved map[string]string
+
+	lookup := func(fields []Field) (map[string]string, error) {
+		values, err := resolveValues(fields, o)
+		if values != nil {
+			resolved = redactValues(fields, values)
+		}
+		return values, err
+	}
+
+	err := parse(prefix, cfg, o, lookup)
+
+	// Nothing was resolved if the config struct itself is invalid.
+	if resolved == nil {
+		return err
+	}
+
+	if err := writeGolden(path, resolved); err != nil {
+		return fmt.Errorf("write golden file: %w", err)
+	}
+
+	return err
+}
+
+// Replay parses the specified config struct using only the key/values
+// stored in the golden file at path by Record. Neither the environment
+// nor the command line flags are consulted, so the result is the same
+// on any machine, the options only configure the keys and the context.
+func Replay(path, prefix string, cfg any, opts ...Option) error {
+	values, err := readGolden(path)
+	if err != nil {
+		return fmt.Errorf("read golden file: %w", err)
+	}
+
+	o := newOptions(opts)
+
+	lookup := func(fields []Field) (map[string]string, error) {
+		o.origins = make(map[string]string, len(values))
+		for key := range values {
+			o.origins[key] = "golden file " + path
+		}
+		return values, nil
+	}
+
+	return parse(prefix, cfg, o, lookup)
+}
+
+// redactValues returns a copy of the values keyed by the field env key
+// with the values of the fields redacted.
+func redactValues(fields []Field, values map[string]string) map[string]string {
+	redacted := make(map[string]string, len(values))
+	for key, value := range values {
+		redacted[key] = value
+	}
+
+	for _, field := range fields {
+		if value, ok := values[field.EnvKey]; ok {
+			redacted[field.EnvKey] = redactValue(value, field)
+		}
+	}
+
+	return redacted
+}
+
+func writeGolden(path string, values map[string]string) error {
+	data, err := json.MarshalIndent(values, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+
+	// WriteFile keeps the mode of a file written before.
+	return os.Chmod(path, 0o600)
+}
+
+func readGolden(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
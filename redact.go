@@ -0,0 +1,134 @@
+package conf
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A RedactionFunc renders the value of a field tagged with `mask` or
+// `secret` wherever values are shown: in String, in errors, in Field.Value
+// and in support bundles.
+type RedactionFunc func(value string) string
+
+// WithRedaction sets how the values of masked fields are rendered,
+// RedactMask by default.
+func WithRedaction(fn RedactionFunc) Option {
+	return func(o *options) {
+		o.redact = fn
+	}
+}
+
+// RedactMask renders every value as xxxxxx.
+func RedactMask(string) string {
+	return maskedValue
+}
+
+// RedactLast4 keeps the last four characters of values of at least twelve
+// characters visible, such as xxxxxx3f9a, so operators can tell keys apart.
+// Shorter values are rendered as xxxxxx.
+func RedactLast4(value string) string {
+	r := []rune(value)
+	if len(r) < 12 {
+		return maskedValue
+	}
+
+	return maskedValue + string(r[len(r)-4:])
+}
+
+// RedactHash returns the RedactionFunc rendering values as a prefix of their
+// HMAC-SHA-256 keyed with key, such as hmac:2bb80d537b1da3e3, so operators
+// can check whether two deployments got the same value without seeing it.
+// Unlike a plain hash the key keeps short values such as PINs from being
+// guessed by hashing every candidate, so it must be kept secret.
+func RedactHash(key []byte) RedactionFunc {
+	key = append([]byte(nil), key...)
+
+	return func(value string) string {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(value))
+		return "hmac:" + hex.EncodeToString(mac.Sum(nil)[:8])
+	}
+}
+
+// redactedError hides the value of a field in the error converting it, as
+// the errors of strconv, net/url and custom types quote the value.
+type redactedError struct {
+	err error
+	msg string
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// redactError hides the value, and for masked slices and maps its items,
+// in the error converting it into the field wherever the value is redacted.
+// Values too short to tell apart from the rest of the message are only
+// hidden when quoted.
+func redactError(err error, value string, field Field) error {
+	if redactValue(value, field) == value {
+		return err
+	}
+
+	secrets := []string{value}
+	if field.Options.Mask || field.Options.Secret {
+		secrets = append(secrets, listItems(value, field)...)
+	}
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+
+	msg := err.Error()
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+
+		with := redactValue(secret, field)
+		msg = strings.ReplaceAll(msg, strconv.Quote(secret), strconv.Quote(with))
+		if len(secret) >= 4 {
+			msg = strings.ReplaceAll(msg, secret, with)
+		}
+	}
+
+	return &redactedError{err: err, msg: msg}
+}
+
+// listItems returns the items of slice values and the keys and values of
+// map values, unquoted.
+func listItems(value string, field Field) []string {
+	kind := field.Field.Kind()
+	if kind == reflect.Ptr {
+		kind = field.Field.Type().Elem().Kind()
+	}
+
+	if kind != reflect.Slice && kind != reflect.Map {
+		return nil
+	}
+
+	items, err := splitList(value, field.Options.Sep, -1)
+	if err != nil {
+		return nil
+	}
+
+	var out []string
+	for _, item := range items {
+		if kind == reflect.Map {
+			if kv, err := splitList(item, field.Options.KVSep, 2); err == nil {
+				for _, part := range kv {
+					out = append(out, unquoteItem(part, field.Options.Sep, field.Options.KVSep))
+				}
+			}
+		}
+		out = append(out, unquoteItem(item, field.Options.Sep, field.Options.KVSep))
+	}
+
+	return out
+}
@@ -0,0 +1,111 @@
+package conf
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRedaction(t *testing.T) {
+	os.Clearenv()
+
+	type config struct {
+		APIKey string `conf:"mask"`
+		Port   int    `conf:"mask"`
+	}
+
+	env := map[string]string{"TEST_API_KEY": "sk-live-0123456789abcdef", "TEST_PORT": "80"}
+
+	tests := []struct {
+		name   string
+		redact RedactionFunc
+		want   string
+	}{
+		{"default", nil, "API_KEY=xxxxxx\n"},
+		{"mask", RedactMask, "API_KEY=xxxxxx\n"},
+		{"last4", RedactLast4, "API_KEY=xxxxxxcdef\n"},
+		{"hash", RedactHash([]byte("key")), "API_KEY=hmac:" + hmacPrefix("key", "sk-live-0123456789abcdef") + "\n"},
+	}
+
+	for _, tt := range tests {
+		opts := []Option{WithEnviron(env), WithArgs(nil)}
+		if tt.redact != nil {
+			opts = append(opts, WithRedaction(tt.redact))
+		}
+
+		var cfg config
+		if err := Parse("test", &cfg, opts...); err != nil {
+			t.Fatalf("\t%s\tShould be able to parse : %s.", failed, err)
+		}
+
+		out, err := String(&cfg, opts...)
+		if err != nil {
+			t.Fatalf("\t%s\tShould be able to render the config : %s.", failed, err)
+		}
+
+		if !strings.HasPrefix(out, tt.want) {
+			t.Fatalf("\t%s\tShould redact with %s :\n%s", failed, tt.name, out)
+		}
+		t.Logf("\t%s\tShould redact with %s : %s", success, tt.name, strings.TrimSpace(out))
+	}
+
+	var cfg config
+	err := Parse("test", &cfg, WithEnviron(map[string]string{"TEST_PORT": "port-12345678"}), WithArgs(nil), WithRedaction(RedactLast4))
+
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || !strings.Contains(err.Error(), "converting 'xxxxxx5678'") {
+		t.Fatalf("\t%s\tShould redact the value in errors : %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould redact the value in errors.", success)
+
+	var leaky struct {
+		Port  int      `conf:"mask"`
+		Pins  []int    `conf:"secret"`
+		Token Duration `conf:"mask"`
+		Plain int
+	}
+
+	env = map[string]string{
+		"TEST_PORT":  "s3cret-port",
+		"TEST_PINS":  "1;sekret",
+		"TEST_TOKEN": "ab",
+		"TEST_PLAIN": "visible",
+	}
+	err = Parse("test", &leaky, WithEnviron(env), WithArgs(nil), WithSecretsDir(t.TempDir()))
+	if err == nil {
+		t.Fatalf("\t%s\tShould fail on the malformed values.", failed)
+	}
+
+	for _, secret := range []string{"s3cret-port", "sekret", `"ab"`} {
+		if strings.Contains(err.Error(), secret) {
+			t.Fatalf("\t%s\tShould not leak %s into the details of errors : %s.", failed, secret, err)
+		}
+	}
+	if !strings.Contains(err.Error(), `"visible"`) || !errors.Is(err, strconv.ErrSyntax) {
+		t.Fatalf("\t%s\tShould keep the details of errors : %s.", failed, err)
+	}
+	t.Logf("\t%s\tShould not leak values into the details of errors : %s", success, err)
+
+	if got := RedactLast4("short"); got != maskedValue {
+		t.Fatalf("\t%s\tShould mask short values fully : %s.", failed, got)
+	}
+	t.Logf("\t%s\tShould mask short values fully.", success)
+
+	if RedactHash([]byte("a"))("1234") == RedactHash([]byte("b"))("1234") {
+		t.Fatalf("\t%s\tShould hash values depending on the key.", failed)
+	}
+	t.Logf("\t%s\tShould hash values depending on the key.", success)
+}
+
+// hmacPrefix returns the prefix of the HMAC-SHA-256 of the value
+// RedactHash renders.
+func hmacPrefix(key, value string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
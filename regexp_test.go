@@ -0,0 +1,46 @@
+package conf
+
+import (
+	"errors"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParse_Regexp(t *testing.T) {
+	os.Clearenv()
+
+	type config struct {
+		Route  *regexp.Regexp `conf:"default:^/api/v[0-9]+/"`
+		Ignore []*regexp.Regexp
+		Name   regexp.Regexp
+	}
+
+	env := map[string]string{"TEST_IGNORE": `\.tmp$;^\.git/`, "TEST_NAME": `^[a-z]+$`}
+
+	var cfg config
+	if err := Parse("test", &cfg, WithEnviron(env), WithArgs(nil)); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse patterns : %s.", failed, err)
+	}
+
+	if !cfg.Route.MatchString("/api/v2/users") || len(cfg.Ignore) != 2 || !cfg.Ignore[1].MatchString(".git/HEAD") || !cfg.Name.MatchString("app") {
+		t.Fatalf("\t%s\tShould compile the patterns : %v.", failed, cfg)
+	}
+	t.Logf("\t%s\tShould compile the patterns.", success)
+
+	out, err := String(&cfg)
+	if err != nil || !strings.Contains(out, `IGNORE=\.tmp$;^\.git/`) {
+		t.Fatalf("\t%s\tShould render the patterns : %v\n%s", failed, err, out)
+	}
+	t.Logf("\t%s\tShould render the patterns.", success)
+
+	cfg = config{}
+	err = Parse("test", &cfg, WithEnviron(map[string]string{"TEST_IGNORE": `ok;(unclosed`}), WithArgs(nil))
+
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || !strings.Contains(err.Error(), "missing closing )") {
+		t.Fatalf("\t%s\tShould report the compile error : %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould report the compile error : %s.", success, err)
+}
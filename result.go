@@ -0,0 +1,58 @@
+package conf
+
+import (
+	"time"
+)
+
+// A Result describes a successful parse for callers needing more than the
+// error of Parse.
+type Result struct {
+
+	// Fields are the fields of the config struct holding the parsed values.
+	Fields []Field
+
+	// Sources are where the fields got their values from keyed by the
+	// field env key, see Sources.
+	Sources map[string]string
+
+	// Warnings are the warnings reported by the parse.
+	Warnings []Warning
+
+	// Duration is how long the parse took.
+	Duration time.Duration
+
+	// Timings are how long resolving and converting the value of every
+	// field took keyed by the field env key, attributing slow parses to
+	// the settings backed by slow files or custom types. Loading files and
+	// remote stores is timed per source by SourcesHealth.
+	Timings map[string]time.Duration
+}
+
+// ParseResult parses the specified config struct like Parse and describes
+// the parse with a Result. It keeps no record of the config struct even
+// with WithProvenance, Sources and Warnings don't know it, the Result
+// describes it.
+func ParseResult(prefix string, cfg any, opts ...Option) (*Result, error) {
+	start := time.Now()
+
+	if err := Parse(prefix, cfg, append(opts[:len(opts):len(opts)], WithProvenance())...); err != nil {
+		return nil, err
+	}
+
+	r := Result{Duration: time.Since(start)}
+
+	fields, err := Fields(prefix, cfg, opts...)
+	if err != nil {
+		return nil, err
+	}
+	r.Fields = fields
+
+	if rec, ok := provenance.Load(cfg); ok {
+		r.Warnings = rec.(parseRecord).warnings
+		r.Timings = rec.(parseRecord).timings
+	}
+	r.Sources = Sources(cfg)
+	Forget(cfg)
+
+	return &r, nil
+}
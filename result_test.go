@@ -0,0 +1,79 @@
+package conf
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestParseResult(t *testing.T) {
+	os.Clearenv()
+
+	var cfg struct {
+		Host    string `conf:"default:localhost"`
+		Port    int
+		OldHost string `conf:"removed_in:v2.0"`
+	}
+
+	env := map[string]string{"TEST_PORT": "8080", "TEST_OLD_HOST": "old"}
+
+	r, err := ParseResult("test", &cfg, WithEnviron(env), WithArgs(nil))
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to parse : %s.", failed, err)
+	}
+	t.Logf("\t%s\tShould be able to parse.", success)
+
+	if len(r.Fields) != 3 || r.Fields[1].EnvKey != "TEST_PORT" || r.Fields[1].Value() != "8080" {
+		t.Fatalf("\t%s\tShould describe the fields : %+v.", failed, r.Fields)
+	}
+	t.Logf("\t%s\tShould describe the fields.", success)
+
+	if r.Sources["TEST_HOST"] != "default" || r.Sources["TEST_PORT"] != "env" {
+		t.Fatalf("\t%s\tShould describe the sources : %v.", failed, r.Sources)
+	}
+	t.Logf("\t%s\tShould describe the sources.", success)
+
+	if len(r.Warnings) != 1 || r.Warnings[0].Key != "TEST_OLD_HOST" {
+		t.Fatalf("\t%s\tShould describe the warnings : %v.", failed, r.Warnings)
+	}
+	t.Logf("\t%s\tShould describe the warnings.", success)
+
+	if r.Duration <= 0 {
+		t.Fatalf("\t%s\tShould time the parse : %s.", failed, r.Duration)
+	}
+	t.Logf("\t%s\tShould time the parse.", success)
+
+	if _, err := ParseResult("test", &cfg, WithEnviron(map[string]string{"TEST_PORT": "http"}), WithArgs(nil)); err == nil {
+		t.Fatalf("\t%s\tShould fail like Parse.", failed)
+	}
+	t.Logf("\t%s\tShould fail like Parse.", success)
+}
+
+type slowValue string
+
+func (v *slowValue) Set(data string) error {
+	time.Sleep(20 * time.Millisecond)
+	*v = slowValue(data)
+	return nil
+}
+
+func TestParseResult_Timings(t *testing.T) {
+	os.Clearenv()
+
+	var cfg struct {
+		Host string
+		Slow slowValue
+	}
+
+	env := map[string]string{"TEST_HOST": "localhost", "TEST_SLOW": "value"}
+
+	r, err := ParseResult("test", &cfg, WithEnviron(env), WithArgs(nil))
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to parse : %s.", failed, err)
+	}
+
+	if r.Timings["TEST_SLOW"] < 20*time.Millisecond || r.Timings["TEST_HOST"] >= 20*time.Millisecond {
+		t.Fatalf("\t%s\tShould attribute the time to the slow field : %v.", failed, r.Timings)
+	}
+	t.Logf("\t%s\tShould attribute the time to the slow field : %v.", success, r.Timings)
+}
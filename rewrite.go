@@ -0,0 +1,116 @@
+package conf
+
+import (
+	"io/fs"
+	"strings"
+)
+
+// A KeyRewriter maps the keys of a field onto the keys of a source, see
+// Rewrite. Sources keyed by env keys, such as the environment and Vault,
+// look up the EnvKey of the field, sources keyed by paths, such as files
+// and Consul, its Path.
+type KeyRewriter func(fld Field) Field
+
+// Rewrite wraps the source so the fields are looked up under the keys the
+// rewriters map them to, applied in order. It maps an existing Consul tree
+// or a legacy file layout onto the keys of the config struct without
+// renaming either side:
+//
+//	legacy := conf.Rewrite(conf.Env(), conf.StripPrefix("MY_SERVICE_"), conf.AddPrefix("LEGACY_"))
+//	err := conf.Parse("my_service", &cfg, conf.WithSources(conf.Env(), legacy))
+func Rewrite(src Sourcer, rewriters ...KeyRewriter) Sourcer {
+	return &rewriteSource{src: src, rewriters: rewriters}
+}
+
+// StripPrefix returns the rewriter removing the prefix from the env keys,
+// e.g. StripPrefix("MY_SERVICE_") looks up MY_SERVICE_DB_HOST as DB_HOST.
+func StripPrefix(prefix string) KeyRewriter {
+	return func(fld Field) Field {
+		fld.EnvKey = strings.TrimPrefix(fld.EnvKey, prefix)
+		return fld
+	}
+}
+
+// AddPrefix returns the rewriter adding the prefix to the env keys,
+// e.g. AddPrefix("LEGACY_") looks up DB_HOST as LEGACY_DB_HOST.
+func AddPrefix(prefix string) KeyRewriter {
+	return func(fld Field) Field {
+		fld.EnvKey = prefix + fld.EnvKey
+		return fld
+	}
+}
+
+// RenameKeys returns the rewriter renaming the env keys found in names to
+// the keys they map to, e.g. {"MY_SERVICE_DB_HOST": "PGHOST"}. Other keys
+// are left as they are.
+func RenameKeys(names map[string]string) KeyRewriter {
+	return func(fld Field) Field {
+		if name, ok := names[fld.EnvKey]; ok {
+			fld.EnvKey = name
+		}
+		return fld
+	}
+}
+
+// MovePath returns the rewriter moving the paths under from to the paths
+// under to, both written with dots such as db.primary. MovePath("db",
+// "storage.postgres") looks up the field DB.Host under storage.postgres.host.
+// Path elements are matched ignoring case, underscores and dashes, an empty
+// from moves all the paths.
+func MovePath(from, to string) KeyRewriter {
+	var fromPath, toPath []string
+	if from != "" {
+		fromPath = strings.Split(from, ".")
+	}
+	if to != "" {
+		toPath = strings.Split(to, ".")
+	}
+
+	return func(fld Field) Field {
+		if len(fld.Path) < len(fromPath) {
+			return fld
+		}
+
+		for i, name := range fromPath {
+			if normalizeKey(name) != normalizeKey(fld.Path[i]) {
+				return fld
+			}
+		}
+
+		fld.Path = append(toPath[:len(toPath):len(toPath)], fld.Path[len(fromPath):]...)
+		return fld
+	}
+}
+
+// rewriteSource looks up the fields of the source under rewritten keys.
+// The wrapped source is loaded and bound to the environment of the parse
+// in its place.
+type rewriteSource struct {
+	src       Sourcer
+	rewriters []KeyRewriter
+}
+
+func (s *rewriteSource) rewrite(fld Field) Field {
+	for _, rewrite := range s.rewriters {
+		fld = rewrite(fld)
+	}
+
+	return fld
+}
+
+func (s *rewriteSource) Source(fld Field) (string, bool) {
+	return s.src.Source(s.rewrite(fld))
+}
+
+func (s *rewriteSource) elements(fsys fs.FS, path []string) []string {
+	l, ok := s.src.(elementLister)
+	if !ok {
+		return nil
+	}
+
+	return l.elements(fsys, s.rewrite(Field{Path: path}).Path)
+}
+
+func (s *rewriteSource) String() string {
+	return originName(s.src)
+}
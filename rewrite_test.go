@@ -0,0 +1,53 @@
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRewrite(t *testing.T) {
+	os.Clearenv()
+
+	type config struct {
+		Host string
+		DB   struct {
+			Host string
+			User string
+			Port int
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "legacy.yaml")
+	yaml := "storage:\n  postgres:\n    user: files\n    port: 5432\n"
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{
+		"APP_HOST":       "current",
+		"LEGACY_HOST":    "legacy",
+		"LEGACY_DB_PORT": "1",
+		"PGHOST":         "db",
+	}
+
+	legacyEnv := Rewrite(Env(), StripPrefix("APP_"), AddPrefix("LEGACY_"))
+	renamedEnv := Rewrite(Env(), RenameKeys(map[string]string{"APP_DB_HOST": "PGHOST"}))
+	legacyFile := Rewrite(yamlFileSource(path), MovePath("db", "storage.postgres"))
+
+	var cfg config
+	if err := Parse("app", &cfg, WithEnviron(env), WithArgs(nil), WithSources(Env(), renamedEnv, legacyEnv, legacyFile), WithProvenance()); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse from rewritten sources : %s.", failed, err)
+	}
+
+	if cfg.Host != "current" || cfg.DB.Host != "db" || cfg.DB.User != "files" || cfg.DB.Port != 1 {
+		t.Fatalf("\t%s\tShould look up the fields under the rewritten keys : %+v.", failed, cfg)
+	}
+	t.Logf("\t%s\tShould look up the fields under the rewritten keys.", success)
+
+	origins := Sources(&cfg)
+	if origins["APP_DB_HOST"] != "env" || origins["APP_DB_USER"] != "yaml file "+path {
+		t.Fatalf("\t%s\tShould report the wrapped sources as origins : %v.", failed, origins)
+	}
+	t.Logf("\t%s\tShould report the wrapped sources as origins.", success)
+}
@@ -0,0 +1,135 @@
+package conf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrSampleWanted is returned by Parse when --config-sample is passed, the
+// sample config can be printed with Sample in the format following the flag.
+var ErrSampleWanted = errors.New("config sample wanted")
+
+// Sample renders a commented sample config for the specified config struct
+// to bootstrap local development, in the format env for a .env.example file
+// read by WithEnvFile or yaml for a config file read by WithYamlFile. Every
+// field is preceded by its help and is set to its default, fields without a
+// default are left commented out to be filled in.
+func Sample(prefix string, cfg any, format string, opts ...Option) (string, error) {
+	fields, err := Fields(prefix, cfg, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+
+	switch format {
+	case "env":
+		fmt.Fprintf(&b, "# The config under the prefix %s.\n", strings.ToUpper(prefix))
+		for _, field := range fields {
+			b.WriteString("\n")
+			writeSampleComment(&b, "", field)
+
+			if def := field.Options.DefaultVal; def != "" {
+				fmt.Fprintf(&b, "%s=%s\n", field.EnvKey, quoteDotenv(def))
+				continue
+			}
+			fmt.Fprintf(&b, "# %s=\n", field.EnvKey)
+		}
+	case "yaml":
+		fmt.Fprintf(&b, "# The config of %s.\n", prefix)
+
+		var parents []string
+		for _, field := range fields {
+			names := make([]string, len(field.Path))
+			for i, name := range field.Path {
+				names[i] = propertyName(name)
+			}
+
+			b.WriteString("\n")
+
+			// Open the objects of the nested structs not opened by the
+			// previous field.
+			common := 0
+			for common < len(parents) && common < len(names)-1 && parents[common] == names[common] {
+				common++
+			}
+			for i := common; i < len(names)-1; i++ {
+				fmt.Fprintf(&b, "%s%s:\n", strings.Repeat("  ", i), names[i])
+			}
+			parents = names[:len(names)-1]
+
+			indent := strings.Repeat("  ", len(parents))
+			key := names[len(names)-1]
+
+			writeSampleComment(&b, indent, field)
+
+			if def := field.Options.DefaultVal; def != "" {
+				typ := field.Field.Type()
+				if isStructSlice(typ) || isStructMap(typ) {
+					fmt.Fprintf(&b, "%s# %s:\n", indent, key)
+					continue
+				}
+
+				value, err := json.Marshal(defaultValue(typ, def, field.Options))
+				if err != nil {
+					return "", fmt.Errorf("render default of field %s: %w", field.Name, err)
+				}
+				fmt.Fprintf(&b, "%s%s: %s\n", indent, key, value)
+				continue
+			}
+			fmt.Fprintf(&b, "%s# %s:\n", indent, key)
+		}
+	default:
+		return "", fmt.Errorf("unknown sample format %q, use env or yaml", format)
+	}
+
+	return b.String(), nil
+}
+
+// writeSampleComment writes the help of the field and how it's set as
+// comments of the sample.
+func writeSampleComment(b *strings.Builder, indent string, field Field) {
+	for _, line := range strings.Split(field.Options.Help, "\n") {
+		if line != "" {
+			fmt.Fprintf(b, "%s# %s\n", indent, line)
+		}
+	}
+
+	var notes []string
+	switch {
+	case field.Options.Required:
+		notes = append(notes, "required")
+	case field.Options.DefaultFile != "" || field.Options.DefaultEmbed != "":
+		notes = append(notes, "default: "+defaultText(field))
+	}
+	if field.Options.Secret {
+		name := field.Options.SecretName
+		if name == "" {
+			name = strings.ToLower(field.EnvKey)
+		}
+		notes = append(notes, "read from the secret "+name)
+	}
+
+	if len(notes) > 0 {
+		fmt.Fprintf(b, "%s# (%s)\n", indent, strings.Join(notes, ", "))
+	}
+}
+
+// sampleFormat returns the format of the sample passed with --config-sample,
+// env unless given.
+func sampleFormat(args []string) string {
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return "env"
+		case strings.HasPrefix(arg, "--config-sample="):
+			return strings.TrimPrefix(arg, "--config-sample=")
+		case arg == "--config-sample" && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-"):
+			return args[i+1]
+		}
+	}
+
+	return "env"
+}
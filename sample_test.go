@@ -0,0 +1,122 @@
+package conf
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSample(t *testing.T) {
+	os.Clearenv()
+
+	type config struct {
+		Host     string `conf:"required,help:the host to listen on"`
+		Port     int    `conf:"default:8080"`
+		Greeting string `conf:"default:hello world"`
+		Tags     []string
+		DB       struct {
+			User     string `conf:"default:app"`
+			Password string `conf:"secret:db_password"`
+		}
+	}
+
+	t.Run("env", func(t *testing.T) {
+		got, err := Sample("app", &config{}, "env")
+		if err != nil {
+			t.Fatalf("\t%s\tShould be able to render the .env sample : %s.", failed, err)
+		}
+
+		want := `# The config under the prefix APP.
+
+# the host to listen on
+# (required)
+# APP_HOST=
+
+APP_PORT=8080
+
+APP_GREETING=hello world
+
+# APP_TAGS=
+
+APP_DB_USER=app
+
+# (read from the secret db_password)
+# APP_DB_PASSWORD=
+`
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("\t%s\tShould render the defaults and help :\n%s", failed, diff)
+		}
+		t.Logf("\t%s\tShould render the defaults and help.", success)
+
+		path := filepath.Join(t.TempDir(), ".env")
+		if err := os.WriteFile(path, []byte(got+"APP_HOST=localhost\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		var cfg config
+		if err := Parse("app", &cfg, WithEnvFile(path), WithEnviron(nil), WithArgs(nil), WithSecretsDir(t.TempDir())); err != nil {
+			t.Fatalf("\t%s\tShould be able to parse the filled in sample : %s.", failed, err)
+		}
+		if cfg.Greeting != "hello world" || cfg.Port != 8080 || cfg.DB.User != "app" {
+			t.Fatalf("\t%s\tShould read the defaults back : %+v.", failed, cfg)
+		}
+		t.Logf("\t%s\tShould read the defaults back.", success)
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		got, err := Sample("app", &config{}, "yaml")
+		if err != nil {
+			t.Fatalf("\t%s\tShould be able to render the YAML sample : %s.", failed, err)
+		}
+
+		want := `# The config of app.
+
+# the host to listen on
+# (required)
+# host:
+
+port: 8080
+
+greeting: "hello world"
+
+# tags:
+
+db:
+  user: "app"
+
+  # (read from the secret db_password)
+  # password:
+`
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("\t%s\tShould render the nested structs :\n%s", failed, diff)
+		}
+		t.Logf("\t%s\tShould render the nested structs.", success)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		if _, err := Sample("app", &config{}, "xml"); err == nil {
+			t.Fatalf("\t%s\tShould reject unknown formats.", failed)
+		}
+		t.Logf("\t%s\tShould reject unknown formats.", success)
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		origStdout, origExit := stdout, exit
+		defer func() { stdout, exit = origStdout, origExit }()
+
+		var out bytes.Buffer
+		code := -1
+		stdout, exit = &out, func(c int) { code = c }
+
+		var cfg config
+		MustParse("app", &cfg, WithEnviron(nil), WithArgs([]string{"--config-sample", "yaml"}))
+
+		if code != 0 || !bytes.HasPrefix(out.Bytes(), []byte("# The config of app.")) {
+			t.Fatalf("\t%s\tShould print the sample for --config-sample : %d : %q.", failed, code, out.String())
+		}
+		t.Logf("\t%s\tShould print the sample for --config-sample.", success)
+	})
+}
@@ -0,0 +1,194 @@
+package conf
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// A Schema describes the config surface of a program: the keys, types and
+// defaults of the fields of its config struct. It is meant to be encoded
+// to JSON with every release and compared with the schema of the previous
+// release using DiffSchemas.
+type Schema struct {
+	Fields []SchemaField `json:"fields"`
+}
+
+// SchemaField describes a single field of the config surface.
+type SchemaField struct {
+	Path     string `json:"path"`
+	Env      string `json:"env"`
+	Flag     string `json:"flag"`
+	Type     string `json:"type"`
+	Default  string `json:"default,omitempty"`
+	Required bool   `json:"required,omitempty"`
+	Help     string `json:"help,omitempty"`
+}
+
+// ExportSchema returns the schema of the specified config struct.
+// The struct isn't parsed, the values of its fields are not included.
+func ExportSchema(prefix string, cfg any, opts ...Option) (*Schema, error) {
+	fields, err := Fields(prefix, cfg, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	typ := reflect.TypeOf(cfg).Elem()
+
+	var s Schema
+	for _, field := range fields {
+		s.Fields = append(s.Fields, SchemaField{
+			Path:     goPath(typ, field.index),
+			Env:      field.EnvKey,
+			Flag:     field.FlagKey,
+			Type:     typeName(field.Field.Type()),
+			Default:  defaultText(field),
+			Required: field.Options.Required,
+			Help:     field.Options.Help,
+		})
+	}
+
+	return &s, nil
+}
+
+// defaultText returns the default of the field, naming the file or the
+// embedded file it is read from.
+func defaultText(field Field) string {
+	switch {
+	case field.Options.DefaultEmbed != "":
+		return "embed " + field.Options.DefaultEmbed
+	case field.Options.DefaultFile != "":
+		return "file " + field.Options.DefaultFile
+	}
+
+	return field.Options.DefaultVal
+}
+
+// Drift lists the changes of the config surface between two schemas.
+type Drift struct {
+
+	// Added are the fields only present in the new schema.
+	Added []SchemaField
+
+	// Removed are the fields only present in the old schema.
+	Removed []SchemaField
+
+	// Renamed are the fields whose keys changed while the Go path stayed.
+	Renamed []Rename
+
+	// Defaults are the fields whose defaults changed.
+	Defaults []DefaultChange
+}
+
+// Rename describes a field whose key changed between two schemas.
+type Rename struct {
+	Old SchemaField
+	New SchemaField
+}
+
+// DefaultChange describes a field whose default changed between two schemas.
+type DefaultChange struct {
+	Field SchemaField
+	Old   string
+	New   string
+}
+
+// DiffSchemas reports the changes of the config surface from the schema
+// of an older release to the schema of a newer one.
+// Fields are matched by their env key, a removed and an added field
+// sharing the Go path are reported as renamed.
+func DiffSchemas(from, to *Schema) *Drift {
+	oldFields := make(map[string]SchemaField, len(from.Fields))
+	for _, f := range from.Fields {
+		oldFields[f.Env] = f
+	}
+
+	newFields := make(map[string]SchemaField, len(to.Fields))
+	for _, f := range to.Fields {
+		newFields[f.Env] = f
+	}
+
+	var d Drift
+
+	removed := make(map[string]SchemaField)
+	for _, f := range from.Fields {
+		if _, ok := newFields[f.Env]; !ok {
+			removed[f.Path] = f
+		}
+	}
+
+	for _, f := range to.Fields {
+		prev, ok := oldFields[f.Env]
+		if !ok {
+			if prev, ok := removed[f.Path]; ok {
+				delete(removed, f.Path)
+				d.Renamed = append(d.Renamed, Rename{Old: prev, New: f})
+				if prev.Default != f.Default {
+					d.Defaults = append(d.Defaults, DefaultChange{Field: f, Old: prev.Default, New: f.Default})
+				}
+				continue
+			}
+			d.Added = append(d.Added, f)
+			continue
+		}
+
+		if prev.Default != f.Default {
+			d.Defaults = append(d.Defaults, DefaultChange{Field: f, Old: prev.Default, New: f.Default})
+		}
+	}
+
+	for _, f := range from.Fields {
+		if _, ok := removed[f.Path]; ok {
+			d.Removed = append(d.Removed, f)
+		}
+	}
+
+	return &d
+}
+
+// Empty reports whether the config surface didn't change.
+func (d *Drift) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Renamed) == 0 && len(d.Defaults) == 0
+}
+
+// String renders the drift as a migration note for operators.
+func (d *Drift) String() string {
+	var b strings.Builder
+
+	if len(d.Renamed) > 0 {
+		b.WriteString("Renamed settings, update your configuration:\n")
+		for _, r := range d.Renamed {
+			fmt.Fprintf(&b, "  %s -> %s (--%s -> --%s)\n", r.Old.Env, r.New.Env, r.Old.Flag, r.New.Flag)
+		}
+	}
+
+	if len(d.Removed) > 0 {
+		b.WriteString("Removed settings, they are ignored now:\n")
+		for _, f := range d.Removed {
+			fmt.Fprintf(&b, "  %s (--%s)\n", f.Env, f.Flag)
+		}
+	}
+
+	if len(d.Added) > 0 {
+		b.WriteString("New settings:\n")
+		for _, f := range d.Added {
+			status := ""
+			switch {
+			case f.Required:
+				status = " required"
+			case f.Default != "":
+				status = " default: " + f.Default
+			}
+			fmt.Fprintf(&b, "  %s (--%s) <%s>%s\n", f.Env, f.Flag, f.Type, status)
+		}
+	}
+
+	if len(d.Defaults) > 0 {
+		b.WriteString("Changed defaults, set the old value to keep the behavior:\n")
+		for _, c := range d.Defaults {
+			fmt.Fprintf(&b, "  %s: %q -> %q\n", c.Field.Env, c.Old, c.New)
+		}
+	}
+
+	return b.String()
+}
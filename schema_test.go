@@ -0,0 +1,78 @@
+package conf
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDiffSchemas(t *testing.T) {
+	var v1 struct {
+		Port    int    `conf:"default:8080"`
+		Host    string `conf:"env:HOST"`
+		Debug   bool
+		Timeout string `conf:"default:5s"`
+	}
+
+	var v2 struct {
+		Port    int    `conf:"default:9090"`
+		Host    string `conf:"env:LISTEN_HOST"`
+		Timeout string `conf:"default:5s"`
+		Token   string `conf:"required"`
+	}
+
+	from, err := ExportSchema("test", &v1)
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to export schema : %s.", failed, err)
+	}
+
+	// The schema of the older release is typically read from a file.
+	data, err := json.Marshal(from)
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to encode schema : %s.", failed, err)
+	}
+	from = new(Schema)
+	if err := json.Unmarshal(data, from); err != nil {
+		t.Fatalf("\t%s\tShould be able to decode schema : %s.", failed, err)
+	}
+	t.Logf("\t%s\tShould be able to export schema.", success)
+
+	to, err := ExportSchema("test", &v2)
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to export schema : %s.", failed, err)
+	}
+
+	d := DiffSchemas(from, to)
+
+	if len(d.Added) != 1 || d.Added[0].Env != "TEST_TOKEN" {
+		t.Fatalf("\t%s\tShould report added fields : %+v.", failed, d.Added)
+	}
+	if len(d.Removed) != 1 || d.Removed[0].Env != "TEST_DEBUG" {
+		t.Fatalf("\t%s\tShould report removed fields : %+v.", failed, d.Removed)
+	}
+	if len(d.Renamed) != 1 || d.Renamed[0].Old.Env != "HOST" || d.Renamed[0].New.Env != "LISTEN_HOST" {
+		t.Fatalf("\t%s\tShould report renamed fields : %+v.", failed, d.Renamed)
+	}
+	if len(d.Defaults) != 1 || d.Defaults[0].Old != "8080" || d.Defaults[0].New != "9090" {
+		t.Fatalf("\t%s\tShould report changed defaults : %+v.", failed, d.Defaults)
+	}
+	t.Logf("\t%s\tShould report the drift.", success)
+
+	note := d.String()
+	for _, want := range []string{
+		"HOST -> LISTEN_HOST (--host -> --host)",
+		"TEST_DEBUG (--debug)",
+		"TEST_TOKEN (--token) <string> required",
+		`TEST_PORT: "8080" -> "9090"`,
+	} {
+		if !strings.Contains(note, want) {
+			t.Fatalf("\t%s\tShould render %q in the migration note :\n%s", failed, want, note)
+		}
+	}
+	t.Logf("\t%s\tShould render the migration note :\n%s", success, note)
+
+	if !DiffSchemas(to, to).Empty() {
+		t.Fatalf("\t%s\tShould report no drift for the same schema.", failed)
+	}
+	t.Logf("\t%s\tShould report no drift for the same schema.", success)
+}
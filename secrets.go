@@ -0,0 +1,50 @@
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// WithSecretsDir sets the directory the fields tagged with `secret` are
+// read from, /run/secrets by default.
+func WithSecretsDir(dir string) Option {
+	return func(o *options) {
+		o.secrets = dir
+	}
+}
+
+// secretsSource provides the values of fields tagged with `secret` from
+// the files of Docker and Swarm secrets. The file is named by the tag,
+// as in `secret:db_password`, or the lowercase env key of the field.
+// Trailing newlines are trimmed, missing files leave the field to the
+// files and the defaults. The first error reading an existing file is
+// kept in fileErr, failing the parse.
+type secretsSource struct {
+	dir     string
+	fsys    fs.FS
+	fileErr *error
+}
+
+func (s secretsSource) Source(fld Field) (string, bool) {
+	if !fld.Options.Secret {
+		return "", false
+	}
+
+	name := fld.Options.SecretName
+	if name == "" {
+		name = strings.ToLower(fld.EnvKey)
+	}
+
+	data, err := readFile(s.fsys, filepath.Join(s.dir, name))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) && s.fileErr != nil && *s.fileErr == nil {
+			*s.fileErr = fmt.Errorf("read secret %s: %w", name, err)
+		}
+		return "", false
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), true
+}
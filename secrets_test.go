@@ -0,0 +1,68 @@
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParse_Secrets(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "db_password"), []byte("s3cret\n"), 0o600)
+	_ = os.WriteFile(filepath.Join(dir, "test_api_key"), []byte("key\r\n"), 0o600)
+	_ = os.WriteFile(filepath.Join(dir, "test_host"), []byte("ignored"), 0o600)
+
+	os.Clearenv()
+	_ = os.Setenv("TEST_TOKEN", "env")
+
+	var cfg struct {
+		DBPassword string `conf:"secret:db_password"`
+		APIKey     string `conf:"secret"`
+		Token      string `conf:"secret,default:none"`
+		Missing    string `conf:"secret,default:fallback"`
+		Host       string
+	}
+
+	if err := Parse("test", &cfg, WithSecretsDir(dir)); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse secrets : %s.", failed, err)
+	}
+
+	if cfg.DBPassword != "s3cret" || cfg.APIKey != "key" {
+		t.Fatalf("\t%s\tShould have read secrets without trailing newlines : %+v.", failed, cfg)
+	}
+	t.Logf("\t%s\tShould have read secrets without trailing newlines.", success)
+
+	if cfg.Token != "env" || cfg.Missing != "fallback" || cfg.Host != "" {
+		t.Fatalf("\t%s\tShould read secrets of tagged fields below the env only : %+v.", failed, cfg)
+	}
+	t.Logf("\t%s\tShould read secrets of tagged fields below the env only.", success)
+
+	out, err := String(&cfg)
+	if err != nil || strings.Contains(out, "s3cret") {
+		t.Fatalf("\t%s\tShould mask secrets in printed config : %v\n%s", failed, err, out)
+	}
+	t.Logf("\t%s\tShould mask secrets in printed config.", success)
+}
+
+func TestParse_SecretsReadError(t *testing.T) {
+	os.Clearenv()
+
+	var cfg struct {
+		Password string `conf:"secret:db_password"`
+	}
+
+	fsys := fstest.MapFS{"run/secrets/db_password/data": {Data: []byte("s3cret")}}
+
+	err := Parse("test", &cfg, WithEnviron(nil), WithArgs(nil), WithFS(fsys))
+	if err == nil || !strings.Contains(err.Error(), "read secret db_password") {
+		t.Fatalf("\t%s\tShould fail to parse when a secret can't be read : %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould fail to parse when a secret can't be read.", success)
+
+	if err := Parse("test", &cfg, WithEnviron(nil), WithArgs(nil), WithFS(fstest.MapFS{})); err != nil {
+		t.Fatalf("\t%s\tShould ignore missing secrets : %s.", failed, err)
+	}
+	t.Logf("\t%s\tShould ignore missing secrets.", success)
+}
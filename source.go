@@ -0,0 +1,303 @@
+package conf
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sourcer is implemented by types providing values for the fields of
+// a config struct, such as the environment, files or remote stores.
+type Sourcer interface {
+
+	// Source returns the value for the field and reports whether
+	// the value was found.
+	Source(fld Field) (string, bool)
+}
+
+// Env returns the Sourcer looking up the field env keys in the
+// environment. It is the source used when none are provided with
+// WithSources.
+// A snapshot of the environment is taken at the start of every parse
+// and all fields are resolved from it, so concurrent changes of the
+// environment can't produce a half-old/half-new config.
+func Env() Sourcer {
+	return envSource{}
+}
+
+type envSource struct {
+	env map[string]string
+
+	// files enables the <KEY>_FILE variables for all fields, the first
+	// error reading such a file or assembling chunks is kept in fileErr
+	// for the parse.
+	files   bool
+	fileErr *error
+
+	// chunks enables the <KEY>_PART<n> variables for all fields.
+	chunks bool
+
+	// fsys is the file system the <KEY>_FILE variables name files of.
+	fsys fs.FS
+}
+
+func (s envSource) Source(fld Field) (string, bool) {
+	if value, ok := s.lookup(fld.EnvKey); ok {
+		return value, true
+	}
+
+	if s.chunks || fld.Options.Chunked {
+		if value, ok := s.chunked(fld.EnvKey); ok {
+			return value, true
+		}
+	}
+
+	if !s.files && !fld.Options.File {
+		return "", false
+	}
+
+	path, ok := s.lookup(fld.EnvKey + "_FILE")
+	if !ok {
+		return "", false
+	}
+
+	data, err := readFile(s.fsys, path)
+	if err != nil {
+		if s.fileErr != nil && *s.fileErr == nil {
+			*s.fileErr = fmt.Errorf("read %s_FILE: %w", fld.EnvKey, err)
+		}
+		return "", false
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), true
+}
+
+// chunked joins the value split across the <KEY>_PART1 to <KEY>_PARTn
+// variables by platforms capping the length of variables. The parts must
+// run from 1 without gaps.
+func (s envSource) chunked(key string) (string, bool) {
+	var b strings.Builder
+
+	n := 0
+	for {
+		part, ok := s.lookup(fmt.Sprintf("%s_PART%d", key, n+1))
+		if !ok {
+			break
+		}
+		b.WriteString(part)
+		n++
+	}
+
+	for k := range s.env {
+		if i, ok := chunkIndex(k, key); ok && i > n {
+			if s.fileErr != nil && *s.fileErr == nil {
+				*s.fileErr = fmt.Errorf("%s_PART%d is set without %s_PART%d, parts must run from 1 without gaps", key, i, key, n+1)
+			}
+			return "", false
+		}
+	}
+
+	return b.String(), n > 0
+}
+
+// chunkIndex returns the index of the part if the key is a <KEY>_PART<n>
+// variable of the env key.
+func chunkIndex(key, envKey string) (int, bool) {
+	digits, ok := strings.CutPrefix(key, envKey+"_PART")
+	if !ok || digits == "" || digits[0] == '0' {
+		return 0, false
+	}
+
+	i, err := strconv.Atoi(digits)
+	if err != nil || i < 1 {
+		return 0, false
+	}
+
+	return i, true
+}
+
+func (s envSource) lookup(key string) (string, bool) {
+	if s.env == nil {
+		return os.LookupEnv(key)
+	}
+
+	value, ok := s.env[key]
+	return value, ok
+}
+
+// snapshotEnv returns a consistent copy of the environment.
+func snapshotEnv() map[string]string {
+	environ := os.Environ()
+
+	env := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		k, v, _ := strings.Cut(kv, "=")
+		env[k] = v
+	}
+
+	return env
+}
+
+// environment returns the environment the parse may read: the one set with
+// WithEnviron or a snapshot of the process environment, limited to the
+// variables allowed and not denied with WithAllowedEnv and WithDeniedEnv.
+func (o *options) environment() map[string]string {
+	env := o.environ
+	if env == nil {
+		env = snapshotEnv()
+	}
+
+	if o.allowEnv == nil && o.denyEnv == nil {
+		return env
+	}
+
+	out := make(map[string]string, len(env))
+	for k, v := range env {
+		if o.allowEnv != nil && !matchKey(o.allowEnv, k) || matchKey(o.denyEnv, k) {
+			continue
+		}
+		out[k] = v
+	}
+
+	return out
+}
+
+// matchKey reports whether the key matches any of the patterns, which use
+// the syntax of path.Match such as PLUGIN_*.
+func matchKey(patterns []string, key string) bool {
+	for _, pattern := range patterns {
+		if ok, err := path.Match(pattern, key); ok || err != nil && pattern == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// prepareSources binds the env sources to the environment for a single parse,
+// the errors reading <KEY>_FILE variables, secrets and assembling chunks are
+// kept in fileErr.
+func prepareSources(sources []Sourcer, env map[string]string, o *options, fileErr *error) []Sourcer {
+	out := make([]Sourcer, 0, len(sources))
+
+	for _, src := range sources {
+		switch s := src.(type) {
+		case envSource:
+			if s.env == nil {
+				s.env, s.files, s.chunks, s.fileErr = env, o.fileEnv, o.chunkedEnv, fileErr
+				s.fsys = o.fsys
+				src = s
+			}
+		case secretsSource:
+			s.fileErr = fileErr
+			src = s
+		case *rewriteSource:
+			inner := prepareSources([]Sourcer{s.src}, env, o, fileErr)
+			src = &rewriteSource{src: inner[0], rewriters: s.rewriters}
+		}
+		out = append(out, src)
+	}
+
+	return out
+}
+
+// mapSource provides values keyed by the field env key.
+type mapSource map[string]string
+
+func (m mapSource) Source(fld Field) (string, bool) {
+	value, ok := m[fld.EnvKey]
+	return value, ok
+}
+
+// sourceField returns the value of the field from the first of the sources
+// providing it, and the index of that source.
+func sourceField(field Field, sources []Sourcer) (string, int, bool) {
+	for i, src := range sources {
+		if value, ok := src.Source(field); ok {
+			return value, i, true
+		}
+	}
+
+	return "", 0, false
+}
+
+// resolveValues collects the values for fields from the pinned values, the
+// command line flags, the sources, the secrets and the files, keyed by the
+// field env key. Pinned values take precedence over flags, which take
+// precedence over the sources, which default to the environment, followed
+// by the secrets, the files and the config file passed with --config.
+func resolveValues(fields []Field, o *options) (map[string]string, error) {
+	sources := o.sources
+	if len(sources) == 0 {
+		sources = []Sourcer{Env()}
+	}
+
+	sources = append(sources[:len(sources):len(sources)], secretsSource{dir: o.secrets, fsys: o.fsys})
+	sources = append(sources, o.files...)
+
+	// A config file passed on the command line is merged below the rest.
+	if path, ok := configFlag(fields, o.args); ok {
+		sources = append(sources, configFileSource(path))
+	}
+
+	var fileErr error
+	sources = prepareSources(sources, o.env, o, &fileErr)
+
+	if err := checkHelp(fields, o.args, o.versioned); err != nil {
+		return nil, err
+	}
+
+	flagValues, err := parseFlags(fields, o.args)
+	if err != nil {
+		return nil, fmt.Errorf("parse flags: %w", err)
+	}
+
+	for _, src := range sources {
+		if err := loadSource(src, o); err != nil {
+			return nil, err
+		}
+	}
+
+	names := []string{"value", "flag"}
+	for _, src := range sources {
+		names = append(names, originName(src))
+	}
+	sources = append([]Sourcer{mapSource(o.values), mapSource(flagValues)}, sources...)
+
+	values := make(map[string]string)
+	o.origins = make(map[string]string)
+
+	for _, field := range fields {
+		start := time.Now()
+		if value, i, ok := sourceField(field, sources); ok {
+			values[field.EnvKey] = value
+			o.origins[field.EnvKey] = names[i]
+		} else {
+
+			// The aliases of the field, such as its legacy names, are
+			// consulted only when no source provides the field itself.
+			for _, alias := range field.Options.Aliases {
+				aliased := field
+				aliased.EnvKey = alias
+
+				if value, i, ok := sourceField(aliased, sources); ok {
+					values[field.EnvKey] = value
+					o.origins[field.EnvKey] = names[i]
+					o.warn(Warning{Key: alias, Message: fmt.Sprintf("deprecated name of %s, rename it", field.EnvKey)})
+					break
+				}
+			}
+		}
+		o.addTiming(field.EnvKey, time.Since(start))
+	}
+
+	if fileErr != nil {
+		return nil, fileErr
+	}
+
+	return values, nil
+}
@@ -0,0 +1,107 @@
+package conf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// WithStrict fails the parse when the environment holds variables under the
+// prefix which don't belong to any field, such as TEST_DEBUG_HSOT, catching
+// typos which would silently leave fields to their defaults.
+func WithStrict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// WithUnknownWarnings reports the variables under the prefix which don't
+// belong to any field to the function set with WithWarnings, without
+// failing the parse like WithStrict.
+func WithUnknownWarnings() Option {
+	return func(o *options) {
+		o.unknownWarnings = true
+	}
+}
+
+// isChunk reports whether the key is a <KEY>_PART<n> variable of any of
+// the env keys.
+func isChunk(key string, envKeys []string) bool {
+	for _, envKey := range envKeys {
+		if _, ok := chunkIndex(key, envKey); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkUnknownKeys reports the variables under the prefix which don't
+// belong to any field with WithStrict or WithUnknownWarnings. Nothing is
+// checked without a prefix as every variable of the environment would be
+// under it.
+func checkUnknownKeys(prefix string, fields []Field, o *options) error {
+	if prefix == "" || !o.strict && !o.unknownWarnings {
+		return nil
+	}
+
+	known := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		known[field.EnvKey] = true
+		for _, alias := range field.Options.Aliases {
+			known[alias] = true
+		}
+		if o.fileEnv || field.Options.File {
+			known[field.EnvKey+"_FILE"] = true
+		}
+	}
+
+	var chunked []string
+	for _, field := range fields {
+		if o.chunkedEnv || field.Options.Chunked {
+			chunked = append(chunked, field.EnvKey)
+		}
+	}
+
+	head := strings.ToUpper(prefix) + o.separator
+
+	var unknown []string
+	for key := range o.env {
+		if strings.HasPrefix(key, head) && !known[key] && !isChunk(key, chunked) {
+			unknown = append(unknown, key)
+		}
+	}
+
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+
+	keys := make([]string, 0, len(fields))
+	for _, field := range fields {
+		keys = append(keys, field.EnvKey)
+	}
+
+	if o.strict {
+		descs := make([]string, len(unknown))
+		for i, key := range unknown {
+			descs[i] = key
+			if c := closest(key, keys); c != "" {
+				descs[i] = fmt.Sprintf("%s (did you mean %s?)", key, c)
+			}
+		}
+
+		return fmt.Errorf("unknown variables under the prefix %s: %s", strings.ToUpper(prefix), strings.Join(descs, ", "))
+	}
+
+	for _, key := range unknown {
+		msg := "variable doesn't belong to any field"
+		if c := closest(key, keys); c != "" {
+			msg += ", did you mean " + c + "?"
+		}
+		o.warn(Warning{Key: key, Message: msg})
+	}
+
+	return nil
+}
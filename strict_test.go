@@ -0,0 +1,100 @@
+package conf
+
+import (
+	"os"
+	"testing"
+)
+
+func TestStrict(t *testing.T) {
+	os.Clearenv()
+
+	type config struct {
+		Debug struct {
+			Host string
+		}
+		Password string `conf:"file"`
+	}
+
+	env := map[string]string{
+		"TEST_DEBUG_HOST":     "localhost",
+		"TEST_PASSWORD_FILE":  "/dev/null",
+		"TEST_DEBUG_HSOT":     "typo",
+		"TEST_DEBUG_PORT_OLD": "1",
+		"OTHER_DEBUG_HOST":    "other",
+	}
+
+	var cfg config
+	err := Parse("test", &cfg, WithEnviron(env), WithArgs(nil), WithStrict())
+
+	want := "unknown variables under the prefix TEST: TEST_DEBUG_HSOT (did you mean TEST_DEBUG_HOST?), TEST_DEBUG_PORT_OLD"
+	if err == nil || err.Error() != want {
+		t.Fatalf("\t%s\tShould fail with %q : %v.", failed, want, err)
+	}
+	t.Logf("\t%s\tShould fail on unknown variables in strict mode.", success)
+
+	var warnings []Warning
+	if err := Parse("test", &cfg, WithEnviron(env), WithArgs(nil), WithWarnings(func(w Warning) { warnings = append(warnings, w) })); err != nil {
+		t.Fatalf("\t%s\tShould parse without strict mode : %s.", failed, err)
+	}
+
+	if len(warnings) != 0 {
+		t.Fatalf("\t%s\tShould not look for unknown variables by default : %v.", failed, warnings)
+	}
+	t.Logf("\t%s\tShould not look for unknown variables by default.", success)
+
+	if err := Parse("test", &cfg, WithEnviron(env), WithArgs(nil), WithUnknownWarnings(), WithWarnings(func(w Warning) { warnings = append(warnings, w) })); err != nil {
+		t.Fatalf("\t%s\tShould parse without strict mode : %s.", failed, err)
+	}
+
+	if len(warnings) != 2 || warnings[0].Key != "TEST_DEBUG_HSOT" || warnings[1].Key != "TEST_DEBUG_PORT_OLD" {
+		t.Fatalf("\t%s\tShould warn about unknown variables : %v.", failed, warnings)
+	}
+	t.Logf("\t%s\tShould warn about unknown variables.", success)
+
+	delete(env, "TEST_DEBUG_HSOT")
+	delete(env, "TEST_DEBUG_PORT_OLD")
+	if err := Parse("test", &cfg, WithEnviron(env), WithArgs(nil), WithStrict()); err != nil {
+		t.Fatalf("\t%s\tShould parse known variables in strict mode : %s.", failed, err)
+	}
+	t.Logf("\t%s\tShould parse known variables in strict mode.", success)
+}
+
+func TestSuggestions(t *testing.T) {
+	os.Clearenv()
+
+	var cfg struct {
+		Debug struct {
+			Host string `conf:"required"`
+		}
+		Port int `conf:"required"`
+	}
+
+	env := map[string]string{"TEST_DEBUG_HSOT": "localhost", "TEST_PORT": "80"}
+
+	err := Parse("test", &cfg, WithEnviron(env), WithArgs(nil))
+
+	want := "required field Debug.Host (TEST_DEBUG_HOST) is missing value, TEST_DEBUG_HSOT found; did you mean TEST_DEBUG_HOST?"
+	if err == nil || err.Error() != want {
+		t.Fatalf("\t%s\tShould suggest the misspelled variable : %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould suggest the misspelled variable.", success)
+
+	var warnings []Warning
+	_ = Parse("test", &cfg, WithEnviron(env), WithArgs(nil), WithUnknownWarnings(), WithWarnings(func(w Warning) { warnings = append(warnings, w) }))
+
+	if len(warnings) != 1 || warnings[0].String() != "TEST_DEBUG_HSOT: variable doesn't belong to any field, did you mean TEST_DEBUG_HOST?" {
+		t.Fatalf("\t%s\tShould suggest the field in warnings : %v.", failed, warnings)
+	}
+	t.Logf("\t%s\tShould suggest the field in warnings.", success)
+
+	for _, tt := range []struct{ key, want string }{
+		{"TEST_PROT", "TEST_PORT"},
+		{"TEST_DEBUG_HOSTS", "TEST_DEBUG_HOST"},
+		{"TEST_TIMEOUT", ""},
+	} {
+		if got := closest(tt.key, []string{"TEST_PORT", "TEST_DEBUG_HOST"}); got != tt.want {
+			t.Fatalf("\t%s\tShould suggest %q for %s : %q.", failed, tt.want, tt.key, got)
+		}
+	}
+	t.Logf("\t%s\tShould suggest only close keys.", success)
+}
@@ -0,0 +1,130 @@
+package conf
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maskedValue replaces the values of fields tagged with `mask`.
+const maskedValue = "xxxxxx"
+
+// String renders the effective configuration of the specified config struct
+// as KEY=value lines, using the env keys without a prefix. Values of fields
+// tagged with `mask` or `secret` are printed as xxxxxx unless redacted
+// otherwise with WithRedaction, fields tagged with `noprint` are left out.
+func String(cfg any, opts ...Option) (string, error) {
+	o := newOptions(opts)
+
+	fields, err := extractFields("", o.separator, nil, nil, cfg)
+	if err != nil {
+		return "", fmt.Errorf("extract fields from config struct: %w", err)
+	}
+
+	if err := bindOptions(fields, o); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+
+	for _, field := range fields {
+		if field.Options.NoPrint {
+			continue
+		}
+
+		value := redactValue(formatValue(field.Field, field.Options), field)
+
+		fmt.Fprintf(&b, "%s=%s\n", field.EnvKey, value)
+	}
+
+	return b.String(), nil
+}
+
+// unredacter is implemented by the types whose String redacts their
+// passwords, rendering them in full.
+type unredacter interface {
+	unredacted() string
+}
+
+// formatValue renders the value in the form accepted by processField.
+func formatValue(v reflect.Value, opts FieldOptions) string {
+	return encodeValue(v, opts, false)
+}
+
+// plainValue renders the value like formatValue without redacting the
+// passwords of DSNs and proxy URLs, to be parsed back in full.
+func plainValue(v reflect.Value, opts FieldOptions) string {
+	return encodeValue(v, opts, true)
+}
+
+// encodeValue renders the value, in full if plain.
+func encodeValue(v reflect.Value, opts FieldOptions, plain bool) string {
+	v = lazyTarget(v)
+
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	if plain {
+		var u unredacter
+		interfaceFrom(v, func(i any, ok *bool) { u, *ok = i.(unredacter) })
+		if u != nil {
+			return u.unredacted()
+		}
+	}
+
+	if v.Type() == timeType && v.CanInterface() {
+		return v.Interface().(time.Time).Format(timeLayout(opts))
+	}
+
+	var (
+		s  fmt.Stringer
+		tm encoding.TextMarshaler
+	)
+	interfaceFrom(v, func(i any, ok *bool) { s, *ok = i.(fmt.Stringer) })
+	if s != nil {
+		return s.String()
+	}
+
+	interfaceFrom(v, func(i any, ok *bool) { tm, *ok = i.(encoding.TextMarshaler) })
+	if tm != nil {
+		if text, err := tm.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes())
+		}
+
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = quoteItem(encodeValue(v.Index(i), opts, plain), opts.Sep)
+		}
+
+		return strings.Join(items, opts.Sep)
+	case reflect.Map:
+		items := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			items = append(items, quoteItem(encodeValue(iter.Key(), opts, plain), opts.Sep, opts.KVSep)+opts.KVSep+quoteItem(encodeValue(iter.Value(), opts, plain), opts.Sep, opts.KVSep))
+		}
+		sort.Strings(items)
+
+		return strings.Join(items, opts.Sep)
+	}
+
+	if !v.CanInterface() {
+		return ""
+	}
+
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,42 @@
+package conf
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestString(t *testing.T) {
+	os.Clearenv()
+
+	cfg := struct {
+		Host     string
+		Password string `conf:"mask"`
+		Secret   string `conf:"noprint"`
+		Timeout  time.Duration
+		Hosts    []string
+		Limits   map[string]int
+		Custom   CustomValue
+		Missing  *int
+	}{
+		Host:     "localhost",
+		Password: "gopher",
+		Secret:   "hidden",
+		Timeout:  time.Second,
+		Hosts:    []string{"a", "b"},
+		Limits:   map[string]int{"b": 2, "a": 1},
+		Custom:   CustomValue{something: "@hello@"},
+	}
+
+	out, err := String(&cfg)
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to render config : %s.", failed, err)
+	}
+	t.Logf("\t%s\tShould be able to render config.", success)
+
+	want := "HOST=localhost\nPASSWORD=xxxxxx\nTIMEOUT=1s\nHOSTS=a;b\nLIMITS=a:1;b:2\nCUSTOM=@hello@\nMISSING=\n"
+	if out != want {
+		t.Fatalf("\t%s\tShould render masked config\ngot:\n%s\nwant:\n%s", failed, out, want)
+	}
+	t.Logf("\t%s\tShould render masked config.", success)
+}
@@ -0,0 +1,95 @@
+package conf
+
+// closest returns the candidate nearest to the key, for suggesting the
+// intended key of a misspelled one. Candidates further than a couple of
+// edits, or a fifth of the key for longer keys, aren't suggested.
+func closest(key string, candidates []string) string {
+	limit := max(2, len(key)/5)
+
+	var (
+		best     string
+		bestDist = limit + 1
+	)
+
+	for _, c := range candidates {
+		if c == key {
+			continue
+		}
+
+		d := levenshtein(key, c)
+		if d < bestDist || d == bestDist && c < best {
+			best, bestDist = c, d
+		}
+	}
+
+	if bestDist > limit {
+		return ""
+	}
+
+	return best
+}
+
+// levenshtein returns the number of single byte insertions, deletions
+// and substitutions turning a into b.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+// missingHints suggests for the required fields without a value the
+// variables of the environment which were likely meant to set them,
+// keyed by the field env key.
+func missingHints(fields []Field, values map[string]string, o *options) map[string]string {
+	var missing []Field
+	for _, field := range fields {
+		if _, ok := values[field.EnvKey]; field.Options.Required && !ok {
+			missing = append(missing, field)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	known := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		known[field.EnvKey] = true
+		for _, alias := range field.Options.Aliases {
+			known[alias] = true
+		}
+	}
+
+	var candidates []string
+	for key := range o.env {
+		if !known[key] {
+			candidates = append(candidates, key)
+		}
+	}
+
+	hints := make(map[string]string)
+	for _, field := range missing {
+		if c := closest(field.EnvKey, candidates); c != "" {
+			hints[field.EnvKey] = c
+		}
+	}
+
+	return hints
+}
@@ -0,0 +1,54 @@
+package conf
+
+import (
+	htmltemplate "html/template"
+	"text/template"
+)
+
+// Template holds a text/template compiled from the value at parse time,
+// so syntax errors fail the parse instead of the first render.
+type Template struct {
+	*template.Template
+	text string
+}
+
+// Set implements the Setter interface.
+func (t *Template) Set(data string) error {
+	tmpl, err := template.New("config").Parse(data)
+	if err != nil {
+		return err
+	}
+
+	*t = Template{Template: tmpl, text: data}
+
+	return nil
+}
+
+// String returns the source of the template.
+func (t Template) String() string {
+	return t.text
+}
+
+// HTMLTemplate holds an html/template compiled from the value at parse
+// time, so syntax errors fail the parse instead of the first render.
+type HTMLTemplate struct {
+	*htmltemplate.Template
+	text string
+}
+
+// Set implements the Setter interface.
+func (t *HTMLTemplate) Set(data string) error {
+	tmpl, err := htmltemplate.New("config").Parse(data)
+	if err != nil {
+		return err
+	}
+
+	*t = HTMLTemplate{Template: tmpl, text: data}
+
+	return nil
+}
+
+// String returns the source of the template.
+func (t HTMLTemplate) String() string {
+	return t.text
+}
@@ -0,0 +1,52 @@
+package conf
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParse_Template(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("TEST_GREETING", "Hello, {{.}}!")
+	_ = os.Setenv("TEST_PAGE", "<p>{{.}}</p>")
+
+	var cfg struct {
+		Greeting Template
+		Page     HTMLTemplate
+	}
+
+	if err := Parse("test", &cfg); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse templates : %s.", failed, err)
+	}
+	t.Logf("\t%s\tShould be able to parse templates.", success)
+
+	var b strings.Builder
+	if err := cfg.Greeting.Execute(&b, "gopher"); err != nil || b.String() != "Hello, gopher!" {
+		t.Fatalf("\t%s\tShould render text template, got %q : %v.", failed, b.String(), err)
+	}
+	t.Logf("\t%s\tShould render text template.", success)
+
+	b.Reset()
+	if err := cfg.Page.Execute(&b, "<b>gopher</b>"); err != nil || b.String() != "<p>&lt;b&gt;gopher&lt;/b&gt;</p>" {
+		t.Fatalf("\t%s\tShould render escaped html template, got %q : %v.", failed, b.String(), err)
+	}
+	t.Logf("\t%s\tShould render escaped html template.", success)
+
+	out, err := String(&cfg)
+	if err != nil || !strings.Contains(out, "GREETING=Hello, {{.}}!") {
+		t.Fatalf("\t%s\tShould print template source : %v\n%s", failed, err, out)
+	}
+	t.Logf("\t%s\tShould print template source.", success)
+
+	os.Clearenv()
+	_ = os.Setenv("TEST_GREETING", "Hello, {{.Name}")
+
+	var fe *FieldError
+	err = Parse("test", &cfg)
+	if !errors.As(err, &fe) || fe.envKey != "TEST_GREETING" || !strings.Contains(err.Error(), "template: config:1:") {
+		t.Fatalf("\t%s\tShould report template syntax error as field error : %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould report template syntax error as field error : %s.", success, err)
+}
@@ -0,0 +1,25 @@
+package conf
+
+import (
+	"github.com/BurntSushi/toml"
+)
+
+// WithTomlFile reads values from the TOML file at path, merged below the
+// sources which take precedence. Nested tables map to nested struct fields,
+// e.g. name in the [ip] table sets IP.Name. The file is read on every parse.
+func WithTomlFile(path string) Option {
+	return withFile(tomlFileSource(path))
+}
+
+func tomlFileSource(path string) *fileSource {
+	return &fileSource{path: path, format: "toml", decode: decodeToml}
+}
+
+func decodeToml(data []byte) (map[string]any, error) {
+	tree := make(map[string]any)
+	if err := toml.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
@@ -0,0 +1,61 @@
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const tomlConfig = `
+an_int = 3
+a_string = "toml"
+debug_host = "http://toml:4000"
+duration = "2m"
+
+[ip]
+name = "toml-name"
+endpoints = ["10.0.0.1:80", "10.0.0.2:80"]
+`
+
+func TestParse_TomlFile(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("TEST_A_STRING", "env")
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(tomlConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := config{3, "env", false, "", ip{"toml-name", "127.0.0.0", []string{"10.0.0.1:80", "10.0.0.2:80"}}, "http://toml:4000", "password", CustomValue{something: "@hello@"}, Embed{"sergey", 2 * time.Minute}}
+
+	t.Run("option", func(t *testing.T) {
+		var cfg config
+		if err := Parse("test", &cfg, WithTomlFile(path)); err != nil {
+			t.Fatalf("\t%s\tShould be able to parse from toml file : %s.", failed, err)
+		}
+
+		if diff := cmp.Diff(want, cfg); diff != "" {
+			t.Fatalf("\t%s\tShould have merged toml file below env variables\n%s", failed, diff)
+		}
+		t.Logf("\t%s\tShould have merged toml file below env variables.", success)
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		args := os.Args
+		defer func() { os.Args = args }()
+		os.Args = []string{"app", "--config", path}
+
+		var cfg config
+		if err := Parse("test", &cfg); err != nil {
+			t.Fatalf("\t%s\tShould be able to parse from --config toml file : %s.", failed, err)
+		}
+
+		if diff := cmp.Diff(want, cfg); diff != "" {
+			t.Fatalf("\t%s\tShould have merged --config toml file below env variables\n%s", failed, diff)
+		}
+		t.Logf("\t%s\tShould have merged --config toml file below env variables.", success)
+	})
+}
@@ -0,0 +1,143 @@
+package conf
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// WithUsageValues makes Usage show the value every field gets from the
+// sources and the name of the source next to its default, so --help run in
+// a live environment doubles as an inspection of the config. The values
+// are redacted like in the errors.
+func WithUsageValues() Option {
+	return func(o *options) {
+		o.usageValues = true
+	}
+}
+
+// Usage renders the help screen for the specified config struct listing
+// every field with its flag, env variable, type, default or required
+// status and the text of the `help` tag.
+func Usage(prefix string, cfg any, opts ...Option) (string, error) {
+	o := newOptions(opts)
+
+	fields, err := extractFields(prefix, o.separator, nil, nil, cfg)
+	if err != nil {
+		return "", fmt.Errorf("extract fields from config struct: %w", err)
+	}
+
+	var values map[string]string
+	if o.usageValues {
+		if err := bindOptions(fields, o); err != nil {
+			return "", err
+		}
+		if values, err = usageValues(fields, o); err != nil {
+			return "", err
+		}
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Usage: %s [options...]\n\nOPTIONS\n", filepath.Base(os.Args[0]))
+
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	for _, field := range fields {
+		var status string
+		switch {
+		case field.Options.Required:
+			status = "(required)"
+		case field.Options.Lazy:
+			status = "(required when used)"
+		case field.Options.DefaultVal != "":
+			status = fmt.Sprintf("(default: %s)", field.Options.DefaultVal)
+		case field.Options.DefaultFile != "":
+			status = fmt.Sprintf("(default file: %s)", field.Options.DefaultFile)
+		case field.Options.DefaultEmbed != "":
+			status = fmt.Sprintf("(default embed: %s)", field.Options.DefaultEmbed)
+		}
+
+		if value, ok := values[field.EnvKey]; ok {
+			status = strings.TrimSpace(fmt.Sprintf("%s [current: %s from %s]", status, redactValue(value, field), o.origins[field.EnvKey]))
+		}
+
+		flag := "--" + field.FlagKey
+		if field.ShortFlag != "" {
+			flag = "-" + field.ShortFlag + ", " + flag
+		}
+
+		fmt.Fprintf(w, "  %s\t$%s\t<%s>\t%s\t%s\n", flag, field.EnvKey, typeName(field.Field.Type()), status, field.Options.Help)
+	}
+	fmt.Fprintf(w, "  -h, --help\t\t\t\tdisplay this help message\n")
+	if _, ok := findVersion(cfg); ok {
+		fmt.Fprintf(w, "  --version\t\t\t\tdisplay version information\n")
+	}
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// usageValues resolves the values of the fields for Usage, leaving out the
+// help flags which would stop the resolution.
+func usageValues(fields []Field, o *options) (map[string]string, error) {
+	args := make([]string, 0, len(o.args))
+	for _, arg := range o.args {
+		if arg != "-h" && arg != "--help" {
+			args = append(args, arg)
+		}
+	}
+	o.args, o.warnings = args, nil
+	o.env = o.environment()
+
+	values, err := resolveValues(fields, o)
+	if err != nil {
+		return nil, fmt.Errorf("resolve values: %w", err)
+	}
+
+	return values, nil
+}
+
+// typeName returns a short name of the type for help output.
+func typeName(typ reflect.Type) string {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if isLazy(typ) {
+		return typeName(typ.Field(0).Type)
+	}
+
+	switch typ {
+	case reflect.TypeOf(time.Duration(0)), reflect.TypeOf(Duration(0)):
+		return "duration"
+	case reflect.TypeOf(Backoff{}):
+		return "backoff"
+	case reflect.TypeOf(ByteSize(0)):
+		return "size"
+	case reflect.TypeOf(DSN{}):
+		return "dsn"
+	case reflect.TypeOf(ListenAddr{}):
+		return "address"
+	case reflect.TypeOf(Header{}):
+		return "headers"
+	case urlType:
+		return "url"
+	case ipNetType, reflect.TypeOf(netip.Prefix{}):
+		return "cidr"
+	case reflect.TypeOf(net.IP{}), reflect.TypeOf(netip.Addr{}):
+		return "ip"
+	case reflect.TypeOf(regexp.Regexp{}):
+		return "regexp"
+	}
+
+	return typ.String()
+}
@@ -0,0 +1,72 @@
+package conf
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUsage(t *testing.T) {
+	var cfg struct {
+		Port    int           `conf:"default:8080,help:port to listen on"`
+		APIKey  string        `conf:"required,env:API_KEY,help:key of the upstream API"`
+		Timeout time.Duration `conf:"default:5s"`
+		DB      struct {
+			Host string
+		}
+		Verbose bool `conf:"short:v,help:log more"`
+	}
+
+	usage, err := Usage("test", &cfg)
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to render usage : %s.", failed, err)
+	}
+	t.Logf("\t%s\tShould be able to render usage.", success)
+
+	want := [][]string{
+		{"--port", "$TEST_PORT", "<int>", "(default: 8080)", "port to listen on"},
+		{"--api-key", "$API_KEY", "<string>", "(required)", "key of the upstream API"},
+		{"--timeout", "$TEST_TIMEOUT", "<duration>", "(default: 5s)"},
+		{"--db-host", "$TEST_DB_HOST", "<string>"},
+		{"-v,", "--verbose", "$TEST_VERBOSE", "<bool>", "log more"},
+	}
+
+	lines := strings.Split(usage, "\n")
+	for i, parts := range want {
+		if got := strings.Join(strings.Fields(lines[i+3]), " "); got != strings.Join(parts, " ") {
+			t.Fatalf("\t%s\tShould render option %s :\n%s", failed, parts[0], usage)
+		}
+	}
+	t.Logf("\t%s\tShould render every option :\n%s", success, usage)
+}
+
+func TestUsage_Values(t *testing.T) {
+	var cfg struct {
+		Port    int           `conf:"default:8080"`
+		APIKey  string        `conf:"required,mask"`
+		Timeout time.Duration `conf:"default:5s"`
+		Verbose bool          `conf:"short:v"`
+	}
+
+	env := map[string]string{"TEST_PORT": "9090", "TEST_API_KEY": "s3cret"}
+	usage, err := Usage("test", &cfg, WithUsageValues(), WithEnviron(env), WithArgs([]string{"--help", "-v"}))
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to render usage with values : %s.", failed, err)
+	}
+	t.Logf("\t%s\tShould be able to render usage with values.", success)
+
+	want := []string{
+		"--port $TEST_PORT <int> (default: 8080) [current: 9090 from env]",
+		"--api-key $TEST_API_KEY <string> (required) [current: xxxxxx from env]",
+		"--timeout $TEST_TIMEOUT <duration> (default: 5s)",
+		"-v, --verbose $TEST_VERBOSE <bool> [current: true from flag]",
+	}
+
+	lines := strings.Split(usage, "\n")
+	for i, line := range want {
+		if got := strings.Join(strings.Fields(lines[i+3]), " "); got != line {
+			t.Fatalf("\t%s\tShould render the current value of %s : %q\n%s", failed, strings.Fields(line)[0], got, usage)
+		}
+	}
+	t.Logf("\t%s\tShould render the current values :\n%s", success, usage)
+}
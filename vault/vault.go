@@ -0,0 +1,304 @@
+// Package vault provides a source reading configuration values from
+// a HashiCorp Vault KV version 2 secret, so secrets never touch the
+// environment.
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/virp/conf"
+)
+
+// An Option configures the Source.
+type Option func(*Source)
+
+// WithToken authenticates with the token.
+func WithToken(token string) Option {
+	return func(s *Source) {
+		s.token = token
+	}
+}
+
+// WithAppRole authenticates with the AppRole auth method mounted at
+// approle, logging in again whenever the token can't be renewed.
+func WithAppRole(roleID, secretID string) Option {
+	return func(s *Source) {
+		s.roleID, s.secretID = roleID, secretID
+	}
+}
+
+// WithHTTPClient sets the client of the requests to Vault,
+// http.DefaultClient by default.
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Source) {
+		s.client = client
+	}
+}
+
+// WithClock sets the clock scheduling the renewals of the token,
+// the system clock by default.
+func WithClock(c conf.Clock) Option {
+	return func(s *Source) {
+		s.clock = c
+	}
+}
+
+// Source provides the values of the keys of a KV version 2 secret.
+// A key provides the field whose env key it equals, or the field whose
+// path it matches ignoring case, underscores and dashes, e.g. the key
+// db_password provides the field DB.Password.
+//
+// The secret is read on every parse. Renewable tokens are renewed once
+// half of their TTL has passed, and the values of the last successful read
+// keep being served when Vault can't be reached.
+type Source struct {
+	addr   string
+	mount  string
+	path   string
+	client *http.Client
+	clock  conf.Clock
+
+	roleID   string
+	secretID string
+
+	mu        sync.RWMutex
+	token     string
+	renewable bool
+	renewAt   time.Time
+	values    map[string]string
+}
+
+// New constructs the source reading the secret at path of the KV version 2
+// engine mounted at mount from the Vault server at addr, for example
+// New("https://vault:8200", "secret", "my-service", WithToken(token)).
+func New(addr, mount, path string, opts ...Option) *Source {
+	s := Source{
+		addr:   strings.TrimRight(addr, "/"),
+		mount:  strings.Trim(mount, "/"),
+		path:   strings.Trim(path, "/"),
+		client: http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	return &s
+}
+
+// Source implements the conf.Sourcer interface.
+func (s *Source) Source(fld conf.Field) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if value, ok := s.values[fld.EnvKey]; ok {
+		return value, true
+	}
+
+	want := normalizeKey(strings.Join(fld.Path, ""))
+	for key, value := range s.values {
+		if normalizeKey(key) == want {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// Load implements the conf.Loader interface.
+func (s *Source) Load() error {
+	return s.LoadContext(context.Background())
+}
+
+// LoadContext implements the conf.ContextLoader interface.
+func (s *Source) LoadContext(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.authenticate(ctx); err != nil {
+		return err
+	}
+
+	var secret struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := s.do(ctx, http.MethodGet, "/v1/"+s.mount+"/data/"+s.path, nil, &secret); err != nil {
+		return fmt.Errorf("read secret %s/%s: %w", s.mount, s.path, err)
+	}
+
+	values := make(map[string]string, len(secret.Data.Data))
+	for key, value := range secret.Data.Data {
+		switch value := value.(type) {
+		case string:
+			values[key] = value
+		case json.Number:
+			values[key] = value.String()
+		default:
+			data, err := json.Marshal(value)
+			if err != nil {
+				return fmt.Errorf("read secret key %s: %w", key, err)
+			}
+			values[key] = string(data)
+		}
+	}
+
+	s.values = values
+
+	return nil
+}
+
+// Cached implements the conf.Cacher interface.
+func (s *Source) Cached() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.values != nil
+}
+
+func (s *Source) String() string {
+	return "vault " + s.mount + "/" + s.path
+}
+
+// authenticate makes sure there is a valid token, logging in with the
+// AppRole or renewing the token once half of its TTL has passed.
+func (s *Source) authenticate(ctx context.Context) error {
+	switch {
+	case s.token == "" && s.roleID != "":
+		return s.login(ctx)
+	case s.token == "":
+		return fmt.Errorf("no vault token, use WithToken or WithAppRole")
+	case s.renewAt.IsZero() && s.roleID == "":
+		return s.lookup(ctx)
+	case !s.renewable || s.now().Before(s.renewAt):
+		return nil
+	}
+
+	var resp struct {
+		Auth auth `json:"auth"`
+	}
+	err := s.do(ctx, http.MethodPost, "/v1/auth/token/renew-self", struct{}{}, &resp)
+	if err == nil {
+		s.setLease(resp.Auth.LeaseDuration, resp.Auth.Renewable)
+		return nil
+	}
+
+	if s.roleID == "" {
+		return fmt.Errorf("renew token: %w", err)
+	}
+
+	return s.login(ctx)
+}
+
+// login logs in with the AppRole.
+func (s *Source) login(ctx context.Context) error {
+	body := map[string]string{"role_id": s.roleID, "secret_id": s.secretID}
+
+	var resp struct {
+		Auth auth `json:"auth"`
+	}
+	s.token = ""
+	if err := s.do(ctx, http.MethodPost, "/v1/auth/approle/login", body, &resp); err != nil {
+		return fmt.Errorf("approle login: %w", err)
+	}
+
+	s.token = resp.Auth.ClientToken
+	s.setLease(resp.Auth.LeaseDuration, resp.Auth.Renewable)
+
+	return nil
+}
+
+// lookup learns the TTL of the token set with WithToken.
+func (s *Source) lookup(ctx context.Context) error {
+	var resp struct {
+		Data struct {
+			TTL       int  `json:"ttl"`
+			Renewable bool `json:"renewable"`
+		} `json:"data"`
+	}
+	if err := s.do(ctx, http.MethodGet, "/v1/auth/token/lookup-self", nil, &resp); err != nil {
+		return fmt.Errorf("lookup token: %w", err)
+	}
+
+	s.setLease(resp.Data.TTL, resp.Data.Renewable)
+
+	return nil
+}
+
+// setLease schedules the renewal of the token at half of its TTL.
+// Tokens without a TTL never expire and aren't renewed.
+func (s *Source) setLease(ttl int, renewable bool) {
+	s.renewable = renewable && ttl > 0
+	s.renewAt = s.now().Add(time.Duration(ttl) * time.Second / 2)
+}
+
+// now returns the time of the clock set with WithClock.
+func (s *Source) now() time.Time {
+	if s.clock == nil {
+		return time.Now()
+	}
+
+	return s.clock.Now()
+}
+
+type auth struct {
+	ClientToken   string `json:"client_token"`
+	LeaseDuration int    `json:"lease_duration"`
+	Renewable     bool   `json:"renewable"`
+}
+
+// do sends the request to Vault and decodes the response into out.
+func (s *Source) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.addr+path, body)
+	if err != nil {
+		return err
+	}
+
+	if s.token != "" {
+		req.Header.Set("X-Vault-Token", s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&e)
+		return fmt.Errorf("vault responded %s: %s", resp.Status, strings.Join(e.Errors, "; "))
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+
+	return dec.Decode(out)
+}
+
+// normalizeKey makes keys comparable ignoring case, underscores and dashes.
+func normalizeKey(key string) string {
+	key = strings.ReplaceAll(key, "_", "")
+	key = strings.ReplaceAll(key, "-", "")
+	return strings.ToLower(key)
+}
@@ -0,0 +1,206 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/virp/conf"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+// fakeVault provides support for testing against the Vault HTTP API.
+type fakeVault struct {
+	mu       sync.Mutex
+	token    string
+	ttl      int
+	logins   int
+	renewals int
+	lookups  int
+	renewErr bool
+	down     bool
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	reply := func(status int, body any) {
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(body)
+	}
+	denied := map[string]any{"errors": []string{"permission denied"}}
+
+	if r.URL.Path == "/v1/auth/approle/login" {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["role_id"] != "role" || body["secret_id"] != "secret" {
+			reply(http.StatusBadRequest, denied)
+			return
+		}
+
+		v.logins++
+		v.token = "approle-token"
+		reply(http.StatusOK, map[string]any{"auth": map[string]any{"client_token": v.token, "lease_duration": v.ttl, "renewable": true}})
+		return
+	}
+
+	if r.Header.Get("X-Vault-Token") != v.token {
+		reply(http.StatusForbidden, denied)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/v1/auth/token/lookup-self":
+		v.lookups++
+		reply(http.StatusOK, map[string]any{"data": map[string]any{"ttl": v.ttl, "renewable": true}})
+	case "/v1/auth/token/renew-self":
+		if v.renewErr {
+			reply(http.StatusForbidden, denied)
+			return
+		}
+		v.renewals++
+		reply(http.StatusOK, map[string]any{"auth": map[string]any{"client_token": v.token, "lease_duration": v.ttl, "renewable": true}})
+	case "/v1/secret/data/my-service":
+		if v.down {
+			reply(http.StatusServiceUnavailable, map[string]any{"errors": []string{"sealed"}})
+			return
+		}
+		reply(http.StatusOK, map[string]any{"data": map[string]any{"data": map[string]any{"db_password": "s3cret", "TEST_WORKERS": 4}}})
+	default:
+		reply(http.StatusNotFound, map[string]any{"errors": []string{}})
+	}
+}
+
+// fakeClock provides support for testing the renewals of tokens
+// without waiting for them.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+type config struct {
+	DB struct {
+		Password string
+	}
+	Workers int
+}
+
+func TestSource_AppRole(t *testing.T) {
+	os.Clearenv()
+
+	fake := &fakeVault{ttl: 1}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	clock := &fakeClock{now: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+	src := New(srv.URL, "secret", "my-service", WithAppRole("role", "secret"), WithClock(clock))
+
+	var cfg config
+	if err := conf.Parse("test", &cfg, conf.WithSources(src), conf.WithArgs(nil)); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse from vault : %s.", failed, err)
+	}
+
+	if cfg.DB.Password != "s3cret" || cfg.Workers != 4 {
+		t.Fatalf("\t%s\tShould have mapped secret keys to fields : %+v.", failed, cfg)
+	}
+	t.Logf("\t%s\tShould have mapped secret keys to fields.", success)
+
+	// Let half of the TTL pass so the token is renewed.
+	clock.advance(600 * time.Millisecond)
+
+	if err := conf.Parse("test", &cfg, conf.WithSources(src), conf.WithArgs(nil)); err != nil || fake.renewals != 1 {
+		t.Fatalf("\t%s\tShould have renewed the token : %d renewals : %v.", failed, fake.renewals, err)
+	}
+	t.Logf("\t%s\tShould have renewed the token.", success)
+
+	clock.advance(600 * time.Millisecond)
+	fake.renewErr = true
+
+	if err := conf.Parse("test", &cfg, conf.WithSources(src), conf.WithArgs(nil)); err != nil || fake.logins != 2 {
+		t.Fatalf("\t%s\tShould log in again when renewal fails : %d logins : %v.", failed, fake.logins, err)
+	}
+	t.Logf("\t%s\tShould log in again when renewal fails.", success)
+
+	fake.down = true
+
+	cfg = config{}
+	if err := conf.Parse("test", &cfg, conf.WithSources(src), conf.WithArgs(nil)); err != nil || cfg.DB.Password != "s3cret" {
+		t.Fatalf("\t%s\tShould keep serving the last secret when vault is down : %v.", failed, err)
+	}
+
+	for _, h := range conf.SourcesHealth() {
+		if h.Name == "vault secret/my-service" && (h.LastError == nil || !h.Cached) {
+			t.Fatalf("\t%s\tShould report vault is down in health : %+v.", failed, h)
+		}
+	}
+	t.Logf("\t%s\tShould keep serving the last secret when vault is down.", success)
+}
+
+func TestSource_Token(t *testing.T) {
+	os.Clearenv()
+
+	fake := &fakeVault{token: "static-token", ttl: 3600}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	var cfg config
+	err := conf.Parse("test", &cfg, conf.WithSources(New(srv.URL, "secret", "my-service", WithToken("static-token"))), conf.WithArgs(nil))
+	if err != nil || cfg.DB.Password != "s3cret" || fake.lookups != 1 {
+		t.Fatalf("\t%s\tShould be able to parse from vault with a token : %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould be able to parse from vault with a token.", success)
+
+	err = conf.Parse("test", &cfg, conf.WithSources(New(srv.URL, "secret", "my-service", WithToken("wrong"))), conf.WithArgs(nil))
+	if err == nil {
+		t.Fatalf("\t%s\tShould fail with a wrong token.", failed)
+	}
+	t.Logf("\t%s\tShould fail with a wrong token : %s.", success, err)
+}
+
+func TestSource_Context(t *testing.T) {
+	os.Clearenv()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var cfg config
+	err := conf.ParseContext(ctx, "test", &cfg, conf.WithSources(New(srv.URL, "secret", "my-service", WithToken("static-token"))), conf.WithArgs(nil))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("\t%s\tShould abort the requests to vault once the context is done : %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould abort the requests to vault once the context is done.", success)
+}
@@ -0,0 +1,47 @@
+package conf
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Version provides the build and description of the program. Embed it into
+// the config struct to have it printed with VersionString when --version
+// is passed. Its fields are never set from the sources.
+type Version struct {
+	Build string
+	Desc  string
+}
+
+var versionType = reflect.TypeOf(Version{})
+
+// VersionString renders the Version held by the specified config struct.
+func VersionString(cfg any) (string, error) {
+	v, ok := findVersion(cfg)
+	if !ok {
+		return "", fmt.Errorf("no version in config struct")
+	}
+
+	if v.Desc == "" {
+		return fmt.Sprintf("Version: %s\n", v.Build), nil
+	}
+
+	return fmt.Sprintf("Version: %s\n%s\n", v.Build, v.Desc), nil
+}
+
+// findVersion returns the first Version field of the config struct.
+func findVersion(cfg any) (Version, bool) {
+	s := reflect.ValueOf(cfg)
+	if s.Kind() != reflect.Ptr || s.Elem().Kind() != reflect.Struct {
+		return Version{}, false
+	}
+	s = s.Elem()
+
+	for i := 0; i < s.NumField(); i++ {
+		if f := s.Field(i); f.Type() == versionType && f.CanInterface() {
+			return f.Interface().(Version), true
+		}
+	}
+
+	return Version{}, false
+}
@@ -0,0 +1,59 @@
+package conf
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestParse_HelpVersion(t *testing.T) {
+	os.Clearenv()
+
+	args := os.Args
+	defer func() { os.Args = args }()
+
+	var cfg struct {
+		Version
+		Port int `conf:"required"`
+	}
+	cfg.Version = Version{Build: "v1.0.0", Desc: "test service"}
+
+	for _, arg := range []string{"-h", "--help"} {
+		os.Args = []string{"app", arg}
+		if err := Parse("test", &cfg); !errors.Is(err, ErrHelpWanted) {
+			t.Fatalf("\t%s\tShould return ErrHelpWanted for %s : %v.", failed, arg, err)
+		}
+		t.Logf("\t%s\tShould return ErrHelpWanted for %s.", success, arg)
+	}
+
+	os.Args = []string{"app", "--version"}
+	if err := Parse("test", &cfg); !errors.Is(err, ErrVersionWanted) {
+		t.Fatalf("\t%s\tShould return ErrVersionWanted : %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould return ErrVersionWanted.", success)
+
+	version, err := VersionString(&cfg)
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to render version : %s.", failed, err)
+	}
+
+	if want := "Version: v1.0.0\ntest service\n"; version != want {
+		t.Fatalf("\t%s\tShould render version %q, got %q.", failed, want, version)
+	}
+	t.Logf("\t%s\tShould render version.", success)
+
+	var plain struct {
+		Port int
+	}
+	os.Args = []string{"app", "--version"}
+	if err := Parse("test", &plain); err != nil {
+		t.Fatalf("\t%s\tShould ignore --version without a version : %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould ignore --version without a version.", success)
+
+	os.Args = []string{"app", "--", "--help"}
+	if err := Parse("test", &cfg); errors.Is(err, ErrHelpWanted) || err == nil {
+		t.Fatalf("\t%s\tShould ignore help after the terminator : %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould ignore help after the terminator.", success)
+}
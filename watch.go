@@ -0,0 +1,98 @@
+package conf
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// defaultWatchInterval is how often Watch parses the configuration
+// unless set by WithWatchInterval.
+const defaultWatchInterval = 30 * time.Second
+
+// WithWatchInterval sets how often Watch parses the configuration,
+// 30s by default.
+func WithWatchInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.watchInterval = d
+	}
+}
+
+// WithWatchErrors sets the function Watch reports failed parses to.
+// The configuration is left as it was by a failed parse.
+func WithWatchErrors(fn func(err error)) Option {
+	return func(o *options) {
+		o.watchErrors = fn
+	}
+}
+
+// WithWatchFreeze pins the configuration once the channel is closed, such
+// as the Done channel of the context of signal.NotifyContext when shutdown
+// begins. Watch then rejects all further reloads and returns, so changes of
+// the config can't race graceful shutdown. A parse completing after the
+// channel is closed is dropped without calling back.
+func WithWatchFreeze(freeze <-chan struct{}) Option {
+	return func(o *options) {
+		o.watchFreeze = freeze
+	}
+}
+
+// Watch parses the configuration every interval until the context is done,
+// picking up rotated secrets and changed settings without a restart. The cfg
+// holds the configuration already parsed and is never modified, every parse
+// goes into a new value of its type and onChange is called with the previous
+// and the new value whenever they differ. Files and remote stores are loaded
+// again on every parse. Watch blocks until the context is done and returns
+// its error, or until the config is frozen by WithWatchFreeze and returns
+// nil.
+func Watch(ctx context.Context, prefix string, cfg any, onChange func(old, new any), opts ...Option) error {
+	p, err := NewParser(prefix, cfg, opts...)
+	if err != nil {
+		return err
+	}
+
+	o := newOptions(opts)
+
+	interval := o.watchInterval
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	old := cfg
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-o.watchFreeze:
+			return nil
+		case <-o.clock.After(interval):
+		}
+
+		next := reflect.New(p.typ.Elem()).Interface()
+		if err := p.Parse(next, WithContext(ctx)); err != nil {
+			if o.watchErrors != nil && ctx.Err() == nil {
+				o.watchErrors(err)
+			}
+			continue
+		}
+
+		if reflect.DeepEqual(old, next) {
+			provenance.Delete(next)
+			continue
+		}
+
+		select {
+		case <-o.watchFreeze:
+			provenance.Delete(next)
+			return nil
+		default:
+		}
+
+		onChange(old, next)
+		if old != cfg {
+			provenance.Delete(old)
+		}
+		old = next
+	}
+}
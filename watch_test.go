@@ -0,0 +1,158 @@
+package conf
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// rotatingSource provides support for testing values changing
+// between parses.
+type rotatingSource struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+// Source implements the Sourcer interface
+func (s *rotatingSource) Source(fld Field) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, ok := s.values[fld.EnvKey]
+	return value, ok
+}
+
+func (s *rotatingSource) set(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[key] = value
+}
+
+func TestWatch(t *testing.T) {
+	os.Clearenv()
+
+	type secrets struct {
+		Token   string
+		Workers int
+	}
+
+	src := &rotatingSource{values: map[string]string{"TEST_TOKEN": "first", "TEST_WORKERS": "1"}}
+	opts := []Option{WithSources(src), WithArgs(nil), WithWatchInterval(5 * time.Millisecond)}
+
+	var cfg secrets
+	if err := Parse("test", &cfg, opts...); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse initial config : %s.", failed, err)
+	}
+
+	type change struct{ old, new secrets }
+	changes := make(chan change, 10)
+	errs := make(chan error, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		onChange := func(old, new any) {
+			changes <- change{*old.(*secrets), *new.(*secrets)}
+		}
+		done <- Watch(ctx, "test", &cfg, onChange, append(opts, WithWatchErrors(func(err error) { errs <- err }))...)
+	}()
+
+	src.set("TEST_TOKEN", "second")
+
+	select {
+	case c := <-changes:
+		if c.old != (secrets{"first", 1}) || c.new != (secrets{"second", 1}) {
+			t.Fatalf("\t%s\tShould report the old and new config, got %+v.", failed, c)
+		}
+		t.Logf("\t%s\tShould report the old and new config.", success)
+	case <-time.After(time.Second):
+		t.Fatalf("\t%s\tShould report the changed config.", failed)
+	}
+
+	src.set("TEST_WORKERS", "many")
+
+	select {
+	case err := <-errs:
+		t.Logf("\t%s\tShould report the failed parse : %s.", success, err)
+	case <-time.After(time.Second):
+		t.Fatalf("\t%s\tShould report the failed parse.", failed)
+	}
+
+	src.set("TEST_WORKERS", "2")
+
+	select {
+	case c := <-changes:
+		if c.old != (secrets{"second", 1}) || c.new != (secrets{"second", 2}) {
+			t.Fatalf("\t%s\tShould keep the config of the last good parse, got %+v.", failed, c)
+		}
+		t.Logf("\t%s\tShould keep the config of the last good parse.", success)
+	case <-time.After(time.Second):
+		t.Fatalf("\t%s\tShould report the changed config.", failed)
+	}
+
+	cancel()
+
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("\t%s\tShould stop once the context is done : %v.", failed, err)
+	}
+	t.Logf("\t%s\tShould stop once the context is done.", success)
+
+	if cfg != (secrets{"first", 1}) {
+		t.Fatalf("\t%s\tShould leave the watched config untouched, got %+v.", failed, cfg)
+	}
+	t.Logf("\t%s\tShould leave the watched config untouched.", success)
+}
+
+func TestWatch_Freeze(t *testing.T) {
+	os.Clearenv()
+
+	type secrets struct {
+		Token string
+	}
+
+	src := &rotatingSource{values: map[string]string{"TEST_TOKEN": "first"}}
+	freeze := make(chan struct{})
+	opts := []Option{WithSources(src), WithArgs(nil), WithWatchInterval(5 * time.Millisecond), WithWatchFreeze(freeze)}
+
+	var cfg secrets
+	if err := Parse("test", &cfg, opts...); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse initial config : %s.", failed, err)
+	}
+
+	var mu sync.Mutex
+	var changes int
+
+	done := make(chan error, 1)
+	go func() {
+		done <- Watch(context.Background(), "test", &cfg, func(old, new any) {
+			mu.Lock()
+			defer mu.Unlock()
+			changes++
+		}, opts...)
+	}()
+
+	close(freeze)
+	src.set("TEST_TOKEN", "second")
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("\t%s\tShould stop without an error once frozen : %s.", failed, err)
+		}
+		t.Logf("\t%s\tShould stop without an error once frozen.", success)
+	case <-time.After(time.Second):
+		t.Fatalf("\t%s\tShould stop once frozen.", failed)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if changes != 0 {
+		t.Fatalf("\t%s\tShould reject reloads once frozen, got %d changes.", failed, changes)
+	}
+	t.Logf("\t%s\tShould reject reloads once frozen.", success)
+}
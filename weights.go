@@ -0,0 +1,99 @@
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Weight is an entry of Weights.
+type Weight struct {
+	Name   string
+	Weight float64
+}
+
+// Weights holds weighted entries set from a value such as a=70,b=30 for
+// traffic splitting and sampling. The weights are percentages which must
+// sum to 100.
+type Weights []Weight
+
+// Set implements the Setter interface.
+func (w *Weights) Set(data string) error {
+	var (
+		weights Weights
+		sum     float64
+	)
+
+	seen := make(map[string]bool)
+
+	for _, item := range strings.Split(data, ",") {
+		name, value, ok := strings.Cut(item, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return fmt.Errorf("invalid weight %q, expected name=weight", item)
+		}
+
+		if seen[name] {
+			return fmt.Errorf("duplicate weight %s", name)
+		}
+		seen[name] = true
+
+		weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return fmt.Errorf("weight %s: %w, expected a percentage", name, err)
+		}
+
+		if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return fmt.Errorf("weight %s: %s is not a percentage", name, value)
+		}
+
+		weights = append(weights, Weight{Name: name, Weight: weight})
+		sum += weight
+	}
+
+	if math.Abs(sum-100) > 1e-6 {
+		return fmt.Errorf("weights sum to %s, expected 100", strconv.FormatFloat(sum, 'g', -1, 64))
+	}
+
+	*w = weights
+
+	return nil
+}
+
+// String renders the weights in the form accepted by Set.
+func (w Weights) String() string {
+	items := make([]string, len(w))
+	for i, weight := range w {
+		items[i] = weight.Name + "=" + strconv.FormatFloat(weight.Weight, 'g', -1, 64)
+	}
+
+	return strings.Join(items, ",")
+}
+
+// Pick returns the name of the entry the number from [0, 1) falls into,
+// e.g. with a=70,b=30 numbers below 0.7 pick a. Pass rand.Float64() to
+// split traffic randomly.
+func (w Weights) Pick(n float64) (string, error) {
+	if len(w) == 0 {
+		return "", errors.New("no weights to pick from")
+	}
+
+	var total float64
+	for _, weight := range w {
+		total += weight.Weight
+		if n*100 < total {
+			return weight.Name, nil
+		}
+	}
+
+	// Rounding may leave the sum a bit below 100.
+	for i := len(w) - 1; i >= 0; i-- {
+		if w[i].Weight > 0 {
+			return w[i].Name, nil
+		}
+	}
+
+	return w[len(w)-1].Name, nil
+}
@@ -0,0 +1,65 @@
+package conf
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse_Weights(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("TEST_SPLIT", "stable=70, canary=29.5,shadow=0.5")
+
+	var cfg struct {
+		Split Weights
+	}
+
+	if err := Parse("test", &cfg); err != nil {
+		t.Fatalf("\t%s\tShould be able to parse weights : %s.", failed, err)
+	}
+
+	want := Weights{{"stable", 70}, {"canary", 29.5}, {"shadow", 0.5}}
+	if diff := cmp.Diff(want, cfg.Split); diff != "" {
+		t.Fatalf("\t%s\tShould have parsed weights\n%s", failed, diff)
+	}
+	t.Logf("\t%s\tShould have parsed weights.", success)
+
+	if got := cfg.Split.String(); got != "stable=70,canary=29.5,shadow=0.5" {
+		t.Fatalf("\t%s\tShould render weights in the form accepted, got %s.", failed, got)
+	}
+	t.Logf("\t%s\tShould render weights in the form accepted.", success)
+
+	picks := map[float64]string{0: "stable", 0.699: "stable", 0.7: "canary", 0.99: "canary", 0.996: "shadow", 0.99999: "shadow"}
+	for n, want := range picks {
+		if got, err := cfg.Split.Pick(n); err != nil || got != want {
+			t.Fatalf("\t%s\tShould pick %s for %v, got %s : %v.", failed, want, n, got, err)
+		}
+	}
+	t.Logf("\t%s\tShould pick entries by weight.", success)
+
+	tests := []struct {
+		name string
+		env  string
+		want string
+	}{
+		{"sum", "a=70,b=20", "weights sum to 90, expected 100"},
+		{"format", "a:70,b=30", `invalid weight "a:70"`},
+		{"negative", "a=110,b=-10", "weight b: -10 is not a percentage"},
+		{"duplicate", "a=50,a=50", "duplicate weight a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			_ = os.Setenv("TEST_SPLIT", tt.env)
+
+			err := Parse("test", &cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("\t%s\tShould fail for %q with %q : %v.", failed, tt.env, tt.want, err)
+			}
+			t.Logf("\t%s\tShould fail for %q : %s.", success, tt.env, err)
+		})
+	}
+}
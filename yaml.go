@@ -0,0 +1,25 @@
+package conf
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+// WithYamlFile reads values from the YAML file at path, merged below the
+// sources which take precedence. Nested keys map to nested struct fields,
+// e.g. ip.name sets IP.Name. The file is read on every parse.
+func WithYamlFile(path string) Option {
+	return withFile(yamlFileSource(path))
+}
+
+func yamlFileSource(path string) *fileSource {
+	return &fileSource{path: path, format: "yaml", decode: decodeYaml}
+}
+
+func decodeYaml(data []byte) (map[string]any, error) {
+	tree := make(map[string]any)
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
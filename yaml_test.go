@@ -0,0 +1,75 @@
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const yamlConfig = `
+an_int: 3
+a_string: yaml
+ip:
+  name: yaml-name
+  endpoints:
+    - 10.0.0.1:80
+    - 10.0.0.2:80
+debugHost: http://yaml:4000
+duration: 2m
+`
+
+func TestParse_YamlFile(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("TEST_A_STRING", "env")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(yamlConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := config{3, "env", false, "", ip{"yaml-name", "127.0.0.0", []string{"10.0.0.1:80", "10.0.0.2:80"}}, "http://yaml:4000", "password", CustomValue{something: "@hello@"}, Embed{"sergey", 2 * time.Minute}}
+
+	t.Run("option", func(t *testing.T) {
+		var cfg config
+		if err := Parse("test", &cfg, WithYamlFile(path)); err != nil {
+			t.Fatalf("\t%s\tShould be able to parse from yaml file : %s.", failed, err)
+		}
+
+		if diff := cmp.Diff(want, cfg); diff != "" {
+			t.Fatalf("\t%s\tShould have merged yaml file below env variables\n%s", failed, diff)
+		}
+		t.Logf("\t%s\tShould have merged yaml file below env variables.", success)
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		args := os.Args
+		defer func() { os.Args = args }()
+		os.Args = []string{"app", "--config", path}
+
+		var cfg config
+		if err := Parse("test", &cfg); err != nil {
+			t.Fatalf("\t%s\tShould be able to parse from --config file : %s.", failed, err)
+		}
+
+		if diff := cmp.Diff(want, cfg); diff != "" {
+			t.Fatalf("\t%s\tShould have merged --config file below env variables\n%s", failed, diff)
+		}
+		t.Logf("\t%s\tShould have merged --config file below env variables.", success)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		bad := filepath.Join(t.TempDir(), "bad.yaml")
+		if err := os.WriteFile(bad, []byte("ip: [unclosed"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		var cfg config
+		if err := Parse("test", &cfg, WithYamlFile(bad)); err == nil {
+			t.Fatalf("\t%s\tShould fail for invalid yaml file.", failed)
+		}
+		t.Logf("\t%s\tShould fail for invalid yaml file.", success)
+	})
+}